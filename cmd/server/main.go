@@ -13,6 +13,7 @@ import (
 	"github.com/akshaydubey29/mimirInsights/pkg/discovery"
 	"github.com/akshaydubey29/mimirInsights/pkg/limits"
 	"github.com/akshaydubey29/mimirInsights/pkg/metrics"
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -32,6 +33,12 @@ func main() {
 
 	logrus.Info("Starting MimirInsights server...")
 
+	// Initialize persistent storage before any component that keeps state.
+	// In-memory storage is only used when configured (storage.type: memory), never as a silent fallback.
+	if err := storage.Init(); err != nil {
+		logrus.Fatalf("Failed to initialize storage: %v", err)
+	}
+
 	// Initialize components
 	discoveryEngine := discovery.NewEngine()
 	metricsClient := metrics.NewClient()
@@ -52,7 +59,7 @@ func main() {
 	router.GET("/healthz", server.HealthCheck)
 
 	// API routes
-	apiGroup := router.Group("/api")
+	apiGroup := router.Group("/api", server.AuditMiddleware())
 	{
 		apiGroup.GET("/health", server.HealthCheck)
 		apiGroup.GET("/tenants", server.GetTenants)
@@ -68,10 +75,17 @@ func main() {
 		apiGroup.GET("/metrics/dashboard", server.GetDashboardMetrics)
 		apiGroup.GET("/metrics/real", server.GetRealMetrics)
 		apiGroup.GET("/metrics/discovery", server.GetAutoDiscoveredMetrics)
+		apiGroup.GET("/metrics/names", server.GetMetricNames)
 		apiGroup.GET("/audit", server.GetAuditLogs)
 		apiGroup.POST("/analyze", server.AnalyzeTenant)
 		apiGroup.GET("/drift", server.GetDriftStatus)
+		apiGroup.GET("/drift/history", server.GetDriftHistory)
 		apiGroup.POST("/drift/baseline", server.CreateDriftBaseline)
+		apiGroup.GET("/drift/baselines", server.ListDriftBaselines)
+		apiGroup.GET("/drift/baselines/:name", server.GetDriftBaseline)
+		apiGroup.GET("/drift/compare", server.CompareDriftBaselines)
+		apiGroup.GET("/drift/git", server.GetGitDrift)
+		apiGroup.POST("/drift/remediate", server.RemediateDrift)
 		apiGroup.GET("/alloy/deployments", server.GetAlloyDeployments)
 		apiGroup.GET("/alloy/workloads", server.GetAlloyWorkloads)
 		apiGroup.POST("/alloy/scale", server.ScaleAlloyReplicas)
@@ -81,6 +95,7 @@ func main() {
 		apiGroup.GET("/capacity/trends", server.GetCapacityTrends)
 		apiGroup.GET("/reports", server.GetCapacityReport) // Add reports endpoint for frontend compatibility
 		apiGroup.POST("/llm/query", server.ProcessLLMQuery)
+		apiGroup.POST("/llm/promql", server.GenerateLLMPromQL)
 		apiGroup.GET("/llm/capabilities", server.GetLLMCapabilities)
 		apiGroup.GET("/cache/status", server.GetCacheStatus)
 		apiGroup.POST("/cache/refresh", server.ForceCacheRefresh)
@@ -94,7 +109,15 @@ func main() {
 	// Intelligent limit analysis and management
 	apiGroup.GET("/analyze-tenant", server.AnalyzeTenantIntelligently)
 	apiGroup.GET("/limit-recommendations", server.GetLimitRecommendations)
+	apiGroup.POST("/limit-recommendations/apply", server.ApplyLimitRecommendations)
+	apiGroup.GET("/approvals", server.ListApprovals)
+	apiGroup.GET("/approvals/:id", server.GetApproval)
+	apiGroup.POST("/approvals/:id/approve", server.ApproveProposal)
+	apiGroup.POST("/approvals/:id/reject", server.RejectProposal)
+	apiGroup.POST("/approvals/:id/retry", server.RetryProposal)
 	apiGroup.PUT("/tenants/:tenant/limits", server.UpdateTenantLimit)
+	apiGroup.GET("/tenants/:tenant/limits/history", server.GetTenantLimitHistory)
+	apiGroup.POST("/tenants/:tenant/limits/rollback/:changeId", server.RollbackTenantLimit)
 
 	// Serve static files for UI
 	router.Static("/dashboard", "./web-ui/build")
@@ -135,5 +158,11 @@ func main() {
 		logrus.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	server.Stop()
+
+	if err := storage.Get().Close(); err != nil {
+		logrus.Errorf("Failed to close storage: %v", err)
+	}
+
 	logrus.Info("Server exited")
 }
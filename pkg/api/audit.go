@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// auditActions maps mutating routes to audit action names
+var auditActions = map[string]string{
+	"POST /api/tenants":                                   "tenant.create",
+	"PUT /api/tenants/:tenant/limits":                     "limit.update",
+	"POST /api/tenants/:tenant/limits/rollback/:changeId": "limit.rollback",
+	"POST /api/limit-recommendations/apply":               "limit.recommendations.apply",
+	"POST /api/approvals/:id/approve":                     "approval.approve",
+	"POST /api/approvals/:id/reject":                      "approval.reject",
+	"POST /api/approvals/:id/retry":                       "approval.retry",
+	"POST /api/drift/baseline":                            "drift.baseline.create",
+	"POST /api/drift/remediate":                           "drift.remediate",
+	"POST /api/alloy/scale":                               "alloy.scale",
+	"POST /api/cache/refresh":                             "cache.refresh",
+	"POST /api/cache/memory/evict":                        "cache.memory.evict",
+	"POST /api/cache/memory/reset":                        "cache.memory.reset",
+	"POST /api/cache/memory/settings":                     "cache.memory.settings",
+}
+
+// readOnlyRoutes are POST routes that do not change any state
+var readOnlyRoutes = map[string]bool{
+	"POST /api/analyze":    true,
+	"POST /api/llm/query":  true,
+	"POST /api/llm/promql": true,
+}
+
+// AuditMiddleware records every mutating API call in the audit log
+func (s *Server) AuditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		route := method + " " + c.FullPath()
+		if readOnlyRoutes[route] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		var payload []byte
+		if c.Request.Body != nil {
+			payload, _ = io.ReadAll(c.Request.Body)
+			c.Request.Body = io.NopCloser(bytes.NewReader(payload))
+		}
+
+		c.Next()
+
+		action, known := auditActions[route]
+		if !known {
+			action = strings.ToLower(method) + " " + c.Request.URL.Path
+		}
+
+		status := c.Writer.Status()
+		outcome := "success"
+		if status >= http.StatusBadRequest {
+			outcome = "failure"
+		}
+
+		entry := audit.Entry{
+			Timestamp:  start,
+			Actor:      s.requestUser(c),
+			SourceIP:   c.ClientIP(),
+			Method:     method,
+			Path:       c.Request.URL.Path,
+			Action:     action,
+			Tenant:     auditTenant(c, payload),
+			StatusCode: status,
+			Outcome:    outcome,
+			DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if len(payload) > 0 {
+			digest := sha256.Sum256(payload)
+			entry.PayloadDigest = "sha256:" + hex.EncodeToString(digest[:])
+		}
+
+		s.auditLogger.Record(entry)
+	}
+}
+
+// auditTenant finds the tenant a request applies to from the path or the JSON payload
+func auditTenant(c *gin.Context, payload []byte) string {
+	if tenant := c.Param("tenant"); tenant != "" {
+		return tenant
+	}
+
+	var body map[string]interface{}
+	if len(payload) == 0 || json.Unmarshal(payload, &body) != nil {
+		return ""
+	}
+	for _, key := range []string{"tenant_name", "tenant", "namespace"} {
+		if value, ok := body[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseAuditFilter builds an audit filter from query parameters
+func parseAuditFilter(c *gin.Context) (audit.Filter, error) {
+	filter := audit.Filter{
+		Actor:  c.Query("actor"),
+		Action: c.Query("action"),
+		Tenant: c.Query("tenant"),
+		Limit:  500,
+	}
+
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = parsed
+	}
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = parsed
+	}
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			return filter, err
+		}
+		filter.Limit = parsed
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net"
+	"strings"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// anonymousUser is the identity of requests that did not pass a trusted authenticating proxy
+const anonymousUser = "anonymous"
+
+// identity resolves the user behind a request from the header set by a trusted authenticating proxy.
+// Clients can send any header, so the header only counts on connections from a configured proxy.
+type identity struct {
+	userHeader     string
+	trustedProxies []*net.IPNet
+}
+
+// newIdentity creates the identity resolver from the auth configuration
+func newIdentity(authConfig config.AuthConfig) *identity {
+	id := &identity{userHeader: authConfig.UserHeader}
+	if id.userHeader == "" {
+		id.userHeader = "X-Forwarded-User"
+	}
+	for _, proxy := range authConfig.TrustedProxies {
+		network, err := config.ParseTrustedProxy(proxy)
+		if err != nil {
+			logrus.Warnf("Ignoring trusted proxy: %v", err)
+			continue
+		}
+		id.trustedProxies = append(id.trustedProxies, network)
+	}
+	if len(id.trustedProxies) == 0 {
+		logrus.Warn("No trusted authentication proxy configured (auth.trusted_proxies); all requests are anonymous")
+	}
+	return id
+}
+
+// user returns the authenticated user of a request, or anonymousUser
+func (id *identity) user(c *gin.Context) string {
+	if !id.trusted(c.Request.RemoteAddr) {
+		return anonymousUser
+	}
+	if user := strings.TrimSpace(c.GetHeader(id.userHeader)); user != "" {
+		return user
+	}
+	return anonymousUser
+}
+
+// trusted reports whether the connection comes from an authenticating proxy.
+// The peer address is used rather than X-Forwarded-For, which the client controls.
+func (id *identity) trusted(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range id.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
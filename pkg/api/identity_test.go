@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdentityUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		auth       config.AuthConfig
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "trusted proxy",
+			auth:       config.AuthConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.1.2.3:41000",
+			headers:    map[string]string{"X-Forwarded-User": "alice"},
+			want:       "alice",
+		},
+		{
+			name:       "untrusted peer cannot claim a user",
+			auth:       config.AuthConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr: "192.168.1.5:41000",
+			headers:    map[string]string{"X-Forwarded-User": "alice"},
+			want:       anonymousUser,
+		},
+		{
+			name:       "X-Forwarded-For does not make a peer trusted",
+			auth:       config.AuthConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			remoteAddr: "192.168.1.5:41000",
+			headers:    map[string]string{"X-Forwarded-User": "alice", "X-Forwarded-For": "10.1.2.3"},
+			want:       anonymousUser,
+		},
+		{
+			name:       "no trusted proxies",
+			remoteAddr: "127.0.0.1:41000",
+			headers:    map[string]string{"X-Forwarded-User": "alice"},
+			want:       anonymousUser,
+		},
+		{
+			name:       "other headers are ignored",
+			auth:       config.AuthConfig{TrustedProxies: []string{"127.0.0.1"}},
+			remoteAddr: "127.0.0.1:41000",
+			headers:    map[string]string{"X-User": "alice"},
+			want:       anonymousUser,
+		},
+		{
+			name:       "configured header",
+			auth:       config.AuthConfig{UserHeader: "X-Auth-Request-Email", TrustedProxies: []string{"::1"}},
+			remoteAddr: "[::1]:41000",
+			headers:    map[string]string{"X-Auth-Request-Email": " bob@example.com ", "X-Forwarded-User": "alice"},
+			want:       "bob@example.com",
+		},
+		{
+			name:       "invalid proxies are skipped",
+			auth:       config.AuthConfig{TrustedProxies: []string{"not-an-ip", "127.0.0.1"}},
+			remoteAddr: "127.0.0.1:41000",
+			headers:    map[string]string{"X-Forwarded-User": "alice"},
+			want:       "alice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("POST", "/api/approvals/prop_1/approve", nil)
+			c.Request.RemoteAddr = tt.remoteAddr
+			for header, value := range tt.headers {
+				c.Request.Header.Set(header, value)
+			}
+
+			if got := newIdentity(tt.auth).user(c); got != tt.want {
+				t.Errorf("user() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
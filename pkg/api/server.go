@@ -2,19 +2,26 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/akshaydubey29/mimirInsights/pkg/approval"
+	"github.com/akshaydubey29/mimirInsights/pkg/audit"
 	"github.com/akshaydubey29/mimirInsights/pkg/cache"
 	"github.com/akshaydubey29/mimirInsights/pkg/capacity"
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
 	"github.com/akshaydubey29/mimirInsights/pkg/discovery"
 	"github.com/akshaydubey29/mimirInsights/pkg/drift"
 	"github.com/akshaydubey29/mimirInsights/pkg/limits"
 	"github.com/akshaydubey29/mimirInsights/pkg/llm"
 	"github.com/akshaydubey29/mimirInsights/pkg/metrics"
 	"github.com/akshaydubey29/mimirInsights/pkg/monitoring"
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
 	"github.com/akshaydubey29/mimirInsights/pkg/tuning"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
@@ -33,6 +40,11 @@ type Server struct {
 	capacityPlanner *capacity.Planner
 	llmAssistant    *llm.Assistant
 	healthChecker   *monitoring.HealthChecker
+	approvalManager *approval.Manager
+	auditLogger     *audit.Logger
+	driftWatcher    *drift.Watcher
+	identity        *identity
+	stopBackground  context.CancelFunc
 
 	// Prometheus metrics
 	requestCounter  *prometheus.CounterVec
@@ -81,21 +93,53 @@ func NewServer(discoveryEngine *discovery.Engine, metricsClient *metrics.Client,
 	// Create cache manager
 	cacheManager := cache.NewManager(discoveryEngine, metricsClient, limitsAnalyzer)
 
+	driftDetector := drift.NewDetector(discoveryEngine)
+	// Limit changes made through this service are sanctioned, not drift
+	limitsAnalyzer.SetExpectChange(driftDetector.ExpectConfigMapData)
+	// Runtime overrides are rolled back through the limits API, which keeps their history
+	driftDetector.SetManagedConfigMaps(limitsAnalyzer.ManagesConfigMap)
+	if cfg := config.Get(); cfg != nil && cfg.Drift.Git.Path != "" {
+		namespace := cfg.Drift.Git.DefaultNamespace
+		if namespace == "" {
+			namespace = cfg.Mimir.Namespace
+		}
+		driftDetector.SetGitSource(drift.NewGitSource(cfg.Drift.Git.Path, cfg.Drift.Git.ManifestsDir, namespace))
+	}
+	llmAssistant, _ := llm.NewAssistant()
+	llmAssistant.SetDataSources(llm.DataSources{
+		Metrics: metricsClient,
+		Limits:  limitsAnalyzer,
+		Cache:   cacheManager,
+		Drift:   driftDetector,
+	})
+	healthChecker := monitoring.NewHealthChecker(discoveryEngine.GetK8sClient(), healthConfig)
+
 	server := &Server{
 		discoveryEngine: discoveryEngine,
 		metricsClient:   metricsClient,
 		limitsAnalyzer:  limitsAnalyzer,
 		cacheManager:    cacheManager,
-		driftDetector:   drift.NewDetector(discoveryEngine.GetK8sClient()),
+		driftDetector:   driftDetector,
 		alloyTuner:      tuning.NewAlloyTuner(discoveryEngine.GetK8sClient()),
 		capacityPlanner: capacity.NewPlanner(metricsClient, limitsAnalyzer),
-		llmAssistant:    func() *llm.Assistant { assistant, _ := llm.NewAssistant(); return assistant }(),
-		healthChecker:   monitoring.NewHealthChecker(discoveryEngine.GetK8sClient(), healthConfig),
+		llmAssistant:    llmAssistant,
+		healthChecker:   healthChecker,
+		approvalManager: approval.NewManager(),
+		auditLogger:     audit.NewLogger(storage.Get(), auditRetention()),
+		identity:        newIdentity(authConfig()),
 		requestCounter:  requestCounter,
 		requestDuration: requestDuration,
 		errorCounter:    errorCounter,
 	}
 
+	server.registerApprovalExecutors()
+
+	// Watch monitored resources so drift is detected as it happens
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	server.stopBackground = stopBackground
+	server.driftWatcher = drift.NewWatcher(driftDetector, healthChecker.GetAlertManager(), driftResyncInterval())
+	go server.startDriftWatcher(backgroundCtx)
+
 	// Start cache manager in background
 	go func() {
 		if err := cacheManager.Start(context.Background()); err != nil {
@@ -106,6 +150,66 @@ func NewServer(discoveryEngine *discovery.Engine, metricsClient *metrics.Client,
 	return server
 }
 
+// Stop stops the background components of the server
+func (s *Server) Stop() {
+	// Cancel first so a drift watcher that is still starting does not start afterwards
+	s.stopBackground()
+	s.driftWatcher.Stop()
+}
+
+// startDriftWatcher starts watching the configured or discovered namespaces for drift
+func (s *Server) startDriftWatcher(ctx context.Context) {
+	cfg := config.Get()
+	if cfg != nil && !cfg.Drift.WatchEnabled {
+		logrus.Info("Continuous drift detection is disabled")
+		return
+	}
+
+	var namespaces []string
+	if cfg != nil {
+		namespaces = cfg.Drift.Namespaces
+	}
+	if len(namespaces) == 0 {
+		result, err := s.discoveryEngine.DiscoverAll(ctx)
+		if err != nil {
+			logrus.Errorf("Failed to discover namespaces for drift watching: %v", err)
+			return
+		}
+		namespaces = append(namespaces, result.Environment.MimirNamespace)
+		for _, tenant := range result.TenantNamespaces {
+			namespaces = append(namespaces, tenant.Name)
+		}
+	}
+
+	if err := s.driftWatcher.Start(ctx, namespaces); err != nil && ctx.Err() == nil {
+		logrus.Errorf("Failed to start drift watcher: %v", err)
+	}
+}
+
+// authConfig returns how request users are identified
+func authConfig() config.AuthConfig {
+	if cfg := config.Get(); cfg != nil {
+		return cfg.Auth
+	}
+	return config.AuthConfig{}
+}
+
+// auditRetention returns how long audit entries are kept; zero keeps them forever
+func auditRetention() time.Duration {
+	if cfg := config.Get(); cfg != nil {
+		return time.Duration(cfg.Audit.RetentionDays) * 24 * time.Hour
+	}
+	return 90 * 24 * time.Hour
+}
+
+// driftResyncInterval returns how often the drift watcher re-checks every watched resource
+func driftResyncInterval() time.Duration {
+	if cfg := config.Get(); cfg != nil && cfg.Drift.ResyncMinutes > 0 {
+		return time.Duration(cfg.Drift.ResyncMinutes) * time.Minute
+	}
+	return 30 * time.Minute
+}
+
 // HealthCheck returns the health status of the service
 func (s *Server) HealthCheck(c *gin.Context) {
 	start := time.Now()
@@ -577,56 +681,23 @@ func (s *Server) GetDashboardMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, metrics)
 }
 
-// GetAuditLogs returns audit logs from actual cluster data
+// GetAuditLogs returns recorded audit entries filtered by time range, actor, action and tenant
 func (s *Server) GetAuditLogs(c *gin.Context) {
 	start := time.Now()
 
-	// Get discovery result to find real tenants
-	discoveryResult := s.cacheManager.GetDiscoveryResult()
-	if discoveryResult == nil {
-		s.recordError(c, "cache_not_ready", start)
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cache not ready, please try again"})
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		s.recordError(c, "validation_error", start)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid audit query: %v", err)})
 		return
 	}
 
-	// Build audit logs from real tenant data
-	var auditLogs []map[string]interface{}
-
-	// Add audit entries for discovered tenants
-	for _, tenant := range discoveryResult.TenantNamespaces {
-		auditLogs = append(auditLogs, map[string]interface{}{
-			"timestamp":   time.Now().UTC(),
-			"action":      "tenant_discovery",
-			"tenant":      tenant.Name,
-			"user":        "system",
-			"description": fmt.Sprintf("Discovered tenant %s", tenant.Name),
-		})
-	}
-
-	// Add audit entries for Mimir components
-	if discoveryResult.Environment != nil && discoveryResult.Environment.MimirComponents != nil {
-		for _, componentName := range discoveryResult.Environment.MimirComponents {
-			auditLogs = append(auditLogs, map[string]interface{}{
-				"timestamp":   time.Now().UTC(),
-				"action":      "component_discovery",
-				"tenant":      "mimir-system",
-				"user":        "system",
-				"description": fmt.Sprintf("Discovered Mimir component: %s", componentName),
-			})
-		}
-	}
-
-	// Sort by timestamp (newest first)
-	sort.Slice(auditLogs, func(i, j int) bool {
-		timeI, _ := time.Parse(time.RFC3339, auditLogs[i]["timestamp"].(string))
-		timeJ, _ := time.Parse(time.RFC3339, auditLogs[j]["timestamp"].(string))
-		return timeI.After(timeJ)
-	})
+	auditLogs := s.auditLogger.Query(filter)
 
 	response := map[string]interface{}{
 		"audit_logs":   auditLogs,
 		"total":        len(auditLogs),
-		"data_source":  "production",
+		"data_source":  "audit_log",
 		"last_updated": time.Now().UTC(),
 	}
 
@@ -785,18 +856,156 @@ func (s *Server) generateDriftRecommendations(report *drift.DriftReport) []strin
 	return recommendations
 }
 
+// GetDriftHistory returns recorded drift filtered by namespace, resource and risk level
+func (s *Server) GetDriftHistory(c *gin.Context) {
+	start := time.Now()
+
+	days := 7
+	if daysParam := c.Query("days"); daysParam != "" {
+		parsed, err := strconv.Atoi(daysParam)
+		if err != nil || parsed <= 0 {
+			s.recordError(c, "validation_error", start)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return
+		}
+		days = parsed
+	}
+
+	filter := drift.HistoryFilter{
+		Namespace: c.Query("namespace"),
+		Resource:  c.Query("resource"),
+		RiskLevel: c.Query("risk_level"),
+		Since:     time.Now().AddDate(0, 0, -days),
+	}
+
+	events, err := s.driftDetector.QueryDriftHistory(filter)
+	if err != nil {
+		s.recordError(c, "drift_history_error", start)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := map[string]interface{}{
+		"events": events,
+		"total":  len(events),
+		"days":   days,
+		"filters": map[string]interface{}{
+			"namespace":  filter.Namespace,
+			"resource":   filter.Resource,
+			"risk_level": filter.RiskLevel,
+		},
+		"since": filter.Since.UTC(),
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGitDrift compares the live cluster with the manifests in the configured Git checkout
+func (s *Server) GetGitDrift(c *gin.Context) {
+	start := time.Now()
+	ctx := c.Request.Context()
+
+	comparison, err := s.driftDetector.CompareWithGit(ctx)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, drift.ErrGitSourceNotConfigured) {
+			status = http.StatusServiceUnavailable
+		}
+		s.recordError(c, "git_drift_error", start)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, comparison)
+}
+
+// RemediateDrift reverts a drifted resource to its stored baseline
+func (s *Server) RemediateDrift(c *gin.Context) {
+	start := time.Now()
+	ctx := c.Request.Context()
+
+	var remediateRequest struct {
+		ResourceKey     string `json:"resource_key" binding:"required"`
+		ResourceVersion string `json:"resource_version"`
+		DryRun          bool   `json:"dry_run"`
+		Reason          string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&remediateRequest); err != nil {
+		s.recordError(c, "invalid_request", start)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	user := s.requestUser(c)
+	dryRun := remediateRequest.DryRun || c.Query("dry_run") == "true"
+	logrus.Infof("🔧 [DRIFT] Remediating %s (dry run: %v) requested by %s", remediateRequest.ResourceKey, dryRun, user)
+
+	result, err := s.driftDetector.RemediateResource(ctx, drift.RemediationRequest{
+		ResourceKey:     remediateRequest.ResourceKey,
+		ResourceVersion: remediateRequest.ResourceVersion,
+		DryRun:          dryRun,
+		Reason:          remediateRequest.Reason,
+		User:            user,
+	})
+	if err != nil {
+		logrus.Errorf("❌ [DRIFT] Failed to remediate %s: %v", remediateRequest.ResourceKey, err)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, drift.ErrBaselineNotFound), errors.Is(err, drift.ErrResourceNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, drift.ErrRemediationConflict):
+			status = http.StatusConflict
+		case errors.Is(err, drift.ErrRemediationUnsupported), errors.Is(err, drift.ErrInvalidResourceKey):
+			status = http.StatusBadRequest
+		}
+		s.recordError(c, "drift_remediation_error", start)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := "remediated"
+	switch {
+	case result.InSync:
+		status = "in_sync"
+	case result.DryRun:
+		status = "dry_run"
+	}
+	if !result.DryRun && s.driftWatcher != nil {
+		s.driftWatcher.ResolveAlert(result.ResourceKey)
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"status":      status,
+		"user":        user,
+		"remediation": result,
+	})
+}
+
 // CreateDriftBaseline creates a baseline for current configurations
 func (s *Server) CreateDriftBaseline(c *gin.Context) {
 	start := time.Now()
 	ctx := c.Request.Context()
 
-	// Get target namespaces
+	// Get target namespaces and, for named baselines, the set to snapshot into
 	var request struct {
-		Namespaces []string `json:"namespaces"`
+		Namespaces  []string `json:"namespaces"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Activate    bool     `json:"activate"`
 	}
 
-	if err := c.ShouldBindJSON(&request); err != nil {
-		// If no request body, use discovered namespaces
+	// The body is optional, but one that is sent must be valid
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		s.recordError(c, "invalid_request", start)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	if len(request.Namespaces) == 0 {
+		// If no namespaces were given, use discovered namespaces
 		result, err := s.discoveryEngine.DiscoverAll(ctx)
 		if err != nil {
 			s.recordError(c, "discovery_error", start)
@@ -810,6 +1019,27 @@ func (s *Server) CreateDriftBaseline(c *gin.Context) {
 		}
 	}
 
+	if request.Name != "" {
+		set, err := s.driftDetector.CreateNamedBaseline(ctx, request.Name, request.Description, s.requestUser(c), request.Namespaces, request.Activate)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, drift.ErrInvalidBaselineRef) {
+				status = http.StatusBadRequest
+			}
+			s.recordError(c, "baseline_creation_error", start)
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+
+		s.recordMetrics(c, http.StatusCreated, start)
+		c.JSON(http.StatusCreated, map[string]interface{}{
+			"message":   fmt.Sprintf("Baseline %s version %d created successfully", set.Name, set.Version),
+			"baseline":  set.Summary(),
+			"activated": request.Activate,
+		})
+		return
+	}
+
 	// Create baseline
 	err := s.driftDetector.CreateBaseline(ctx, request.Namespaces)
 	if err != nil {
@@ -828,6 +1058,87 @@ func (s *Server) CreateDriftBaseline(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+// ListDriftBaselines lists the stored named baseline versions
+func (s *Server) ListDriftBaselines(c *gin.Context) {
+	start := time.Now()
+
+	baselines, err := s.driftDetector.ListBaselineSets()
+	if err != nil {
+		s.recordError(c, "baseline_list_error", start)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"baselines": baselines,
+		"total":     len(baselines),
+	})
+}
+
+// GetDriftBaseline returns a named baseline; the latest version unless ?version= is given
+func (s *Server) GetDriftBaseline(c *gin.Context) {
+	start := time.Now()
+
+	version := 0
+	if versionParam := c.Query("version"); versionParam != "" {
+		parsed, err := strconv.Atoi(versionParam)
+		if err != nil || parsed <= 0 {
+			s.recordError(c, "invalid_request", start)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a positive number"})
+			return
+		}
+		version = parsed
+	}
+
+	set, err := s.driftDetector.GetBaselineSet(c.Param("name"), version)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, drift.ErrBaselineSetNotFound) {
+			status = http.StatusNotFound
+		}
+		s.recordError(c, "baseline_get_error", start)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, set)
+}
+
+// CompareDriftBaselines diffs two configuration states, e.g. ?from=pre-upgrade-2.10&to=current
+func (s *Server) CompareDriftBaselines(c *gin.Context) {
+	start := time.Now()
+	ctx := c.Request.Context()
+
+	from := c.Query("from")
+	if from == "" {
+		s.recordError(c, "invalid_request", start)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from is required (<name>[@<version>], active, git or current)"})
+		return
+	}
+	to := c.DefaultQuery("to", drift.BaselineRefCurrent)
+
+	comparison, err := s.driftDetector.CompareBaselines(ctx, from, to)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, drift.ErrInvalidBaselineRef):
+			status = http.StatusBadRequest
+		case errors.Is(err, drift.ErrBaselineSetNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, drift.ErrGitSourceNotConfigured):
+			status = http.StatusServiceUnavailable
+		}
+		s.recordError(c, "baseline_compare_error", start)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, comparison)
+}
+
 // GetCapacityReport generates a comprehensive capacity planning report
 func (s *Server) GetCapacityReport(c *gin.Context) {
 	start := time.Now()
@@ -1216,15 +1527,40 @@ func (s *Server) ScaleAlloyReplicas(c *gin.Context) {
 	start := time.Now()
 	ctx := c.Request.Context()
 
-	var request tuning.AlloyReplicaRequest
+	var request struct {
+		tuning.AlloyReplicaRequest
+		RequireApproval bool `json:"require_approval"`
+	}
 	if err := c.ShouldBindJSON(&request); err != nil {
 		s.recordError(c, "validation_error", start)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	if s.approvalManager.Enabled() || request.RequireApproval {
+		currentReplicas, err := s.alloyTuner.GetCurrentReplicas(ctx, request.Namespace, request.DeploymentName)
+		if err != nil {
+			s.recordError(c, "scaling_error", start)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		proposal := &approval.Proposal{
+			Type:      approval.ChangeAlloyScale,
+			Requester: s.requestUser(c),
+			Reason:    request.Reason,
+			AlloyScale: &approval.AlloyScaleChange{
+				AlloyReplicaRequest: request.AlloyReplicaRequest,
+				CurrentReplicas:     currentReplicas,
+			},
+		}
+		if s.submitForApproval(c, proposal, request.RequireApproval, start) {
+			return
+		}
+	}
+
 	// Perform scaling operation
-	response, err := s.alloyTuner.ScaleAlloyReplicas(ctx, request)
+	response, err := s.alloyTuner.ScaleAlloyReplicas(ctx, request.AlloyReplicaRequest)
 	if err != nil {
 		s.recordError(c, "scaling_error", start)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1309,15 +1645,98 @@ func (s *Server) ProcessLLMQuery(c *gin.Context) {
 	if request.MaxTokens == 0 {
 		request.MaxTokens = 500
 	}
-	if request.Temperature == 0 {
-		request.Temperature = 0.7
+	if request.Temperature == nil {
+		temperature := 0.7
+		request.Temperature = &temperature
+	}
+
+	if c.Query("stream") == "true" || strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		s.streamLLMQuery(c, request, start)
+		return
 	}
 
 	// Process the query
 	response, err := s.llmAssistant.ProcessQuery(ctx, request)
 	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, llm.ErrLLMUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		s.recordError(c, "llm_processing_error", start)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, response)
+}
+
+// streamLLMQuery answers a query as Server-Sent Events: sources, token, analysis, recommendations and done.
+// Failures before the first event are returned as JSON, later ones as an error event.
+func (s *Server) streamLLMQuery(c *gin.Context, request llm.QueryRequest, start time.Time) {
+	ctx := c.Request.Context()
+	streaming := false
+	emit := func(event llm.StreamEvent) error {
+		if !streaming {
+			c.Header("Cache-Control", "no-cache")
+			c.Header("Connection", "keep-alive")
+			c.Header("X-Accel-Buffering", "no") // Keep nginx ingresses from buffering the stream
+			streaming = true
+		}
+		c.SSEvent(event.Type, event.Data)
+		c.Writer.Flush()
+		// Stop generating once the client has gone away
+		return ctx.Err()
+	}
+
+	_, err := s.llmAssistant.ProcessQueryStream(ctx, request, emit)
+	if err != nil {
+		s.recordError(c, "llm_processing_error", start)
+		if !streaming {
+			status := http.StatusInternalServerError
+			if errors.Is(err, llm.ErrLLMUnavailable) {
+				status = http.StatusServiceUnavailable
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		if ctx.Err() == nil {
+			c.SSEvent("error", gin.H{"error": err.Error()})
+			c.Writer.Flush()
+		}
+		return
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+}
+
+// GenerateLLMPromQL turns a natural language question into a validated PromQL query
+func (s *Server) GenerateLLMPromQL(c *gin.Context) {
+	start := time.Now()
+	ctx := c.Request.Context()
+
+	var request llm.PromQLRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		s.recordError(c, "validation_error", start)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.MaxTokens == 0 {
+		request.MaxTokens = 300
+	}
+
+	response, err := s.llmAssistant.GeneratePromQL(ctx, request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, llm.ErrLLMUnavailable):
+			status = http.StatusServiceUnavailable
+		case errors.Is(err, llm.ErrInvalidPromQL):
+			status = http.StatusUnprocessableEntity
+		}
+		s.recordError(c, "llm_promql_error", start)
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -1825,6 +2244,23 @@ func (s *Server) GetRealMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, productionData)
 }
 
+// GetMetricNames returns the metric names resolved for each logical metric; refresh=true probes Mimir again
+func (s *Server) GetMetricNames(c *gin.Context) {
+	start := time.Now()
+	ctx := c.Request.Context()
+
+	var names *metrics.MetricNames
+	if c.Query("refresh") == "true" {
+		logrus.Infof("🔍 [API] GetMetricNames: Probing metric names again")
+		names = s.metricsClient.RefreshMetricNames(ctx)
+	} else {
+		names = s.metricsClient.MetricNames(ctx)
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, names)
+}
+
 // AnalyzeTenantIntelligently performs intelligent analysis of tenant limits
 func (s *Server) AnalyzeTenantIntelligently(c *gin.Context) {
 	start := time.Now()
@@ -1889,9 +2325,10 @@ func (s *Server) AnalyzeTenantIntelligently(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// UpdateTenantLimit updates a specific limit for a tenant
+// UpdateTenantLimit updates a specific limit for a tenant in the runtime overrides ConfigMap
 func (s *Server) UpdateTenantLimit(c *gin.Context) {
 	start := time.Now()
+	ctx := c.Request.Context()
 
 	// Get tenant name from path parameter
 	tenantName := c.Param("tenant")
@@ -1903,10 +2340,11 @@ func (s *Server) UpdateTenantLimit(c *gin.Context) {
 
 	// Parse request body
 	var updateRequest struct {
-		LimitName string      `json:"limit_name" binding:"required"`
-		NewValue  interface{} `json:"new_value" binding:"required"`
-		Reason    string      `json:"reason"`
-		ApplyNow  bool        `json:"apply_now"`
+		LimitName       string      `json:"limit_name" binding:"required"`
+		NewValue        interface{} `json:"new_value" binding:"required"`
+		Reason          string      `json:"reason"`
+		DryRun          bool        `json:"dry_run"`
+		RequireApproval bool        `json:"require_approval"`
 	}
 
 	if err := c.ShouldBindJSON(&updateRequest); err != nil {
@@ -1915,40 +2353,425 @@ func (s *Server) UpdateTenantLimit(c *gin.Context) {
 		return
 	}
 
+	if updateRequest.DryRun || c.Query("dry_run") == "true" {
+		logrus.Infof("🔍 [UPDATE] Dry-run of limit %s for tenant %s to %v", updateRequest.LimitName, tenantName, updateRequest.NewValue)
+
+		preview, err := s.limitsAnalyzer.PreviewTenantLimit(ctx, tenantName, updateRequest.LimitName, updateRequest.NewValue)
+		if err != nil {
+			logrus.Errorf("❌ [UPDATE] Failed to preview limit %s for tenant %s: %v", updateRequest.LimitName, tenantName, err)
+			s.recordError(c, "limit_preview_error", start)
+			c.JSON(limitErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to preview limit update: %v", err)})
+			return
+		}
+
+		s.recordMetrics(c, http.StatusOK, start)
+		c.JSON(http.StatusOK, map[string]interface{}{
+			"tenant_name":      tenantName,
+			"limit_name":       updateRequest.LimitName,
+			"reason":           updateRequest.Reason,
+			"status":           "dry_run",
+			"applied":          false,
+			"valid":            preview.Valid,
+			"validation":       preview.Validation,
+			"changes":          preview.Changes,
+			"current_limits":   preview.CurrentLimits,
+			"diff":             preview.Diff,
+			"config_map":       preview.ConfigMap,
+			"namespace":        preview.Namespace,
+			"data_key":         preview.DataKey,
+			"resource_version": preview.ResourceVersion,
+		})
+		return
+	}
+
+	if validation := s.limitsAnalyzer.ValidateLimitValue(updateRequest.LimitName, updateRequest.NewValue); !validation.Valid {
+		s.recordError(c, "validation_error", start)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit value", "validation": validation})
+		return
+	}
+
+	user := s.requestUser(c)
+
+	if s.approvalManager.Enabled() || updateRequest.RequireApproval {
+		preview, err := s.limitsAnalyzer.PreviewTenantLimit(ctx, tenantName, updateRequest.LimitName, updateRequest.NewValue)
+		if err != nil {
+			s.recordError(c, "limit_preview_error", start)
+			c.JSON(limitErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to preview limit update: %v", err)})
+			return
+		}
+
+		proposal := &approval.Proposal{
+			Type:      approval.ChangeLimitUpdate,
+			Requester: user,
+			Reason:    updateRequest.Reason,
+			LimitUpdate: &approval.LimitUpdateChange{
+				TenantName:   tenantName,
+				LimitName:    updateRequest.LimitName,
+				CurrentValue: preview.Changes[0].OldValue,
+				NewValue:     preview.Changes[0].NewValue,
+				Diff:         preview.Diff,
+			},
+		}
+		if s.submitForApproval(c, proposal, updateRequest.RequireApproval, start) {
+			return
+		}
+	}
+
 	logrus.Infof("🔧 [UPDATE] Updating limit %s for tenant %s to %v", updateRequest.LimitName, tenantName, updateRequest.NewValue)
 
-	// TODO: Implement actual limit update logic
-	// This would involve:
-	// 1. Validating the new value
-	// 2. Updating runtime overrides ConfigMap
-	// 3. Applying the change to Mimir
-	// 4. Monitoring for any issues
+	result, err := s.limitsAnalyzer.UpdateTenantLimit(ctx, tenantName, updateRequest.LimitName, updateRequest.NewValue, updateRequest.Reason, user)
+	if err != nil {
+		logrus.Errorf("❌ [UPDATE] Failed to update limit %s for tenant %s: %v", updateRequest.LimitName, tenantName, err)
+		s.recordError(c, "limit_update_error", start)
+		c.JSON(limitErrorStatus(err), gin.H{"error": fmt.Sprintf("Failed to update limit: %v", err)})
+		return
+	}
 
-	// For now, return a success response
+	change := result.Changes[0]
 	response := map[string]interface{}{
+		"change_id":        change.ChangeID,
+		"tenant_name":      tenantName,
+		"limit_name":       change.LimitName,
+		"old_value":        change.OldValue,
+		"new_value":        change.NewValue,
+		"reason":           updateRequest.Reason,
+		"user":             user,
+		"status":           "applied",
+		"applied":          true,
+		"config_map":       result.ConfigMap,
+		"namespace":        result.Namespace,
+		"resource_version": result.ResourceVersion,
+		"update_time":      result.UpdatedAt.UTC(),
+		"message":          fmt.Sprintf("Limit updated in runtime overrides %s/%s", result.Namespace, result.ConfigMap),
+	}
+
+	logrus.Infof("✅ [UPDATE] Limit %s for %s changed from %v to %v (resourceVersion %s)",
+		change.LimitName, tenantName, change.OldValue, change.NewValue, result.ResourceVersion)
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTenantLimitHistory returns the recorded limit changes for a tenant
+func (s *Server) GetTenantLimitHistory(c *gin.Context) {
+	start := time.Now()
+
+	tenantName := c.Param("tenant")
+	limitName := c.Query("limit_name")
+
+	history := s.limitsAnalyzer.GetLimitHistory(tenantName, limitName)
+	if history == nil {
+		history = []*limits.LimitChangeRecord{}
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, gin.H{
 		"tenant_name": tenantName,
-		"limit_name":  updateRequest.LimitName,
-		"old_value":   "to_be_implemented",
-		"new_value":   updateRequest.NewValue,
-		"reason":      updateRequest.Reason,
-		"status":      "pending",
-		"applied":     updateRequest.ApplyNow,
-		"update_time": time.Now().UTC(),
-		"message":     "Limit update request received. Implementation pending.",
+		"changes":     history,
+		"total":       len(history),
+	})
+}
+
+// RollbackTenantLimit restores the value replaced by a recorded limit change
+func (s *Server) RollbackTenantLimit(c *gin.Context) {
+	start := time.Now()
+	ctx := c.Request.Context()
+
+	tenantName := c.Param("tenant")
+	changeID := c.Param("changeId")
+
+	var rollbackRequest struct {
+		Reason          string `json:"reason"`
+		Force           bool   `json:"force"`
+		RequireApproval bool   `json:"require_approval"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&rollbackRequest); err != nil {
+			s.recordError(c, "invalid_request", start)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+			return
+		}
 	}
 
-	logrus.Infof("✅ [UPDATE] Limit update request processed for %s: %s", tenantName, updateRequest.LimitName)
+	user := s.requestUser(c)
+
+	if s.approvalManager.Enabled() || rollbackRequest.RequireApproval {
+		preview, err := s.limitsAnalyzer.PreviewRollback(ctx, tenantName, changeID, rollbackRequest.Force)
+		if err != nil {
+			s.recordError(c, "limit_rollback_error", start)
+			c.JSON(limitErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		planned := preview.Changes[0]
+		proposal := &approval.Proposal{
+			Type:      approval.ChangeLimitRollback,
+			Requester: user,
+			Reason:    rollbackRequest.Reason,
+			LimitUpdate: &approval.LimitUpdateChange{
+				TenantName:   tenantName,
+				LimitName:    planned.LimitName,
+				CurrentValue: planned.OldValue,
+				NewValue:     planned.NewValue,
+				Diff:         preview.Diff,
+				RollbackOf:   changeID,
+				Force:        rollbackRequest.Force,
+			},
+		}
+		if s.submitForApproval(c, proposal, rollbackRequest.RequireApproval, start) {
+			return
+		}
+	}
+
+	logrus.Infof("⏪ [ROLLBACK] Rolling back change %s for tenant %s (requested by %s)", changeID, tenantName, user)
+
+	result, err := s.limitsAnalyzer.RollbackLimitChange(ctx, tenantName, changeID, rollbackRequest.Reason, user, rollbackRequest.Force)
+	if err != nil {
+		logrus.Errorf("❌ [ROLLBACK] Failed to roll back change %s for tenant %s: %v", changeID, tenantName, err)
+		s.recordError(c, "limit_rollback_error", start)
+		c.JSON(limitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	change := result.Changes[0]
+	logrus.Infof("✅ [ROLLBACK] Limit %s for %s restored to %v", change.LimitName, tenantName, change.NewValue)
 
 	s.recordMetrics(c, http.StatusOK, start)
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"change_id":        change.ChangeID,
+		"rollback_of":      changeID,
+		"tenant_name":      tenantName,
+		"limit_name":       change.LimitName,
+		"old_value":        change.OldValue,
+		"new_value":        change.NewValue,
+		"action":           change.Action,
+		"user":             user,
+		"status":           "rolled_back",
+		"config_map":       result.ConfigMap,
+		"namespace":        result.Namespace,
+		"resource_version": result.ResourceVersion,
+		"update_time":      result.UpdatedAt.UTC(),
+	})
 }
 
-// GetLimitRecommendations gets intelligent recommendations for all tenants
-func (s *Server) GetLimitRecommendations(c *gin.Context) {
+// ListApprovals lists change proposals, optionally filtered by state
+func (s *Server) ListApprovals(c *gin.Context) {
+	start := time.Now()
+
+	proposals := s.approvalManager.List(approval.ProposalState(c.Query("state")))
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, gin.H{
+		"proposals": proposals,
+		"total":     len(proposals),
+		"enabled":   s.approvalManager.Enabled(),
+	})
+}
+
+// GetApproval returns a single change proposal
+func (s *Server) GetApproval(c *gin.Context) {
+	start := time.Now()
+
+	proposal, err := s.approvalManager.Get(c.Param("id"))
+	if err != nil {
+		s.recordError(c, "proposal_not_found", start)
+		c.JSON(approvalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, proposal)
+}
+
+// ApproveProposal records an approval and applies the change once enough approvals are collected
+func (s *Server) ApproveProposal(c *gin.Context) {
+	s.decideProposal(c, "approve")
+}
+
+// RejectProposal rejects a pending change proposal
+func (s *Server) RejectProposal(c *gin.Context) {
+	s.decideProposal(c, "reject")
+}
+
+// RetryProposal applies an approved proposal again after applying it failed
+func (s *Server) RetryProposal(c *gin.Context) {
+	s.decideProposal(c, "retry")
+}
+
+func (s *Server) decideProposal(c *gin.Context, decision string) {
 	start := time.Now()
 	ctx := c.Request.Context()
 
-	// Get discovery data for tenant information
+	var decisionRequest struct {
+		Comment string `json:"comment"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&decisionRequest); err != nil {
+			s.recordError(c, "invalid_request", start)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+			return
+		}
+	}
+
+	user := s.requestUser(c)
+	if user == anonymousUser {
+		s.recordError(c, "unidentified_user", start)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "approvals require a user authenticated by a trusted proxy (auth.trusted_proxies)"})
+		return
+	}
+
+	var proposal *approval.Proposal
+	var err error
+	switch decision {
+	case "approve":
+		proposal, err = s.approvalManager.Approve(ctx, c.Param("id"), user, decisionRequest.Comment)
+	case "reject":
+		proposal, err = s.approvalManager.Reject(c.Param("id"), user, decisionRequest.Comment)
+	default:
+		proposal, err = s.approvalManager.Retry(ctx, c.Param("id"), user)
+	}
+
+	if err != nil {
+		logrus.Errorf("❌ [APPROVAL] Decision on proposal %s by %s failed: %v", c.Param("id"), user, err)
+		s.recordError(c, "approval_error", start)
+		response := gin.H{"error": err.Error()}
+		if proposal != nil {
+			response["proposal"] = proposal
+		}
+		c.JSON(approvalErrorStatus(err), response)
+		return
+	}
+
+	logrus.Infof("✅ [APPROVAL] Proposal %s is now %s", proposal.ID, proposal.State)
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, proposal)
+}
+
+// submitForApproval stores a proposal when the policy requires approvals and reports whether it did
+func (s *Server) submitForApproval(c *gin.Context, proposal *approval.Proposal, requested bool, start time.Time) bool {
+	if requested {
+		proposal.RequiredApprovals = 1
+	}
+	if s.approvalManager.RequiredApprovals(proposal) == 0 && !requested {
+		return false
+	}
+
+	submitted := s.approvalManager.Submit(proposal)
+	logrus.Infof("📝 [APPROVAL] %s proposal %s submitted by %s, needs %d approval(s)",
+		submitted.Type, submitted.ID, submitted.Requester, submitted.RequiredApprovals)
+
+	s.recordMetrics(c, http.StatusAccepted, start)
+	c.JSON(http.StatusAccepted, gin.H{
+		"status":   "pending_approval",
+		"message":  fmt.Sprintf("Change submitted for approval, %d approval(s) required", submitted.RequiredApprovals),
+		"proposal": submitted,
+	})
+	return true
+}
+
+// registerApprovalExecutors wires approved proposals to the components that apply them
+func (s *Server) registerApprovalExecutors() {
+	// Approvers saw the current values of the proposal, so changes only apply while those still hold
+	s.approvalManager.RegisterExecutor(approval.ChangeLimitUpdate, func(ctx context.Context, proposal *approval.Proposal) (interface{}, error) {
+		reason := fmt.Sprintf("%s (proposal %s)", proposal.Reason, proposal.ID)
+		return s.limitsAnalyzer.ApplyLimitChanges(ctx, []limits.LimitChange{approvedLimitChange(*proposal.LimitUpdate)}, reason, proposal.Requester)
+	})
+
+	s.approvalManager.RegisterExecutor(approval.ChangeLimitRollback, func(ctx context.Context, proposal *approval.Proposal) (interface{}, error) {
+		change := proposal.LimitUpdate
+		reason := fmt.Sprintf("%s (proposal %s)", proposal.Reason, proposal.ID)
+		return s.limitsAnalyzer.RollbackLimitChange(ctx, change.TenantName, change.RollbackOf, reason, proposal.Requester, change.Force)
+	})
+
+	s.approvalManager.RegisterExecutor(approval.ChangeBulkLimitUpdate, func(ctx context.Context, proposal *approval.Proposal) (interface{}, error) {
+		changes := make([]limits.LimitChange, 0, len(proposal.BulkLimitUpdate.Changes))
+		for _, change := range proposal.BulkLimitUpdate.Changes {
+			changes = append(changes, approvedLimitChange(change))
+		}
+		reason := fmt.Sprintf("%s (proposal %s)", proposal.Reason, proposal.ID)
+		return s.limitsAnalyzer.ApplyLimitChanges(ctx, changes, reason, proposal.Requester)
+	})
+
+	s.approvalManager.RegisterExecutor(approval.ChangeAlloyScale, func(ctx context.Context, proposal *approval.Proposal) (interface{}, error) {
+		request := proposal.AlloyScale.AlloyReplicaRequest
+		current, err := s.alloyTuner.GetCurrentReplicas(ctx, request.Namespace, request.DeploymentName)
+		if err != nil {
+			return nil, err
+		}
+		if current != proposal.AlloyScale.CurrentReplicas {
+			return nil, fmt.Errorf("%w: %s/%s has %d replicas, not %d",
+				approval.ErrStaleProposal, request.Namespace, request.DeploymentName, current, proposal.AlloyScale.CurrentReplicas)
+		}
+		return s.alloyTuner.ScaleAlloyReplicas(ctx, request)
+	})
+}
+
+// approvedLimitChange turns an approved limit update into a change that only applies while the limit keeps its current value
+func approvedLimitChange(change approval.LimitUpdateChange) limits.LimitChange {
+	return limits.LimitChange{
+		TenantID:      change.TenantName,
+		LimitName:     change.LimitName,
+		NewValue:      change.NewValue,
+		OldValue:      change.CurrentValue,
+		CheckOldValue: true,
+	}
+}
+
+// limitErrorStatus maps errors of the limits updater to HTTP status codes
+func limitErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, limits.ErrInvalidLimitChange):
+		return http.StatusBadRequest
+	case errors.Is(err, limits.ErrOverridesNotFound), errors.Is(err, limits.ErrChangeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, limits.ErrChangeConflict):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func approvalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, approval.ErrProposalNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, approval.ErrInvalidState), errors.Is(err, approval.ErrStaleProposal):
+		return http.StatusConflict
+	case errors.Is(err, approval.ErrNotAllowed):
+		return http.StatusForbidden
+	}
+	// Errors of applying an approved limit change
+	return limitErrorStatus(err)
+}
+
+// requestUser identifies the caller from the header set by the trusted authenticating proxy
+func (s *Server) requestUser(c *gin.Context) string {
+	return s.identity.user(c)
+}
+
+// ApplyLimitRecommendations applies a selection of limit recommendations as one overrides update
+func (s *Server) ApplyLimitRecommendations(c *gin.Context) {
+	start := time.Now()
+	ctx := c.Request.Context()
+
+	var applyRequest struct {
+		limits.RecommendationSelector
+		Reason          string `json:"reason"`
+		DryRun          bool   `json:"dry_run"`
+		RequireApproval bool   `json:"require_approval"`
+	}
+
+	if err := c.ShouldBindJSON(&applyRequest); err != nil {
+		s.recordError(c, "invalid_request", start)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+	if err := applyRequest.RecommendationSelector.Validate(); err != nil {
+		s.recordError(c, "invalid_request", start)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	discoveryResult := s.cacheManager.GetDiscoveryResult()
 	if discoveryResult == nil {
 		s.recordError(c, "cache_not_ready", start)
@@ -1956,21 +2779,108 @@ func (s *Server) GetLimitRecommendations(c *gin.Context) {
 		return
 	}
 
-	// Get all tenant names
+	user := s.requestUser(c)
+	tenantNames := recommendationTenantNames(discoveryResult)
+
+	if !applyRequest.DryRun && (s.approvalManager.Enabled() || applyRequest.RequireApproval) {
+		// Approvers decide on the concrete changes of a dry run, not on the selector
+		planned, err := s.limitsAnalyzer.ApplyRecommendations(ctx, tenantNames, applyRequest.RecommendationSelector, applyRequest.Reason, user, true)
+		if err != nil {
+			logrus.Errorf("❌ [BULK] Failed to plan limit recommendations: %v", err)
+			s.recordError(c, "bulk_apply_error", start)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to plan recommendations: %v", err)})
+			return
+		}
+
+		bulk := &approval.BulkLimitUpdateChange{Changes: []approval.LimitUpdateChange{}, Diff: planned.Diff}
+		for _, item := range planned.Items {
+			if item.Status == "planned" {
+				bulk.Changes = append(bulk.Changes, approval.LimitUpdateChange{
+					TenantName:   item.TenantID,
+					LimitName:    item.LimitName,
+					CurrentValue: item.CurrentValue,
+					NewValue:     item.RecommendedValue,
+				})
+			}
+		}
+		if len(bulk.Changes) == 0 {
+			s.recordMetrics(c, http.StatusOK, start)
+			c.JSON(http.StatusOK, planned)
+			return
+		}
+
+		proposal := &approval.Proposal{
+			Type:            approval.ChangeBulkLimitUpdate,
+			Requester:       user,
+			Reason:          applyRequest.Reason,
+			BulkLimitUpdate: bulk,
+		}
+		if s.submitForApproval(c, proposal, applyRequest.RequireApproval, start) {
+			return
+		}
+	}
+
+	logrus.Infof("🔧 [BULK] Applying limit recommendations (dry run: %v) requested by %s", applyRequest.DryRun, user)
+
+	result, err := s.limitsAnalyzer.ApplyRecommendations(ctx, tenantNames,
+		applyRequest.RecommendationSelector, applyRequest.Reason, user, applyRequest.DryRun)
+	if err != nil {
+		logrus.Errorf("❌ [BULK] Failed to apply limit recommendations: %v", err)
+		s.recordError(c, "bulk_apply_error", start)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to apply recommendations: %v", err)})
+		return
+	}
+
+	logrus.Infof("✅ [BULK] Recommendations processed: %d applied, %d skipped, %d failed",
+		result.Applied, result.Skipped, result.Failed)
+
+	s.recordMetrics(c, http.StatusOK, start)
+	c.JSON(http.StatusOK, result)
+}
+
+// recommendationTenantNames lists the unique tenant names known to discovery
+func recommendationTenantNames(discoveryResult *discovery.DiscoveryResult) []string {
+	seen := make(map[string]bool)
 	var tenantNames []string
 
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			tenantNames = append(tenantNames, name)
+		}
+	}
+
 	// Add discovered tenants
 	for _, tenant := range discoveryResult.TenantNamespaces {
-		tenantNames = append(tenantNames, tenant.Name)
+		add(tenant.Name)
 	}
 
 	// Add detected tenants
 	if discoveryResult.Environment != nil && discoveryResult.Environment.DetectedTenants != nil {
 		for _, detectedTenant := range discoveryResult.Environment.DetectedTenants {
-			tenantNames = append(tenantNames, detectedTenant.Name)
+			add(detectedTenant.Name)
 		}
 	}
 
+	return tenantNames
+}
+
+// GetLimitRecommendations gets intelligent recommendations for all tenants
+func (s *Server) GetLimitRecommendations(c *gin.Context) {
+	start := time.Now()
+	ctx := c.Request.Context()
+
+	// Get discovery data for tenant information
+	discoveryResult := s.cacheManager.GetDiscoveryResult()
+	if discoveryResult == nil {
+		s.recordError(c, "cache_not_ready", start)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Cache not ready, please try again"})
+		return
+	}
+
+	// Get all tenant names
+	tenantNames := recommendationTenantNames(discoveryResult)
+
 	// Perform intelligent analysis for each tenant
 	var allRecommendations []map[string]interface{}
 	var totalRiskScore float64
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/discovery"
+	"github.com/akshaydubey29/mimirInsights/pkg/drift"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// newTestServer creates a server with unregistered metrics and the given drift detector
+func newTestServer(driftDetector *drift.Detector) *Server {
+	return &Server{
+		driftDetector:   driftDetector,
+		identity:        newIdentity(authConfig()),
+		requestCounter:  prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_requests_total"}, []string{"method", "endpoint", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "test_request_duration_seconds"}, []string{"method", "endpoint"}),
+		errorCounter:    prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_errors_total"}, []string{"type"}),
+	}
+}
+
+func serve(handler gin.HandlerFunc, method, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(method, "/api/drift", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler(c)
+	return recorder
+}
+
+func TestRemediateDriftRejectsBadRequests(t *testing.T) {
+	server := newTestServer(drift.NewDetector(&discovery.Engine{}))
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "malformed body", body: `{"resource_key":`, wantStatus: http.StatusBadRequest},
+		{name: "missing key", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid key", body: `{"resource_key": "runtime-overrides"}`, wantStatus: http.StatusBadRequest, wantError: "invalid resource key"},
+		{name: "key without a name", body: `{"resource_key": "configmap/mimir/"}`, wantStatus: http.StatusBadRequest, wantError: "invalid resource key"},
+		{name: "unsupported kind", body: `{"resource_key": "deployment/mimir/ingester"}`, wantStatus: http.StatusBadRequest, wantError: "not supported"},
+		{name: "no baseline", body: `{"resource_key": "configmap/mimir/unknown-config"}`, wantStatus: http.StatusNotFound, wantError: "baseline not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := serve(server.RemediateDrift, http.MethodPost, tt.body)
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.wantError) {
+				t.Errorf("body = %s, want an error mentioning %q", recorder.Body, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCreateDriftBaselineRejectsMalformedBody(t *testing.T) {
+	server := newTestServer(drift.NewDetector(&discovery.Engine{}))
+
+	for _, body := range []string{`{"namespaces": ["mimir"]`, `{"namespaces": "mimir"}`, `not json`} {
+		recorder := serve(server.CreateDriftBaseline, http.MethodPost, body)
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, want 400 (%s)", body, recorder.Code, recorder.Body)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+	"github.com/akshaydubey29/mimirInsights/pkg/tuning"
+	"github.com/sirupsen/logrus"
+)
+
+// ProposalState represents the lifecycle state of a change proposal
+type ProposalState string
+
+const (
+	StatePending  ProposalState = "pending"
+	StateApproved ProposalState = "approved"
+	StateRejected ProposalState = "rejected"
+	StateApplied  ProposalState = "applied"
+	StateExpired  ProposalState = "expired"
+	StateFailed   ProposalState = "failed" // Approved but applying failed; can be retried
+)
+
+// ChangeType represents the kind of change a proposal carries
+type ChangeType string
+
+const (
+	ChangeLimitUpdate     ChangeType = "limit_update"
+	ChangeLimitRollback   ChangeType = "limit_rollback"
+	ChangeBulkLimitUpdate ChangeType = "bulk_limit_update"
+	ChangeAlloyScale      ChangeType = "alloy_scale"
+)
+
+// executionTimeout bounds applying an approved proposal, which no longer depends on the approving request
+const executionTimeout = 2 * time.Minute
+
+var (
+	// ErrProposalNotFound is returned when a proposal does not exist
+	ErrProposalNotFound = errors.New("proposal not found")
+	// ErrInvalidState is returned when a proposal cannot transition from its current state
+	ErrInvalidState = errors.New("invalid proposal state")
+	// ErrNotAllowed is returned when a user may not decide on a proposal
+	ErrNotAllowed = errors.New("not allowed")
+	// ErrStaleProposal is returned when the state a proposal was approved against has changed
+	ErrStaleProposal = errors.New("proposal is stale")
+)
+
+// LimitUpdateChange describes a proposed tenant limit update
+type LimitUpdateChange struct {
+	TenantName   string      `json:"tenant_name"`
+	LimitName    string      `json:"limit_name"`
+	CurrentValue interface{} `json:"current_value"`
+	NewValue     interface{} `json:"new_value"`
+	Diff         string      `json:"diff,omitempty"`
+	RollbackOf   string      `json:"rollback_of,omitempty"` // Recorded change a limit_rollback reverts
+	Force        bool        `json:"force,omitempty"`       // Roll back even if the limit changed since
+}
+
+// BulkLimitUpdateChange describes limit updates applied together in one runtime overrides update
+type BulkLimitUpdateChange struct {
+	Changes []LimitUpdateChange `json:"changes"`
+	Diff    string              `json:"diff,omitempty"`
+}
+
+// AlloyScaleChange describes a proposed Alloy replica change
+type AlloyScaleChange struct {
+	tuning.AlloyReplicaRequest
+	CurrentReplicas int32 `json:"current_replicas"`
+}
+
+// Decision represents an approval or rejection by a user
+type Decision struct {
+	User      string    `json:"user"`
+	Comment   string    `json:"comment,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Proposal represents a change waiting for approval
+type Proposal struct {
+	ID                string                 `json:"id"`
+	Type              ChangeType             `json:"type"`
+	State             ProposalState          `json:"state"`
+	Requester         string                 `json:"requester"`
+	Reason            string                 `json:"reason"`
+	RequiredApprovals int                    `json:"required_approvals"`
+	Approvals         []Decision             `json:"approvals"`
+	Rejection         *Decision              `json:"rejection,omitempty"`
+	LimitUpdate       *LimitUpdateChange     `json:"limit_update,omitempty"` // Also set for limit_rollback
+	BulkLimitUpdate   *BulkLimitUpdateChange `json:"bulk_limit_update,omitempty"`
+	AlloyScale        *AlloyScaleChange      `json:"alloy_scale,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"`
+	ExpiresAt         time.Time              `json:"expires_at"`
+	AppliedAt         *time.Time             `json:"applied_at,omitempty"`
+	Attempts          int                    `json:"attempts,omitempty"`
+	Result            interface{}            `json:"result,omitempty"`
+	Error             string                 `json:"error,omitempty"`
+}
+
+// Executor applies an approved proposal
+type Executor func(ctx context.Context, proposal *Proposal) (interface{}, error)
+
+// Manager handles change proposals and their approvals
+type Manager struct {
+	config    config.ApprovalConfig
+	proposals map[string]*Proposal
+	executors map[ChangeType]Executor
+	store     storage.Store
+	mutex     sync.Mutex
+}
+
+var proposalSequence uint64
+
+// NewManager creates a new approval manager from the global configuration
+func NewManager() *Manager {
+	var approvalConfig config.ApprovalConfig
+	if cfg := config.Get(); cfg != nil {
+		approvalConfig = cfg.Approval
+	}
+	if approvalConfig.ExpiryHours <= 0 {
+		approvalConfig.ExpiryHours = 24
+	}
+
+	manager := &Manager{
+		config:    approvalConfig,
+		proposals: make(map[string]*Proposal),
+		executors: make(map[ChangeType]Executor),
+		store:     storage.Get(),
+	}
+	manager.load()
+	return manager
+}
+
+// load restores persisted proposals so pending changes survive restarts
+func (m *Manager) load() {
+	err := m.store.ForEach(storage.BucketApprovalProposals, func(key string, value []byte) error {
+		var proposal Proposal
+		if err := storage.DecodeJSON(value, &proposal); err != nil {
+			logrus.Warnf("Skipping malformed proposal %s: %v", key, err)
+			return nil
+		}
+		m.proposals[proposal.ID] = &proposal
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to load change proposals: %v", err)
+		return
+	}
+
+	// A proposal still approved was being applied when the process stopped
+	for _, proposal := range m.proposals {
+		if proposal.State == StateApproved {
+			proposal.State = StateFailed
+			proposal.Error = "interrupted by a restart while being applied"
+			m.save(proposal)
+		}
+	}
+	if len(m.proposals) > 0 {
+		logrus.Infof("Loaded %d change proposals from storage", len(m.proposals))
+	}
+}
+
+// save persists a proposal; callers must hold the lock
+func (m *Manager) save(proposal *Proposal) {
+	if err := storage.PutJSON(m.store, storage.BucketApprovalProposals, proposal.ID, proposal); err != nil {
+		logrus.Errorf("Failed to persist proposal %s: %v", proposal.ID, err)
+	}
+}
+
+// Enabled reports whether changes must go through the approval workflow
+func (m *Manager) Enabled() bool {
+	return m.config.Enabled
+}
+
+// RegisterExecutor registers the function that applies proposals of a type
+func (m *Manager) RegisterExecutor(changeType ChangeType, executor Executor) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.executors[changeType] = executor
+}
+
+// RequiredApprovals evaluates the policy for a proposal; a rule applies when any change of the proposal matches it
+func (m *Manager) RequiredApprovals(proposal *Proposal) int {
+	required := m.config.DefaultApprovals
+	increases := proposalIncreases(proposal)
+
+	for _, rule := range m.config.Rules {
+		if !ruleAppliesTo(rule, proposal.Type) {
+			continue
+		}
+		matched := false
+		for _, increase := range increases {
+			if rule.LimitName != "" && rule.LimitName != increase.limitName {
+				continue
+			}
+			// Unknown factors (e.g. a limit that was not set before) are treated as matching
+			if rule.MinIncreaseFactor > 0 && increase.factor > 0 && increase.factor <= rule.MinIncreaseFactor {
+				continue
+			}
+			matched = true
+			break
+		}
+		if matched && rule.Approvals > required {
+			required = rule.Approvals
+		}
+	}
+
+	if required < 0 {
+		required = 0
+	}
+	return required
+}
+
+// ruleAppliesTo reports whether a rule covers proposals of a type.
+// A limit_update rule covers every way of changing limits, so rollbacks and bulk updates cannot bypass it.
+func ruleAppliesTo(rule config.ApprovalRule, changeType ChangeType) bool {
+	switch ChangeType(rule.ChangeType) {
+	case "", changeType:
+		return true
+	case ChangeLimitUpdate:
+		return changeType == ChangeLimitRollback || changeType == ChangeBulkLimitUpdate
+	}
+	return false
+}
+
+// Submit stores a new pending proposal
+func (m *Manager) Submit(proposal *Proposal) *Proposal {
+	now := time.Now()
+	proposal.ID = fmt.Sprintf("prop_%d_%d", now.Unix(), atomic.AddUint64(&proposalSequence, 1))
+	proposal.State = StatePending
+	// Callers may ask for more approvals than the policy requires, never fewer
+	if required := m.RequiredApprovals(proposal); required > proposal.RequiredApprovals {
+		proposal.RequiredApprovals = required
+	}
+	proposal.Approvals = []Decision{}
+	proposal.CreatedAt = now
+	proposal.ExpiresAt = now.Add(time.Duration(m.config.ExpiryHours) * time.Hour)
+
+	m.mutex.Lock()
+	m.proposals[proposal.ID] = proposal
+	m.save(proposal)
+	m.mutex.Unlock()
+
+	logrus.Infof("Change proposal %s (%s) submitted by %s, requires %d approval(s)",
+		proposal.ID, proposal.Type, proposal.Requester, proposal.RequiredApprovals)
+
+	return copyProposal(proposal)
+}
+
+// List returns proposals, optionally filtered by state, newest first
+func (m *Manager) List(state ProposalState) []*Proposal {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.expirePending()
+
+	result := []*Proposal{}
+	for _, proposal := range m.proposals {
+		if state != "" && proposal.State != state {
+			continue
+		}
+		result = append(result, copyProposal(proposal))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+// Get returns a proposal by ID
+func (m *Manager) Get(id string) (*Proposal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.expirePending()
+
+	proposal, exists := m.proposals[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrProposalNotFound, id)
+	}
+	return copyProposal(proposal), nil
+}
+
+// Approve records an approval and applies the proposal once enough approvals are collected
+func (m *Manager) Approve(ctx context.Context, id, user, comment string) (*Proposal, error) {
+	m.mutex.Lock()
+
+	m.expirePending()
+
+	proposal, exists := m.proposals[id]
+	if !exists {
+		m.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrProposalNotFound, id)
+	}
+	if proposal.State != StatePending {
+		m.mutex.Unlock()
+		return nil, fmt.Errorf("%w: proposal %s is %s", ErrInvalidState, id, proposal.State)
+	}
+	if user == proposal.Requester {
+		m.mutex.Unlock()
+		return nil, fmt.Errorf("%w: requester %s cannot approve their own proposal", ErrNotAllowed, user)
+	}
+	for _, approval := range proposal.Approvals {
+		if approval.User == user {
+			m.mutex.Unlock()
+			return nil, fmt.Errorf("%w: %s has already approved proposal %s", ErrNotAllowed, user, id)
+		}
+	}
+
+	proposal.Approvals = append(proposal.Approvals, Decision{User: user, Comment: comment, Timestamp: time.Now()})
+	logrus.Infof("Proposal %s approved by %s (%d/%d)", id, user, len(proposal.Approvals), proposal.RequiredApprovals)
+
+	if len(proposal.Approvals) < proposal.RequiredApprovals {
+		m.save(proposal)
+		result := copyProposal(proposal)
+		m.mutex.Unlock()
+		return result, nil
+	}
+
+	// Only the caller that moves the proposal to approved executes it
+	return m.startExecution(ctx, proposal)
+}
+
+// Retry applies a failed proposal again with its original approvals
+func (m *Manager) Retry(ctx context.Context, id, user string) (*Proposal, error) {
+	m.mutex.Lock()
+
+	proposal, exists := m.proposals[id]
+	if !exists {
+		m.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrProposalNotFound, id)
+	}
+	if proposal.State != StateFailed {
+		m.mutex.Unlock()
+		return nil, fmt.Errorf("%w: proposal %s is %s, only failed proposals can be retried", ErrInvalidState, id, proposal.State)
+	}
+
+	logrus.Infof("Retrying proposal %s for %s", id, user)
+	return m.startExecution(ctx, proposal)
+}
+
+// startExecution marks a proposal approved and applies it; callers must hold the lock, which is released
+func (m *Manager) startExecution(ctx context.Context, proposal *Proposal) (*Proposal, error) {
+	proposal.State = StateApproved
+	proposal.Attempts++
+	m.save(proposal)
+	executor := m.executors[proposal.Type]
+	snapshot := copyProposal(proposal)
+	m.mutex.Unlock()
+
+	return m.execute(ctx, proposal.ID, executor, snapshot)
+}
+
+// Reject rejects a pending proposal
+func (m *Manager) Reject(id, user, comment string) (*Proposal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.expirePending()
+
+	proposal, exists := m.proposals[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrProposalNotFound, id)
+	}
+	if proposal.State != StatePending {
+		return nil, fmt.Errorf("%w: proposal %s is %s", ErrInvalidState, id, proposal.State)
+	}
+
+	proposal.State = StateRejected
+	proposal.Rejection = &Decision{User: user, Comment: comment, Timestamp: time.Now()}
+	m.save(proposal)
+	logrus.Infof("Proposal %s rejected by %s", id, user)
+
+	return copyProposal(proposal), nil
+}
+
+// execute runs the executor outside the lock and records the outcome.
+// A client giving up on the approving request must not abort a change halfway, so the request context is detached.
+func (m *Manager) execute(ctx context.Context, id string, executor Executor, snapshot *Proposal) (*Proposal, error) {
+	if executor == nil {
+		return m.recordOutcome(id, nil, fmt.Errorf("no executor registered for %s", snapshot.Type))
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), executionTimeout)
+	defer cancel()
+
+	result, err := executor(ctx, snapshot)
+	return m.recordOutcome(id, result, err)
+}
+
+func (m *Manager) recordOutcome(id string, result interface{}, err error) (*Proposal, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	proposal := m.proposals[id]
+	if err != nil {
+		proposal.State = StateFailed
+		proposal.Error = err.Error()
+		m.save(proposal)
+		logrus.Errorf("Failed to apply approved proposal %s: %v", id, err)
+		return copyProposal(proposal), err
+	}
+
+	now := time.Now()
+	proposal.State = StateApplied
+	proposal.AppliedAt = &now
+	proposal.Result = result
+	proposal.Error = ""
+	m.save(proposal)
+	logrus.Infof("Applied proposal %s (%s)", id, proposal.Type)
+
+	return copyProposal(proposal), nil
+}
+
+// expirePending moves overdue pending proposals to expired; callers must hold the lock
+func (m *Manager) expirePending() {
+	now := time.Now()
+	for _, proposal := range m.proposals {
+		if proposal.State == StatePending && now.After(proposal.ExpiresAt) {
+			proposal.State = StateExpired
+			m.save(proposal)
+		}
+	}
+}
+
+// increase is the limit name and new/current ratio of one change in a proposal; the factor is 0 when unknown
+type increase struct {
+	limitName string
+	factor    float64
+}
+
+// proposalIncreases returns the increase of every change a proposal carries
+func proposalIncreases(proposal *Proposal) []increase {
+	switch {
+	case proposal.LimitUpdate != nil:
+		return []increase{limitIncrease(proposal.LimitUpdate)}
+	case proposal.BulkLimitUpdate != nil:
+		increases := make([]increase, 0, len(proposal.BulkLimitUpdate.Changes))
+		for i := range proposal.BulkLimitUpdate.Changes {
+			increases = append(increases, limitIncrease(&proposal.BulkLimitUpdate.Changes[i]))
+		}
+		return increases
+	case proposal.AlloyScale != nil:
+		if proposal.AlloyScale.CurrentReplicas <= 0 {
+			return []increase{{}}
+		}
+		return []increase{{factor: float64(proposal.AlloyScale.Replicas) / float64(proposal.AlloyScale.CurrentReplicas)}}
+	}
+	return []increase{{}}
+}
+
+func limitIncrease(change *LimitUpdateChange) increase {
+	current, okCurrent := toFloat(change.CurrentValue)
+	proposed, okProposed := toFloat(change.NewValue)
+	if !okCurrent || !okProposed || current <= 0 {
+		return increase{limitName: change.LimitName}
+	}
+	return increase{limitName: change.LimitName, factor: proposed / current}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func copyProposal(proposal *Proposal) *Proposal {
+	copied := *proposal
+	copied.Approvals = append([]Decision{}, proposal.Approvals...)
+	if proposal.BulkLimitUpdate != nil {
+		bulk := *proposal.BulkLimitUpdate
+		bulk.Changes = append([]LimitUpdateChange{}, bulk.Changes...)
+		copied.BulkLimitUpdate = &bulk
+	}
+	return &copied
+}
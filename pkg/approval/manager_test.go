@@ -0,0 +1,251 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+	"github.com/akshaydubey29/mimirInsights/pkg/tuning"
+)
+
+func newTestManager(approvalConfig config.ApprovalConfig) *Manager {
+	if approvalConfig.ExpiryHours == 0 {
+		approvalConfig.ExpiryHours = 24
+	}
+	return &Manager{
+		config:    approvalConfig,
+		proposals: make(map[string]*Proposal),
+		executors: make(map[ChangeType]Executor),
+		store:     storage.NewMemoryStore(),
+	}
+}
+
+func limitProposal(limitName string, current, proposed interface{}) *Proposal {
+	return &Proposal{
+		Type: ChangeLimitUpdate,
+		LimitUpdate: &LimitUpdateChange{
+			TenantName:   "team-a",
+			LimitName:    limitName,
+			CurrentValue: current,
+			NewValue:     proposed,
+		},
+	}
+}
+
+func TestRequiredApprovals(t *testing.T) {
+	rules := []config.ApprovalRule{
+		{ChangeType: "limit_update", LimitName: "max_global_series_per_user", MinIncreaseFactor: 2.0, Approvals: 2},
+		{ChangeType: "alloy_scale", MinIncreaseFactor: 3.0, Approvals: 3},
+		{LimitName: "ingestion_rate", Approvals: 2},
+	}
+
+	tests := []struct {
+		name     string
+		defaults int
+		proposal *Proposal
+		want     int
+	}{
+		{
+			name:     "small increase uses the default",
+			defaults: 1,
+			proposal: limitProposal("max_global_series_per_user", int64(100000), int64(150000)),
+			want:     1,
+		},
+		{
+			name:     "large increase matches the rule",
+			defaults: 1,
+			proposal: limitProposal("max_global_series_per_user", int64(100000), int64(300000)),
+			want:     2,
+		},
+		{
+			name:     "exactly the factor does not match",
+			defaults: 1,
+			proposal: limitProposal("max_global_series_per_user", 100000.0, 200000.0),
+			want:     1,
+		},
+		{
+			name:     "unknown current value matches",
+			defaults: 1,
+			proposal: limitProposal("max_global_series_per_user", nil, int64(150000)),
+			want:     2,
+		},
+		{
+			name:     "rule without change type matches any type",
+			defaults: 0,
+			proposal: &Proposal{Type: ChangeLimitRollback, LimitUpdate: &LimitUpdateChange{LimitName: "ingestion_rate", CurrentValue: 10, NewValue: 5}},
+			want:     2,
+		},
+		{
+			name:     "other limit uses the default",
+			defaults: 0,
+			proposal: limitProposal("max_label_names_per_series", 30, 300),
+			want:     0,
+		},
+		{
+			name:     "bulk update takes the strictest matching change",
+			defaults: 1,
+			proposal: &Proposal{Type: ChangeBulkLimitUpdate, BulkLimitUpdate: &BulkLimitUpdateChange{Changes: []LimitUpdateChange{
+				{LimitName: "max_label_names_per_series", CurrentValue: 30, NewValue: 40},
+				{LimitName: "ingestion_rate", CurrentValue: 10000, NewValue: 12000},
+			}}},
+			want: 2,
+		},
+		{
+			name:     "limit_update rule covers bulk updates",
+			defaults: 1,
+			proposal: &Proposal{Type: ChangeBulkLimitUpdate, BulkLimitUpdate: &BulkLimitUpdateChange{Changes: []LimitUpdateChange{
+				{LimitName: "max_global_series_per_user", CurrentValue: 1000, NewValue: 9000},
+			}}},
+			want: 2,
+		},
+		{
+			name:     "limit_update rule covers rollbacks",
+			defaults: 1,
+			proposal: &Proposal{Type: ChangeLimitRollback, LimitUpdate: &LimitUpdateChange{LimitName: "max_global_series_per_user", CurrentValue: 1000, NewValue: 9000}},
+			want:     2,
+		},
+		{
+			name:     "limit_update rule does not cover scaling",
+			defaults: 1,
+			proposal: &Proposal{Type: ChangeAlloyScale, AlloyScale: &AlloyScaleChange{AlloyReplicaRequest: tuning.AlloyReplicaRequest{Replicas: 2}, CurrentReplicas: 2}},
+			want:     1,
+		},
+		{
+			name:     "large scale-up",
+			defaults: 1,
+			proposal: &Proposal{Type: ChangeAlloyScale, AlloyScale: &AlloyScaleChange{AlloyReplicaRequest: tuning.AlloyReplicaRequest{Replicas: 8}, CurrentReplicas: 2}},
+			want:     3,
+		},
+		{
+			name:     "small scale-up",
+			defaults: 1,
+			proposal: &Proposal{Type: ChangeAlloyScale, AlloyScale: &AlloyScaleChange{AlloyReplicaRequest: tuning.AlloyReplicaRequest{Replicas: 3}, CurrentReplicas: 2}},
+			want:     1,
+		},
+		{
+			name:     "negative default is clamped",
+			defaults: -1,
+			proposal: limitProposal("max_label_names_per_series", 30, 40),
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(config.ApprovalConfig{DefaultApprovals: tt.defaults, Rules: rules})
+			if got := manager.RequiredApprovals(tt.proposal); got != tt.want {
+				t.Errorf("RequiredApprovals() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApproveRules(t *testing.T) {
+	manager := newTestManager(config.ApprovalConfig{DefaultApprovals: 2})
+	manager.RegisterExecutor(ChangeLimitUpdate, func(ctx context.Context, proposal *Proposal) (interface{}, error) {
+		return "done", nil
+	})
+
+	proposal := limitProposal("ingestion_rate", 10, 20)
+	proposal.Requester = "alice"
+	submitted := manager.Submit(proposal)
+
+	if _, err := manager.Approve(context.Background(), submitted.ID, "alice", ""); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("self-approval error = %v, want ErrNotAllowed", err)
+	}
+	if p, err := manager.Approve(context.Background(), submitted.ID, "bob", ""); err != nil || p.State != StatePending {
+		t.Fatalf("first approval = %v, %v, want pending", p, err)
+	}
+	if _, err := manager.Approve(context.Background(), submitted.ID, "bob", ""); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("repeated approval error = %v, want ErrNotAllowed", err)
+	}
+	p, err := manager.Approve(context.Background(), submitted.ID, "carol", "")
+	if err != nil {
+		t.Fatalf("second approval error = %v", err)
+	}
+	if p.State != StateApplied || p.Result != "done" || p.Attempts != 1 {
+		t.Errorf("proposal = %s/%v/%d attempts, want applied/done/1", p.State, p.Result, p.Attempts)
+	}
+}
+
+func TestFailedExecutionCanBeRetried(t *testing.T) {
+	manager := newTestManager(config.ApprovalConfig{DefaultApprovals: 1})
+	fail := true
+	manager.RegisterExecutor(ChangeLimitUpdate, func(ctx context.Context, proposal *Proposal) (interface{}, error) {
+		if fail {
+			return nil, errors.New("conflict")
+		}
+		return "done", nil
+	})
+
+	proposal := limitProposal("ingestion_rate", 10, 20)
+	proposal.Requester = "alice"
+	submitted := manager.Submit(proposal)
+
+	p, err := manager.Approve(context.Background(), submitted.ID, "bob", "")
+	if err == nil {
+		t.Fatal("Approve() error = nil, want executor error")
+	}
+	if p.State != StateFailed || p.Error != "conflict" {
+		t.Fatalf("proposal = %s (%q), want failed (conflict)", p.State, p.Error)
+	}
+
+	if _, err := manager.Reject(submitted.ID, "carol", ""); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Reject() of failed proposal error = %v, want ErrInvalidState", err)
+	}
+
+	fail = false
+	p, err = manager.Retry(context.Background(), submitted.ID, "bob")
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if p.State != StateApplied || p.Error != "" || p.Attempts != 2 {
+		t.Errorf("proposal = %s (%q, %d attempts), want applied after 2 attempts", p.State, p.Error, p.Attempts)
+	}
+
+	if _, err := manager.Retry(context.Background(), submitted.ID, "bob"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Retry() of applied proposal error = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestExecutionOutlivesTheApprovingRequest(t *testing.T) {
+	manager := newTestManager(config.ApprovalConfig{DefaultApprovals: 1})
+	manager.RegisterExecutor(ChangeLimitUpdate, func(ctx context.Context, proposal *Proposal) (interface{}, error) {
+		return nil, ctx.Err()
+	})
+
+	proposal := limitProposal("ingestion_rate", 10, 20)
+	proposal.Requester = "alice"
+	submitted := manager.Submit(proposal)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // The approving client went away
+	p, err := manager.Approve(ctx, submitted.ID, "bob", "")
+	if err != nil || p.State != StateApplied {
+		t.Errorf("Approve() = %s, %v, want applied", p.State, err)
+	}
+}
+
+func TestLoadMarksInterruptedExecutionsFailed(t *testing.T) {
+	manager := newTestManager(config.ApprovalConfig{DefaultApprovals: 1})
+	interrupted := limitProposal("ingestion_rate", 10, 20)
+	interrupted.ID, interrupted.State = "prop_1", StateApproved
+	pending := limitProposal("ingestion_rate", 10, 30)
+	pending.ID, pending.State = "prop_2", StatePending
+	for _, proposal := range []*Proposal{interrupted, pending} {
+		if err := storage.PutJSON(manager.store, storage.BucketApprovalProposals, proposal.ID, proposal); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager.load()
+
+	if got := manager.proposals["prop_1"].State; got != StateFailed {
+		t.Errorf("interrupted proposal state = %s, want failed", got)
+	}
+	if got := manager.proposals["prop_2"].State; got != StatePending {
+		t.Errorf("pending proposal state = %s, want pending", got)
+	}
+}
@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// Entry represents a recorded mutating API call
+type Entry struct {
+	ID            string    `json:"id"`
+	Timestamp     time.Time `json:"timestamp"`
+	Actor         string    `json:"actor"`
+	SourceIP      string    `json:"source_ip"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	Action        string    `json:"action"`
+	Tenant        string    `json:"tenant,omitempty"`
+	PayloadDigest string    `json:"payload_digest,omitempty"`
+	StatusCode    int       `json:"status_code"`
+	Outcome       string    `json:"outcome"` // "success", "failure"
+	DurationMs    float64   `json:"duration_ms"`
+}
+
+// Filter selects audit entries; zero values match everything
+type Filter struct {
+	From   time.Time
+	To     time.Time
+	Actor  string
+	Action string
+	Tenant string
+	Limit  int
+}
+
+// pruneInterval is how often recording an entry also removes expired ones
+const pruneInterval = time.Hour
+
+// Logger records audit entries in persistent storage
+type Logger struct {
+	store     storage.Store
+	retention time.Duration // Zero keeps entries forever
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+}
+
+var entrySequence uint64
+
+// NewLogger creates an audit logger backed by the given storage that keeps entries for retention
+func NewLogger(store storage.Store, retention time.Duration) *Logger {
+	return &Logger{store: store, retention: retention}
+}
+
+// Record stores an audit entry
+func (l *Logger) Record(entry Entry) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	entry.ID = fmt.Sprintf("audit_%d_%d", entry.Timestamp.Unix(), atomic.AddUint64(&entrySequence, 1))
+
+	key := timeKey(entry.Timestamp) + "_" + entry.ID
+	if err := storage.PutJSON(l.store, storage.BucketAudit, key, entry); err != nil {
+		logrus.Errorf("Failed to persist audit entry: %v", err)
+	}
+
+	l.pruneIfDue(entry.Timestamp)
+}
+
+// Query returns the entries matching the filter, newest first.
+// Keys are in time order, so only the requested time range is read and reading stops at the limit.
+func (l *Logger) Query(filter Filter) []Entry {
+	result := []Entry{}
+	before := ""
+	if !filter.To.IsZero() {
+		before = timeKey(filter.To.Add(time.Nanosecond))
+	}
+
+	err := l.store.ForEachReverse(storage.BucketAudit, before, func(key string, value []byte) error {
+		var entry Entry
+		if err := storage.DecodeJSON(value, &entry); err != nil {
+			logrus.Warnf("Skipping malformed audit entry %s: %v", key, err)
+			return nil
+		}
+		if !filter.From.IsZero() && entry.Timestamp.Before(filter.From) {
+			return storage.ErrStopIteration
+		}
+		if filter.matches(entry) {
+			result = append(result, entry)
+		}
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			return storage.ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to query audit log: %v", err)
+	}
+	return result
+}
+
+// Prune removes entries older than the retention and returns how many were removed
+func (l *Logger) Prune(now time.Time) int {
+	if l.retention <= 0 {
+		return 0
+	}
+	cutoff := timeKey(now.Add(-l.retention))
+
+	var expired []string
+	err := l.store.ForEach(storage.BucketAudit, func(key string, value []byte) error {
+		if key >= cutoff {
+			return storage.ErrStopIteration
+		}
+		expired = append(expired, key)
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to scan audit log: %v", err)
+		return 0
+	}
+
+	for _, key := range expired {
+		if err := l.store.Delete(storage.BucketAudit, key); err != nil {
+			logrus.Errorf("Failed to prune audit entry %s: %v", key, err)
+		}
+	}
+	return len(expired)
+}
+
+// pruneIfDue prunes at most once per pruneInterval
+func (l *Logger) pruneIfDue(now time.Time) {
+	if l.retention <= 0 {
+		return
+	}
+	l.pruneMu.Lock()
+	if now.Sub(l.lastPrune) < pruneInterval {
+		l.pruneMu.Unlock()
+		return
+	}
+	l.lastPrune = now
+	l.pruneMu.Unlock()
+
+	if pruned := l.Prune(now); pruned > 0 {
+		logrus.Debugf("Pruned %d audit entries older than %v", pruned, l.retention)
+	}
+}
+
+// timeKey zero-pads the timestamp so keys sort chronologically
+func timeKey(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+func (f Filter) matches(entry Entry) bool {
+	if !f.From.IsZero() && entry.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && entry.Timestamp.After(f.To) {
+		return false
+	}
+	if f.Actor != "" && entry.Actor != f.Actor {
+		return false
+	}
+	if f.Action != "" && entry.Action != f.Action {
+		return false
+	}
+	if f.Tenant != "" && entry.Tenant != f.Tenant {
+		return false
+	}
+	return true
+}
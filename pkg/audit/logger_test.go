@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+)
+
+var testStart = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestLogger records one entry per hour, alternating actors and tenants
+func newTestLogger(t *testing.T, entries int) *Logger {
+	t.Helper()
+	logger := NewLogger(storage.NewMemoryStore(), 0)
+	for i := 0; i < entries; i++ {
+		actor, tenant := "alice", "team-a"
+		if i%2 == 1 {
+			actor, tenant = "bob", "team-b"
+		}
+		logger.Record(Entry{Timestamp: testStart.Add(time.Duration(i) * time.Hour), Actor: actor, Action: "limits.update", Tenant: tenant})
+	}
+	return logger
+}
+
+func TestQuery(t *testing.T) {
+	logger := newTestLogger(t, 10)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int // Hours after testStart, newest first
+	}{
+		{name: "everything", filter: Filter{}, want: []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}},
+		{name: "limit keeps the newest", filter: Filter{Limit: 3}, want: []int{9, 8, 7}},
+		{name: "actor", filter: Filter{Actor: "bob", Limit: 2}, want: []int{9, 7}},
+		{name: "tenant", filter: Filter{Tenant: "team-a"}, want: []int{8, 6, 4, 2, 0}},
+		{name: "action without matches", filter: Filter{Action: "approval.approve"}, want: []int{}},
+		{
+			name:   "time range is inclusive",
+			filter: Filter{From: testStart.Add(3 * time.Hour), To: testStart.Add(5 * time.Hour)},
+			want:   []int{5, 4, 3},
+		},
+		{name: "to only", filter: Filter{To: testStart.Add(time.Hour)}, want: []int{1, 0}},
+		{name: "from only with limit", filter: Filter{From: testStart.Add(8 * time.Hour), Limit: 5}, want: []int{9, 8}},
+		{name: "range before the log", filter: Filter{To: testStart.Add(-time.Hour)}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := logger.Query(tt.filter)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Query() returned %d entries, want %d", len(got), len(tt.want))
+			}
+			for i, hour := range tt.want {
+				if want := testStart.Add(time.Duration(hour) * time.Hour); !got[i].Timestamp.Equal(want) {
+					t.Errorf("entry %d at %v, want %v", i, got[i].Timestamp, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name       string
+		retention  time.Duration
+		wantPruned int
+		wantLeft   int
+	}{
+		{name: "expired entries", retention: 4 * time.Hour, wantPruned: 5, wantLeft: 5},
+		{name: "nothing expired", retention: 24 * time.Hour, wantPruned: 0, wantLeft: 10},
+		{name: "no retention keeps everything", retention: 0, wantPruned: 0, wantLeft: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := newTestLogger(t, 10)
+			logger.retention = tt.retention
+
+			// Nine hours after the first entry the last one is brand new
+			if pruned := logger.Prune(testStart.Add(9 * time.Hour)); pruned != tt.wantPruned {
+				t.Errorf("Prune() = %d, want %d", pruned, tt.wantPruned)
+			}
+			if left := len(logger.Query(Filter{})); left != tt.wantLeft {
+				t.Errorf("%d entries left, want %d", left, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestRecordPrunesExpiredEntries(t *testing.T) {
+	logger := NewLogger(storage.NewMemoryStore(), 24*time.Hour)
+	logger.Record(Entry{Timestamp: testStart, Action: "limits.update"})
+	logger.Record(Entry{Timestamp: testStart.Add(30 * time.Minute), Action: "limits.update"})
+
+	// The first record after the prune interval removes what expired
+	logger.Record(Entry{Timestamp: testStart.Add(25 * time.Hour), Action: "limits.update"})
+	if got := len(logger.Query(Filter{})); got != 1 {
+		t.Errorf("%d entries after a day, want only the newest", got)
+	}
+}
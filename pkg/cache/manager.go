@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/akshaydubey29/mimirInsights/pkg/discovery"
 	"github.com/akshaydubey29/mimirInsights/pkg/limits"
 	"github.com/akshaydubey29/mimirInsights/pkg/metrics"
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -21,6 +23,7 @@ type Manager struct {
 	// Cache storage
 	cache     *Cache
 	cacheLock sync.RWMutex
+	store     storage.Store
 
 	// Background collection
 	collectionInterval time.Duration
@@ -65,6 +68,9 @@ type Cache struct {
 	CollectionCount int64     `json:"collection_count"`
 }
 
+// cacheSnapshotKey is the storage key of the persisted cache
+const cacheSnapshotKey = "snapshot"
+
 // TenantMetricsData holds metrics for a specific tenant
 type TenantMetricsData struct {
 	IngestionRate    map[string]float64 `json:"ingestion_rate"`
@@ -86,8 +92,12 @@ func NewManager(discoveryEngine *discovery.Engine, metricsClient *metrics.Client
 		stopChan:           make(chan struct{}),
 		tenantCacheTTL:     5 * time.Minute,  // 5 minutes TTL for tenant discovery
 		mimirCacheTTL:      10 * time.Minute, // 10 minutes TTL for Mimir discovery
+		store:              storage.Get(),
 	}
 
+	// Serve the last persisted snapshot until the first collection completes
+	manager.restoreSnapshot()
+
 	// Initialize memory manager
 	manager.memoryManager = NewMemoryManager()
 
@@ -263,12 +273,41 @@ func (m *Manager) collectAllData(ctx context.Context) error {
 	m.cache.CollectionCount++
 	m.cacheLock.Unlock()
 
+	m.persistSnapshot()
+
 	logrus.Infof("✅ [CACHE] Data collection completed in %v. Found %d tenants, %d Mimir components",
 		time.Since(start), len(tenantNames), len(discoveryResult.MimirComponents))
 
 	return nil
 }
 
+// persistSnapshot saves the current cache so it can be restored after a restart
+func (m *Manager) persistSnapshot() {
+	m.cacheLock.RLock()
+	defer m.cacheLock.RUnlock()
+
+	if err := storage.PutJSON(m.store, storage.BucketCache, cacheSnapshotKey, m.cache); err != nil {
+		logrus.Warnf("⚠️ [CACHE] Failed to persist cache snapshot: %v", err)
+	}
+}
+
+// restoreSnapshot loads the last persisted cache, if any
+func (m *Manager) restoreSnapshot() {
+	snapshot := &Cache{}
+	if err := storage.GetJSON(m.store, storage.BucketCache, cacheSnapshotKey, snapshot); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			logrus.Warnf("⚠️ [CACHE] Failed to restore cache snapshot: %v", err)
+		}
+		return
+	}
+
+	m.cacheLock.Lock()
+	m.cache = snapshot
+	m.cacheLock.Unlock()
+
+	logrus.Infof("✅ [CACHE] Restored cache snapshot from %v", snapshot.LastUpdated)
+}
+
 // collectTenantMetrics collects metrics for a specific tenant
 func (m *Manager) collectTenantMetrics(ctx context.Context, tenantName string) (*TenantMetricsData, error) {
 	metricsData := &TenantMetricsData{
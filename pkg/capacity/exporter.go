@@ -8,6 +8,9 @@ import (
 	"html/template"
 	"strconv"
 	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+	"github.com/sirupsen/logrus"
 )
 
 // Exporter handles capacity report exports
@@ -328,7 +331,7 @@ type Scheduler struct {
 	planner      *Planner
 	exporter     *Exporter
 	emailService EmailService
-	exports      map[string]*ScheduledExport
+	store        storage.Store
 }
 
 // NewScheduler creates a new export scheduler
@@ -337,21 +340,38 @@ func NewScheduler(planner *Planner, exporter *Exporter, emailService EmailServic
 		planner:      planner,
 		exporter:     exporter,
 		emailService: emailService,
-		exports:      make(map[string]*ScheduledExport),
+		store:        storage.Get(),
 	}
 }
 
 // AddScheduledExport adds a new scheduled export
 func (s *Scheduler) AddScheduledExport(export *ScheduledExport) {
-	s.exports[export.ID] = export
+	if err := storage.PutJSON(s.store, storage.BucketCapacityExports, export.ID, export); err != nil {
+		logrus.Errorf("Failed to store scheduled export %s: %v", export.ID, err)
+	}
 }
 
 // RemoveScheduledExport removes a scheduled export
 func (s *Scheduler) RemoveScheduledExport(id string) {
-	delete(s.exports, id)
+	if err := s.store.Delete(storage.BucketCapacityExports, id); err != nil {
+		logrus.Errorf("Failed to remove scheduled export %s: %v", id, err)
+	}
 }
 
 // GetScheduledExports returns all scheduled exports
 func (s *Scheduler) GetScheduledExports() map[string]*ScheduledExport {
-	return s.exports
+	exports := make(map[string]*ScheduledExport)
+	err := s.store.ForEach(storage.BucketCapacityExports, func(key string, value []byte) error {
+		var export ScheduledExport
+		if err := storage.DecodeJSON(value, &export); err != nil {
+			logrus.Warnf("Skipping unreadable scheduled export %s: %v", key, err)
+			return nil
+		}
+		exports[key] = &export
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to list scheduled exports: %v", err)
+	}
+	return exports
 }
@@ -297,6 +297,23 @@ func (p *Planner) getCurrentTenantCapacity(ctx context.Context, tenantName strin
 		ErrorRate:     p.calculateAverageFromSeries(tenantMetrics.Metrics["rejected_samples"]),
 	}
 
+	// Clusters that don't scrape Mimir into itself have no self-monitoring series; ask Mimir's usage APIs instead
+	missingIngestion := len(tenantMetrics.Metrics["ingestion_rate"]) == 0
+	missingSeries := len(tenantMetrics.Metrics["active_series"]) == 0
+	if missingIngestion || missingSeries {
+		usage, err := p.metricsClient.GetTenantUsage(ctx, tenantName)
+		if err != nil {
+			logrus.Warnf("No usage metrics or usage APIs available for %s: %v", tenantName, err)
+		} else {
+			if value, ok := usage.Value("ingestion_rate"); ok && missingIngestion {
+				capacity.IngestionRate = value
+			}
+			if value, ok := usage.Value("active_series"); ok && missingSeries {
+				capacity.ActiveSeries = int64(value)
+			}
+		}
+	}
+
 	// Simulate CPU and storage usage (in real implementation, get from metrics)
 	capacity.CPUUsage = 45.0 + (capacity.IngestionRate / 1000.0 * 10.0)
 	capacity.StorageUsage = float64(capacity.ActiveSeries) * 0.001 // 1KB per series
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"strings"
 
@@ -10,12 +11,17 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server ServerConfig `mapstructure:"server"`
-	Mimir  MimirConfig  `mapstructure:"mimir"`
-	K8s    K8sConfig    `mapstructure:"k8s"`
-	Log    LogConfig    `mapstructure:"log"`
-	UI     UIConfig     `mapstructure:"ui"`
-	LLM    LLMConfig    `mapstructure:"llm"`
+	Server   ServerConfig   `mapstructure:"server"`
+	Mimir    MimirConfig    `mapstructure:"mimir"`
+	K8s      K8sConfig      `mapstructure:"k8s"`
+	Log      LogConfig      `mapstructure:"log"`
+	UI       UIConfig       `mapstructure:"ui"`
+	LLM      LLMConfig      `mapstructure:"llm"`
+	Approval ApprovalConfig `mapstructure:"approval"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+	Audit    AuditConfig    `mapstructure:"audit"`
+	Storage  StorageConfig  `mapstructure:"storage"`
+	Drift    DriftConfig    `mapstructure:"drift"`
 }
 
 // ServerConfig holds server-specific configuration
@@ -32,6 +38,12 @@ type MimirConfig struct {
 	OrgID     string          `mapstructure:"org_id"`
 	Discovery DiscoveryConfig `mapstructure:"discovery"`
 	API       APIConfig       `mapstructure:"api"`
+
+	IngesterURL      string   `mapstructure:"ingester_url"`      // Serves /ingester/tenants; empty uses api_url
+	AllowedTenants   []string `mapstructure:"allowed_tenants"`   // Org IDs queries may target besides org_id; "*" allows any
+	TenantFederation bool     `mapstructure:"tenant_federation"` // Mimir runs with -tenant-federation.enabled
+
+	ReplicationFactor int `mapstructure:"replication_factor"` // -ingester.ring.replication-factor; ingester series are counted once per replica
 }
 
 // DiscoveryConfig holds auto-discovery configuration
@@ -56,6 +68,7 @@ type APIConfig struct {
 	Port               int      `mapstructure:"port"`
 	Timeout            int      `mapstructure:"timeout"`
 	MetricsPaths       []string `mapstructure:"metrics_paths"`
+	MaxConcurrency     int      `mapstructure:"max_concurrency"` // Endpoints queried at once when collecting from several
 }
 
 // K8sConfig holds Kubernetes-specific configuration
@@ -87,6 +100,57 @@ type LLMConfig struct {
 	Endpoint  string `mapstructure:"endpoint"`
 	Model     string `mapstructure:"model"`
 	MaxTokens int    `mapstructure:"max_tokens"`
+
+	TimeoutSeconds int `mapstructure:"timeout_seconds"` // Per-request timeout
+	MaxRetries     int `mapstructure:"max_retries"`     // Retries on rate limiting (429) and server errors (5xx)
+}
+
+// ApprovalConfig holds the change approval workflow configuration
+type ApprovalConfig struct {
+	Enabled          bool           `mapstructure:"enabled"`
+	DefaultApprovals int            `mapstructure:"default_approvals"`
+	ExpiryHours      int            `mapstructure:"expiry_hours"`
+	Rules            []ApprovalRule `mapstructure:"rules"`
+}
+
+// ApprovalRule requires a number of approvers for matching changes
+type ApprovalRule struct {
+	ChangeType        string  `mapstructure:"change_type"` // "limit_update" (also covers rollbacks and bulk updates), "alloy_scale"
+	LimitName         string  `mapstructure:"limit_name"`
+	MinIncreaseFactor float64 `mapstructure:"min_increase_factor"`
+	Approvals         int     `mapstructure:"approvals"`
+}
+
+// AuthConfig holds how the user behind a request is identified
+type AuthConfig struct {
+	UserHeader     string   `mapstructure:"user_header"`     // Header the authenticating proxy puts the user in
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // IPs or CIDRs of the proxies whose user header is trusted
+}
+
+// AuditConfig holds audit log configuration
+type AuditConfig struct {
+	RetentionDays int `mapstructure:"retention_days"` // 0 keeps entries forever
+}
+
+// StorageConfig holds persistent storage configuration
+type StorageConfig struct {
+	Type string `mapstructure:"type"` // "bolt" or "memory"
+	Path string `mapstructure:"path"`
+}
+
+// DriftConfig holds drift detection configuration
+type DriftConfig struct {
+	WatchEnabled  bool           `mapstructure:"watch_enabled"`
+	ResyncMinutes int            `mapstructure:"resync_minutes"`
+	Namespaces    []string       `mapstructure:"namespaces"` // Empty watches the Mimir and discovered tenant namespaces
+	Git           DriftGitConfig `mapstructure:"git"`
+}
+
+// DriftGitConfig points drift comparison at rendered manifests in a local Git checkout
+type DriftGitConfig struct {
+	Path             string `mapstructure:"path"`              // Checkout on disk; empty disables Git comparison
+	ManifestsDir     string `mapstructure:"manifests_dir"`     // Directory within the checkout holding the manifests
+	DefaultNamespace string `mapstructure:"default_namespace"` // Namespace for manifests without one; defaults to the Mimir namespace
 }
 
 var (
@@ -145,8 +209,12 @@ func setDefaults() {
 	// Mimir defaults
 	viper.SetDefault("mimir.namespace", "mimir")
 	viper.SetDefault("mimir.api_url", "http://mimir-distributor:9090")
+	viper.SetDefault("mimir.ingester_url", "")
 	viper.SetDefault("mimir.timeout", 30)
 	viper.SetDefault("mimir.org_id", "default")
+	viper.SetDefault("mimir.allowed_tenants", []string{})
+	viper.SetDefault("mimir.tenant_federation", false)
+	viper.SetDefault("mimir.replication_factor", 3)
 
 	// Mimir Discovery defaults
 	viper.SetDefault("mimir.discovery.auto_detect", true)
@@ -171,6 +239,7 @@ func setDefaults() {
 	viper.SetDefault("mimir.api.distributor_service", "")
 	viper.SetDefault("mimir.api.port", 9090)
 	viper.SetDefault("mimir.api.timeout", 30)
+	viper.SetDefault("mimir.api.max_concurrency", 4)
 	viper.SetDefault("mimir.api.metrics_paths", []string{"/metrics", "/api/v1/query", "/prometheus/api/v1/query"})
 
 	// K8s defaults
@@ -191,6 +260,35 @@ func setDefaults() {
 	viper.SetDefault("llm.provider", "openai")
 	viper.SetDefault("llm.model", "gpt-4")
 	viper.SetDefault("llm.max_tokens", 1000)
+	viper.SetDefault("llm.timeout_seconds", 60)
+	viper.SetDefault("llm.max_retries", 3)
+
+	// Storage defaults
+	viper.SetDefault("storage.type", "bolt")
+	viper.SetDefault("storage.path", "data/mimir-insights.db")
+
+	// Drift defaults
+	viper.SetDefault("drift.watch_enabled", true)
+	viper.SetDefault("drift.resync_minutes", 30)
+	viper.SetDefault("drift.namespaces", []string{})
+	viper.SetDefault("drift.git.path", "")
+	viper.SetDefault("drift.git.manifests_dir", "")
+	viper.SetDefault("drift.git.default_namespace", "")
+
+	// Approval defaults
+	viper.SetDefault("approval.enabled", false)
+	viper.SetDefault("approval.default_approvals", 1)
+	viper.SetDefault("approval.expiry_hours", 24)
+	viper.SetDefault("approval.rules", []map[string]interface{}{
+		{"change_type": "limit_update", "limit_name": "max_global_series_per_user", "min_increase_factor": 2.0, "approvals": 2},
+	})
+
+	// Auth defaults
+	viper.SetDefault("auth.user_header", "X-Forwarded-User")
+	viper.SetDefault("auth.trusted_proxies", []string{})
+
+	// Audit defaults
+	viper.SetDefault("audit.retention_days", 90)
 }
 
 // validateConfig validates the configuration
@@ -220,6 +318,12 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("invalid log level: %s", config.Log.Level)
 	}
 
+	for _, proxy := range config.Auth.TrustedProxies {
+		if _, err := ParseTrustedProxy(proxy); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -235,3 +339,23 @@ func GetEnvWithDefault(key, defaultValue string) string {
 func IsDevelopment() bool {
 	return GetEnvWithDefault("ENV", "production") == "development"
 }
+
+// ParseTrustedProxy parses an entry of auth.trusted_proxies; a bare IP matches only that address
+func ParseTrustedProxy(proxy string) (*net.IPNet, error) {
+	if !strings.Contains(proxy, "/") {
+		ip := net.ParseIP(proxy)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %s", proxy)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxy: %s", proxy)
+	}
+	return network, nil
+}
@@ -1262,6 +1262,11 @@ func getDaemonSetStatus(daemonSet *appsv1.DaemonSet) string {
 	return "NotReady"
 }
 
+// IsMimirComponent reports whether a workload with the given metadata is a Mimir component
+func (e *Engine) IsMimirComponent(name string, labels map[string]string, annotations map[string]string) bool {
+	return e.isMimirComponentAdvanced(name, labels, annotations)
+}
+
 // isMimirComponentAdvanced checks if a Kubernetes resource (Deployment/StatefulSet) is a Mimir component
 func (e *Engine) isMimirComponentAdvanced(name string, labels map[string]string, annotations map[string]string) bool {
 	// Exclude our own components to avoid false positives
@@ -0,0 +1,429 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reserved baseline references that do not name a baseline set
+const (
+	BaselineRefCurrent = "current" // The live state of the cluster
+	BaselineRefActive  = "active"  // The baselines drift detection compares against
+	BaselineRefGit     = "git"     // The manifests of the configured Git checkout
+)
+
+var (
+	// ErrBaselineSetNotFound is returned when a named baseline or version does not exist
+	ErrBaselineSetNotFound = errors.New("baseline set not found")
+	// ErrInvalidBaselineRef is returned for malformed baseline names and references
+	ErrInvalidBaselineRef = errors.New("invalid baseline reference")
+)
+
+var baselineNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// BaselineSet is a named, versioned snapshot of the baselines of a set of namespaces
+type BaselineSet struct {
+	Name        string                     `json:"name"`
+	Version     int                        `json:"version"`
+	Description string                     `json:"description,omitempty"`
+	CreatedBy   string                     `json:"created_by,omitempty"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	Namespaces  []string                   `json:"namespaces"`
+	Baselines   map[string]*BaselineConfig `json:"baselines"`
+}
+
+// BaselineSetSummary describes a baseline set version without its baselines
+type BaselineSetSummary struct {
+	Name          string    `json:"name"`
+	Version       int       `json:"version"`
+	Description   string    `json:"description,omitempty"`
+	CreatedBy     string    `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	Namespaces    []string  `json:"namespaces"`
+	ResourceCount int       `json:"resource_count"`
+}
+
+// BaselineComparison is the drift between two configuration states
+type BaselineComparison struct {
+	From   string     `json:"from"`
+	To     string     `json:"to"`
+	Commit *GitCommit `json:"commit,omitempty"` // Set when one side is the Git checkout
+	DriftReport
+}
+
+// Summary returns the baseline set without its baselines
+func (s *BaselineSet) Summary() BaselineSetSummary {
+	return BaselineSetSummary{
+		Name:          s.Name,
+		Version:       s.Version,
+		Description:   s.Description,
+		CreatedBy:     s.CreatedBy,
+		CreatedAt:     s.CreatedAt,
+		Namespaces:    s.Namespaces,
+		ResourceCount: len(s.Baselines),
+	}
+}
+
+// SaveBaselineSet stores a baseline set as the next version of its name
+func (s *BaselineStore) SaveBaselineSet(set *BaselineSet) error {
+	s.setMutex.Lock()
+	defer s.setMutex.Unlock()
+
+	latest, err := s.latestBaselineSetVersion(set.Name)
+	if err != nil {
+		return err
+	}
+	set.Version = latest + 1
+
+	if err := storage.PutJSON(s.store, storage.BucketDriftBaselineSets, baselineSetKey(set.Name, set.Version), set); err != nil {
+		return fmt.Errorf("failed to store baseline set %s: %w", set.Name, err)
+	}
+	return nil
+}
+
+// GetBaselineSet returns a version of a baseline set; version 0 returns the latest
+func (s *BaselineStore) GetBaselineSet(name string, version int) (*BaselineSet, error) {
+	if version == 0 {
+		latest, err := s.latestBaselineSetVersion(name)
+		if err != nil {
+			return nil, err
+		}
+		version = latest
+	}
+	if version <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBaselineSetNotFound, name)
+	}
+
+	var set BaselineSet
+	if err := storage.GetJSON(s.store, storage.BucketDriftBaselineSets, baselineSetKey(name, version), &set); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s version %d", ErrBaselineSetNotFound, name, version)
+		}
+		return nil, fmt.Errorf("failed to load baseline set %s: %w", name, err)
+	}
+	return &set, nil
+}
+
+// ListBaselineSets returns every stored baseline set version, ordered by name and version
+func (s *BaselineStore) ListBaselineSets() ([]BaselineSetSummary, error) {
+	summaries := []BaselineSetSummary{}
+	err := s.store.ForEach(storage.BucketDriftBaselineSets, func(key string, value []byte) error {
+		var set BaselineSet
+		if err := storage.DecodeJSON(value, &set); err != nil {
+			logrus.Warnf("Skipping unreadable baseline set %s: %v", key, err)
+			return nil
+		}
+		summaries = append(summaries, set.Summary())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list baseline sets: %w", err)
+	}
+	return summaries, nil
+}
+
+// ActivateBaselineSet replaces the active baselines of the set's namespaces with the set.
+// Active baselines of those namespaces that are not in the set are removed, so resources
+// deleted before the snapshot are not reported as deleted. It returns how many were removed.
+func (s *BaselineStore) ActivateBaselineSet(set *BaselineSet) int {
+	covered := make(map[string]bool, len(set.Namespaces))
+	for _, namespace := range set.Namespaces {
+		covered[namespace] = true
+	}
+
+	removed := 0
+	for resourceKey, baseline := range s.GetAllBaselines() {
+		if _, inSet := set.Baselines[resourceKey]; !inSet && covered[baseline.Namespace] {
+			s.DeleteBaseline(resourceKey)
+			removed++
+		}
+	}
+	for resourceKey, baseline := range set.Baselines {
+		s.StoreBaseline(resourceKey, baseline)
+	}
+	return removed
+}
+
+// latestBaselineSetVersion returns the highest stored version of a name, or 0 when there is none
+func (s *BaselineStore) latestBaselineSetVersion(name string) (int, error) {
+	prefix := name + "/"
+	latest := 0
+	err := s.store.ForEach(storage.BucketDriftBaselineSets, func(key string, value []byte) error {
+		if strings.HasPrefix(key, prefix) {
+			if version, err := strconv.Atoi(strings.TrimPrefix(key, prefix)); err == nil && version > latest {
+				latest = version
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read baseline set %s: %w", name, err)
+	}
+	return latest, nil
+}
+
+// baselineSetKey zero-pads the version so versions sort numerically
+func baselineSetKey(name string, version int) string {
+	return fmt.Sprintf("%s/%06d", name, version)
+}
+
+// ParseBaselineRef splits a "<name>[@<version>]" reference; version 0 means the latest
+func ParseBaselineRef(ref string) (string, int, error) {
+	name, versionText, hasVersion := strings.Cut(strings.TrimSpace(ref), "@")
+	if name == BaselineRefCurrent || name == BaselineRefActive || name == BaselineRefGit {
+		if hasVersion {
+			return "", 0, fmt.Errorf("%w: %q cannot have a version", ErrInvalidBaselineRef, name)
+		}
+		return name, 0, nil
+	}
+	if !baselineNamePattern.MatchString(name) {
+		return "", 0, fmt.Errorf("%w: %q", ErrInvalidBaselineRef, ref)
+	}
+	if !hasVersion {
+		return name, 0, nil
+	}
+	version, err := strconv.Atoi(versionText)
+	if err != nil || version <= 0 {
+		return "", 0, fmt.Errorf("%w: version in %q must be a positive number", ErrInvalidBaselineRef, ref)
+	}
+	return name, version, nil
+}
+
+// CreateNamedBaseline snapshots the namespaces as a new version of a named baseline set.
+// With activate the snapshot also replaces the baselines drift detection compares against.
+func (d *Detector) CreateNamedBaseline(ctx context.Context, name, description, user string, namespaces []string, activate bool) (*BaselineSet, error) {
+	if name == BaselineRefCurrent || name == BaselineRefActive || name == BaselineRefGit || !baselineNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q is not a valid baseline name", ErrInvalidBaselineRef, name)
+	}
+
+	set := &BaselineSet{
+		Name:        name,
+		Description: description,
+		CreatedBy:   user,
+		CreatedAt:   time.Now(),
+		Namespaces:  namespaces,
+		Baselines:   d.captureBaselines(ctx, namespaces),
+	}
+	if err := d.baselineStore.SaveBaselineSet(set); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("📸 [DRIFT] Created baseline %s version %d with %d resources", set.Name, set.Version, len(set.Baselines))
+	if activate {
+		removed := d.baselineStore.ActivateBaselineSet(set)
+		logrus.Infof("📸 [DRIFT] Activated baseline %s version %d, removing %d baselines not in it", set.Name, set.Version, removed)
+	}
+	return set, nil
+}
+
+// ListBaselineSets returns every stored baseline set version
+func (d *Detector) ListBaselineSets() ([]BaselineSetSummary, error) {
+	return d.baselineStore.ListBaselineSets()
+}
+
+// GetBaselineSet returns a version of a named baseline set; version 0 returns the latest
+func (d *Detector) GetBaselineSet(name string, version int) (*BaselineSet, error) {
+	return d.baselineStore.GetBaselineSet(name, version)
+}
+
+// CompareBaselines reports the drift from one configuration state to another.
+// References are "<name>[@<version>]", "active", "git" or "current"; neither side is modified.
+func (d *Detector) CompareBaselines(ctx context.Context, fromRef, toRef string) (*BaselineComparison, error) {
+	fromName, fromVersion, err := ParseBaselineRef(fromRef)
+	if err != nil {
+		return nil, err
+	}
+	toName, toVersion, err := ParseBaselineRef(toRef)
+	if err != nil {
+		return nil, err
+	}
+	if fromName == BaselineRefCurrent && toName == BaselineRefCurrent {
+		return nil, fmt.Errorf("%w: at least one side must be a stored baseline", ErrInvalidBaselineRef)
+	}
+
+	comparison := &BaselineComparison{
+		DriftReport: DriftReport{
+			GeneratedAt:   time.Now(),
+			DriftStatuses: []DriftStatus{},
+		},
+	}
+
+	// The live state is captured for the namespaces covered by the stored side
+	var from, to map[string]*BaselineConfig
+	var namespaces []string
+	if fromName != BaselineRefCurrent {
+		resolved, err := d.resolveBaselines(fromName, fromVersion, comparison)
+		if err != nil {
+			return nil, err
+		}
+		from, namespaces, comparison.From = resolved.baselines, resolved.namespaces, resolved.label
+	}
+	if toName != BaselineRefCurrent {
+		resolved, err := d.resolveBaselines(toName, toVersion, comparison)
+		if err != nil {
+			return nil, err
+		}
+		to, comparison.To = resolved.baselines, resolved.label
+		namespaces = append(namespaces, resolved.namespaces...)
+	}
+	if fromName == BaselineRefCurrent {
+		from, comparison.From = d.captureBaselines(ctx, uniqueNamespaces(namespaces)), BaselineRefCurrent
+	}
+	if toName == BaselineRefCurrent {
+		to, comparison.To = d.captureBaselines(ctx, uniqueNamespaces(namespaces)), BaselineRefCurrent
+	}
+
+	if fromName == BaselineRefGit || toName == BaselineRefGit {
+		from, to = withoutDeployAnnotations(from), withoutDeployAnnotations(to)
+	}
+
+	comparison.DriftStatuses = d.compareBaselineMaps(from, to)
+	d.calculateSummary(&comparison.DriftReport)
+	return comparison, nil
+}
+
+// CompareWithGit reports the differences between the live cluster and the Git checkout
+func (d *Detector) CompareWithGit(ctx context.Context) (*BaselineComparison, error) {
+	return d.CompareBaselines(ctx, BaselineRefGit, BaselineRefCurrent)
+}
+
+// resolvedBaselines are the baselines behind a reference with the namespaces they cover
+type resolvedBaselines struct {
+	baselines  map[string]*BaselineConfig
+	namespaces []string
+	label      string
+}
+
+// resolveBaselines loads the baselines behind a parsed reference; Git records its commit on the comparison
+func (d *Detector) resolveBaselines(name string, version int, comparison *BaselineComparison) (*resolvedBaselines, error) {
+	switch name {
+	case BaselineRefActive:
+		baselines := d.baselineStore.GetAllBaselines()
+		var namespaces []string
+		for _, baseline := range baselines {
+			namespaces = append(namespaces, baseline.Namespace)
+		}
+		return &resolvedBaselines{baselines: baselines, namespaces: namespaces, label: BaselineRefActive}, nil
+	case BaselineRefGit:
+		if d.gitSource == nil {
+			return nil, ErrGitSourceNotConfigured
+		}
+		manifests, err := d.gitSource.load()
+		if err != nil {
+			return nil, err
+		}
+		comparison.Commit = manifests.Commit
+		return &resolvedBaselines{
+			baselines:  d.gitBaselines(manifests),
+			namespaces: manifests.namespaces(),
+			label:      fmt.Sprintf("%s@%s", BaselineRefGit, manifests.Commit.Hash),
+		}, nil
+	}
+
+	set, err := d.baselineStore.GetBaselineSet(name, version)
+	if err != nil {
+		return nil, err
+	}
+	return &resolvedBaselines{
+		baselines:  set.Baselines,
+		namespaces: set.Namespaces,
+		label:      fmt.Sprintf("%s@%d", set.Name, set.Version),
+	}, nil
+}
+
+// compareBaselineMaps reports every resource of either state, ordered by resource key
+func (d *Detector) compareBaselineMaps(from, to map[string]*BaselineConfig) []DriftStatus {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, exists := from[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	statuses := make([]DriftStatus, 0, len(keys))
+	for _, key := range keys {
+		fromBaseline, inFrom := from[key]
+		toBaseline, inTo := to[key]
+
+		if !inTo {
+			statuses = append(statuses, deletedDriftStatus(fromBaseline))
+			continue
+		}
+
+		status := DriftStatus{
+			Resource:    toBaseline.Resource,
+			Namespace:   toBaseline.Namespace,
+			Name:        toBaseline.Name,
+			LastChecked: time.Now(),
+			CurrentHash: toBaseline.Hash,
+			Changes:     []ConfigChange{},
+		}
+		switch {
+		case !inFrom:
+			status.Status = "new"
+			status.RiskLevel = "medium"
+		case fromBaseline.Hash == toBaseline.Hash:
+			status.Status = "no_drift"
+			status.RiskLevel = "low"
+			status.BaselineHash = fromBaseline.Hash
+		default:
+			status.Status = "drifted"
+			status.BaselineHash = fromBaseline.Hash
+			status.Changes = d.baselineChanges(fromBaseline, toBaseline)
+			status.RiskLevel = d.calculateRiskLevel(status.Changes)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// baselineChanges compares two baselines of the same resource
+func (d *Detector) baselineChanges(from, to *BaselineConfig) []ConfigChange {
+	if from.Resource == "ConfigMap" {
+		return d.analyzeConfigMapChanges(from, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   to.Namespace,
+				Name:        to.Name,
+				Labels:      to.Labels,
+				Annotations: to.Annotations,
+			},
+			Data: to.Data,
+		})
+	}
+	return d.analyzeWorkloadChanges(from, workloadSnapshot{
+		Resource:    to.Resource,
+		Namespace:   to.Namespace,
+		Name:        to.Name,
+		Data:        to.Data,
+		Labels:      to.Labels,
+		Annotations: to.Annotations,
+	})
+}
+
+func uniqueNamespaces(namespaces []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, namespace := range namespaces {
+		if namespace != "" && !seen[namespace] {
+			seen[namespace] = true
+			result = append(result, namespace)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
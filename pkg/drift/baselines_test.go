@@ -0,0 +1,397 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+	"github.com/akshaydubey29/mimirInsights/pkg/k8s"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testCommit = "3f786850e387550fdab836ed7e6dc881de23001b"
+
+// newTestK8sClient returns a client of a stub API server that lists configMaps and fails every other request
+func newTestK8sClient(t *testing.T, configMaps ...corev1.ConfigMap) *k8s.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace, inNamespace := strings.CutPrefix(r.URL.Path, "/api/v1/namespaces/")
+		namespace, isConfigMaps := strings.CutSuffix(namespace, "/configmaps")
+		if !inNamespace || !isConfigMaps || r.Method != http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		list := corev1.ConfigMapList{TypeMeta: metav1.TypeMeta{Kind: "ConfigMapList", APIVersion: "v1"}, Items: []corev1.ConfigMap{}}
+		for _, cm := range configMaps {
+			if cm.Namespace == namespace {
+				list.Items = append(list.Items, cm)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	}))
+	t.Cleanup(server.Close)
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	writeFile(t, kubeconfig, fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: stub
+  cluster:
+    server: %s
+contexts:
+- name: stub
+  context:
+    cluster: stub
+    user: stub
+current-context: stub
+users:
+- name: stub
+  user: {}
+`, server.URL))
+
+	t.Cleanup(viper.Reset)
+	viper.Set("k8s.in_cluster", false)
+	viper.Set("k8s.config_path", kubeconfig)
+	if err := config.Init(); err != nil {
+		t.Fatalf("config.Init() error = %v", err)
+	}
+	client, err := k8s.NewClient()
+	if err != nil {
+		t.Fatalf("k8s.NewClient() error = %v", err)
+	}
+	return client
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeGitCheckout creates a checkout of files on the main branch at testCommit
+func writeGitCheckout(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(dir, ".git", "refs", "heads", "main"), testCommit+"\n")
+	for path, content := range files {
+		writeFile(t, filepath.Join(dir, filepath.FromSlash(path)), content)
+	}
+	return dir
+}
+
+func configMap(namespace, name string, data map[string]string) corev1.ConfigMap {
+	return corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Data: data}
+}
+
+func configMapBaseline(namespace, name string, data map[string]string) *BaselineConfig {
+	return &BaselineConfig{
+		Resource:  "ConfigMap",
+		Namespace: namespace,
+		Name:      name,
+		Hash:      calculateDataHash(data, nil, nil),
+		Data:      data,
+	}
+}
+
+// statusSummary renders drift statuses as "<resource key>=<status>" in report order
+func statusSummary(statuses []DriftStatus) string {
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		parts = append(parts, ResourceKey(status.Resource, status.Namespace, status.Name)+"="+status.Status)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParseBaselineRef(t *testing.T) {
+	tests := []struct {
+		ref         string
+		wantName    string
+		wantVersion int
+		wantErr     bool
+	}{
+		{ref: "prod", wantName: "prod"},
+		{ref: " prod@3 ", wantName: "prod", wantVersion: 3},
+		{ref: "release-2.10_rc.1", wantName: "release-2.10_rc.1"},
+		{ref: "active", wantName: BaselineRefActive},
+		{ref: "git", wantName: BaselineRefGit},
+		{ref: "current", wantName: BaselineRefCurrent},
+		{ref: "active@1", wantErr: true},
+		{ref: "current@2", wantErr: true},
+		{ref: "prod@0", wantErr: true},
+		{ref: "prod@-1", wantErr: true},
+		{ref: "prod@latest", wantErr: true},
+		{ref: "", wantErr: true},
+		{ref: "../prod", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		name, version, err := ParseBaselineRef(tt.ref)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidBaselineRef) {
+				t.Errorf("ParseBaselineRef(%q) error = %v, want ErrInvalidBaselineRef", tt.ref, err)
+			}
+			continue
+		}
+		if err != nil || name != tt.wantName || version != tt.wantVersion {
+			t.Errorf("ParseBaselineRef(%q) = %q, %d, %v; want %q, %d", tt.ref, name, version, err, tt.wantName, tt.wantVersion)
+		}
+	}
+}
+
+func TestBaselineSetVersions(t *testing.T) {
+	store := newTestDetector().baselineStore
+
+	// More than nine versions check that versions are ordered numerically
+	for i := 1; i <= 11; i++ {
+		set := &BaselineSet{Name: "prod", Description: fmt.Sprintf("snapshot %d", i), Namespaces: []string{"mimir"}, Baselines: map[string]*BaselineConfig{}}
+		if err := store.SaveBaselineSet(set); err != nil {
+			t.Fatalf("SaveBaselineSet() error = %v", err)
+		}
+		if set.Version != i {
+			t.Fatalf("saved version %d, want %d", set.Version, i)
+		}
+	}
+	if err := store.SaveBaselineSet(&BaselineSet{Name: "staging", Baselines: map[string]*BaselineConfig{}}); err != nil {
+		t.Fatalf("SaveBaselineSet() error = %v", err)
+	}
+
+	tests := []struct {
+		version         int
+		wantVersion     int
+		wantDescription string
+		wantErr         error
+	}{
+		{version: 0, wantVersion: 11, wantDescription: "snapshot 11"},
+		{version: 2, wantVersion: 2, wantDescription: "snapshot 2"},
+		{version: 12, wantErr: ErrBaselineSetNotFound},
+	}
+	for _, tt := range tests {
+		set, err := store.GetBaselineSet("prod", tt.version)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetBaselineSet(prod, %d) error = %v, want %v", tt.version, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || set.Version != tt.wantVersion || set.Description != tt.wantDescription {
+			t.Errorf("GetBaselineSet(prod, %d) = %+v, %v; want version %d", tt.version, set, err, tt.wantVersion)
+		}
+	}
+	if _, err := store.GetBaselineSet("missing", 0); !errors.Is(err, ErrBaselineSetNotFound) {
+		t.Errorf("GetBaselineSet(missing) error = %v, want ErrBaselineSetNotFound", err)
+	}
+
+	summaries, err := store.ListBaselineSets()
+	if err != nil {
+		t.Fatalf("ListBaselineSets() error = %v", err)
+	}
+	var listed []string
+	for _, summary := range summaries {
+		listed = append(listed, fmt.Sprintf("%s@%d", summary.Name, summary.Version))
+	}
+	want := "prod@1 prod@2 prod@3 prod@4 prod@5 prod@6 prod@7 prod@8 prod@9 prod@10 prod@11 staging@1"
+	if got := strings.Join(listed, " "); got != want {
+		t.Errorf("ListBaselineSets() = %s, want %s", got, want)
+	}
+}
+
+func TestActivateBaselineSet(t *testing.T) {
+	store := newTestDetector().baselineStore
+	store.StoreBaseline("configmap/mimir/runtime-overrides", configMapBaseline("mimir", "runtime-overrides", map[string]string{"overrides.yaml": "a: 1"}))
+	store.StoreBaseline("configmap/mimir/removed-config", configMapBaseline("mimir", "removed-config", nil))
+	store.StoreBaseline("configmap/monitoring/alloy-config", configMapBaseline("monitoring", "alloy-config", nil))
+
+	set := &BaselineSet{
+		Name:       "prod",
+		Namespaces: []string{"mimir"},
+		Baselines: map[string]*BaselineConfig{
+			"configmap/mimir/runtime-overrides": configMapBaseline("mimir", "runtime-overrides", map[string]string{"overrides.yaml": "a: 2"}),
+			"configmap/mimir/mimir-config":      configMapBaseline("mimir", "mimir-config", nil),
+		},
+	}
+	if removed := store.ActivateBaselineSet(set); removed != 1 {
+		t.Errorf("ActivateBaselineSet() removed %d baselines, want 1", removed)
+	}
+
+	active := store.GetAllBaselines()
+	if len(active) != 3 || active["configmap/mimir/removed-config"] != nil {
+		t.Errorf("active baselines = %v, want the set and the other namespace's baseline", active)
+	}
+	if baseline := active["configmap/mimir/runtime-overrides"]; baseline == nil || baseline.Data["overrides.yaml"] != "a: 2" {
+		t.Errorf("runtime-overrides baseline = %+v, want the set's", baseline)
+	}
+	if active["configmap/monitoring/alloy-config"] == nil {
+		t.Errorf("baseline of a namespace outside the set was removed")
+	}
+}
+
+func TestCreateNamedBaseline(t *testing.T) {
+	tests := []struct {
+		name       string
+		activate   bool
+		wantActive string
+	}{
+		{
+			name:       "activated",
+			activate:   true,
+			wantActive: "configmap/mimir/mimir-config=new configmap/mimir/runtime-overrides=new configmap/monitoring/alloy-config=new",
+		},
+		{
+			name:       "not activated",
+			wantActive: "configmap/mimir/removed-config=new configmap/mimir/runtime-overrides=new configmap/monitoring/alloy-config=new",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := newTestDetector()
+			detector.k8sClient = newTestK8sClient(t,
+				configMap("mimir", "runtime-overrides", map[string]string{"overrides.yaml": "a: 2"}),
+				configMap("mimir", "mimir-config", map[string]string{"mimir.yaml": "target: all"}),
+			)
+			detector.baselineStore.StoreBaseline("configmap/mimir/runtime-overrides", configMapBaseline("mimir", "runtime-overrides", map[string]string{"overrides.yaml": "a: 1"}))
+			detector.baselineStore.StoreBaseline("configmap/mimir/removed-config", configMapBaseline("mimir", "removed-config", nil))
+			detector.baselineStore.StoreBaseline("configmap/monitoring/alloy-config", configMapBaseline("monitoring", "alloy-config", nil))
+
+			set, err := detector.CreateNamedBaseline(context.Background(), "prod", "before upgrade", "alice", []string{"mimir"}, tt.activate)
+			if err != nil {
+				t.Fatalf("CreateNamedBaseline() error = %v", err)
+			}
+			if set.Version != 1 || set.CreatedBy != "alice" || len(set.Baselines) != 2 {
+				t.Errorf("baseline set = %+v, want version 1 with both ConfigMaps", set.Summary())
+			}
+
+			// The active baselines are compared against an empty state to list them
+			statuses := detector.compareBaselineMaps(nil, detector.baselineStore.GetAllBaselines())
+			if got := statusSummary(statuses); got != tt.wantActive {
+				t.Errorf("active baselines = %s, want %s", got, tt.wantActive)
+			}
+			runtime := detector.baselineStore.GetBaseline("configmap/mimir/runtime-overrides")
+			if wantData := map[bool]string{true: "a: 2", false: "a: 1"}[tt.activate]; runtime.Data["overrides.yaml"] != wantData {
+				t.Errorf("active runtime-overrides = %v, want %s", runtime.Data, wantData)
+			}
+		})
+	}
+
+	for _, reserved := range []string{BaselineRefCurrent, BaselineRefActive, BaselineRefGit, "prod@2"} {
+		if _, err := newTestDetector().CreateNamedBaseline(context.Background(), reserved, "", "", nil, false); !errors.Is(err, ErrInvalidBaselineRef) {
+			t.Errorf("CreateNamedBaseline(%s) error = %v, want ErrInvalidBaselineRef", reserved, err)
+		}
+	}
+}
+
+func TestCompareBaselines(t *testing.T) {
+	detector := newTestDetector()
+	helmAnnotations := map[string]string{"meta.helm.sh/release-name": "mimir"}
+	live := configMap("mimir", "runtime-overrides", map[string]string{"overrides.yaml": "a: 2"})
+	live.Annotations = helmAnnotations
+	detector.k8sClient = newTestK8sClient(t, live, configMap("mimir", "mimir-config", map[string]string{"mimir.yaml": "target: all"}))
+
+	detector.SetGitSource(NewGitSource(writeGitCheckout(t, map[string]string{
+		"manifests/runtime-overrides.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: runtime-overrides\ndata:\n  overrides.yaml: \"a: 2\"\n",
+	}), "manifests", "mimir"))
+
+	for _, data := range []map[string]string{{"overrides.yaml": "a: 1"}, {"overrides.yaml": "a: 2"}} {
+		set := &BaselineSet{
+			Name:       "prod",
+			Namespaces: []string{"mimir"},
+			Baselines:  map[string]*BaselineConfig{"configmap/mimir/runtime-overrides": configMapBaseline("mimir", "runtime-overrides", data)},
+		}
+		if err := detector.baselineStore.SaveBaselineSet(set); err != nil {
+			t.Fatalf("SaveBaselineSet() error = %v", err)
+		}
+		if set.Version == 1 {
+			detector.baselineStore.ActivateBaselineSet(set)
+		}
+	}
+
+	tests := []struct {
+		from, to         string
+		wantFrom, wantTo string
+		wantStatuses     string
+		wantCommit       bool
+		wantErr          error
+	}{
+		{
+			from: "prod@1", to: "prod@2",
+			wantFrom: "prod@1", wantTo: "prod@2",
+			wantStatuses: "configmap/mimir/runtime-overrides=drifted",
+		},
+		{
+			from: "prod@1", to: "prod",
+			wantFrom: "prod@1", wantTo: "prod@2",
+			wantStatuses: "configmap/mimir/runtime-overrides=drifted",
+		},
+		{
+			from: "active", to: "prod@1",
+			wantFrom: "active", wantTo: "prod@1",
+			wantStatuses: "configmap/mimir/runtime-overrides=no_drift",
+		},
+		{
+			from: "prod@2", to: "current",
+			wantFrom: "prod@2", wantTo: "current",
+			wantStatuses: "configmap/mimir/mimir-config=new configmap/mimir/runtime-overrides=drifted",
+		},
+		{
+			from: "current", to: "active",
+			wantFrom: "current", wantTo: "active",
+			wantStatuses: "configmap/mimir/mimir-config=deleted configmap/mimir/runtime-overrides=drifted",
+		},
+		{
+			// Annotations added by Helm at install time are not drift from Git
+			from: "git", to: "current",
+			wantFrom: "git@" + testCommit, wantTo: "current",
+			wantStatuses: "configmap/mimir/mimir-config=new configmap/mimir/runtime-overrides=no_drift",
+			wantCommit:   true,
+		},
+		{from: "current", to: "current", wantErr: ErrInvalidBaselineRef},
+		{from: "prod@3", to: "current", wantErr: ErrBaselineSetNotFound},
+		{from: "missing", to: "active", wantErr: ErrBaselineSetNotFound},
+		{from: "active@1", to: "current", wantErr: ErrInvalidBaselineRef},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			comparison, err := detector.CompareBaselines(context.Background(), tt.from, tt.to)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("CompareBaselines() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompareBaselines() error = %v", err)
+			}
+
+			if comparison.From != tt.wantFrom || comparison.To != tt.wantTo {
+				t.Errorf("compared %s to %s, want %s to %s", comparison.From, comparison.To, tt.wantFrom, tt.wantTo)
+			}
+			if got := statusSummary(comparison.DriftStatuses); got != tt.wantStatuses {
+				t.Errorf("statuses = %s, want %s", got, tt.wantStatuses)
+			}
+			if (comparison.Commit != nil) != tt.wantCommit {
+				t.Errorf("commit = %+v, want one only for Git", comparison.Commit)
+			}
+		})
+	}
+
+	if _, err := newTestDetector().CompareWithGit(context.Background()); !errors.Is(err, ErrGitSourceNotConfigured) {
+		t.Errorf("CompareWithGit() without a checkout error = %v, want ErrGitSourceNotConfigured", err)
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/akshaydubey29/mimirInsights/pkg/discovery"
 	"github.com/akshaydubey29/mimirInsights/pkg/k8s"
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,8 +20,15 @@ import (
 
 // Detector handles configuration drift detection
 type Detector struct {
-	k8sClient     *k8s.Client
-	baselineStore *BaselineStore
+	k8sClient       *k8s.Client
+	discoveryEngine *discovery.Engine
+	baselineStore   *BaselineStore
+	historyStore    *HistoryStore
+	gitSource       *GitSource
+	managed         func(namespace, name string) bool // ConfigMaps written and versioned through another API
+
+	expected      map[string]map[string]string // ConfigMap data this service is writing, by resource key and data key
+	expectedMutex sync.Mutex
 }
 
 // DriftStatus represents the drift status of a configuration
@@ -32,12 +43,15 @@ type DriftStatus struct {
 	BaselineHash string                 `json:"baseline_hash"`
 	CurrentHash  string                 `json:"current_hash"`
 	Metadata     map[string]interface{} `json:"metadata"`
+
+	alreadyReported bool // The same drift was reported by an earlier check
 }
 
 // ConfigChange represents a specific configuration change
 type ConfigChange struct {
 	Type        string `json:"type"` // "added", "modified", "deleted"
 	Key         string `json:"key"`
+	Path        string `json:"path,omitempty"` // YAML path within the key for structured payloads
 	OldValue    string `json:"old_value"`
 	NewValue    string `json:"new_value"`
 	Impact      string `json:"impact"` // "low", "medium", "high", "critical"
@@ -55,6 +69,7 @@ type BaselineConfig struct {
 	Annotations  map[string]string `json:"annotations"`
 	CreatedAt    time.Time         `json:"created_at"`
 	LastModified time.Time         `json:"last_modified"`
+	ObservedHash string            `json:"observed_hash,omitempty"` // Drifted state last reported, "deleted" once gone
 }
 
 // DriftReport represents a comprehensive drift report
@@ -76,14 +91,89 @@ type DriftSummary struct {
 	CriticalRisk int `json:"critical_risk"`
 }
 
-// NewDetector creates a new drift detector
-func NewDetector(k8sClient *k8s.Client) *Detector {
+// NewDetector creates a new drift detector that monitors the Mimir workloads found by the discovery engine
+func NewDetector(discoveryEngine *discovery.Engine) *Detector {
 	return &Detector{
-		k8sClient:     k8sClient,
-		baselineStore: NewBaselineStore(),
+		k8sClient:       discoveryEngine.GetK8sClient(),
+		discoveryEngine: discoveryEngine,
+		baselineStore:   NewBaselineStore(),
+		historyStore:    NewHistoryStore(storage.Get()),
+		expected:        make(map[string]map[string]string),
 	}
 }
 
+// ExpectConfigMapData records a sanctioned write of a ConfigMap data key, e.g. a limit update through the API.
+// Once the ConfigMap is seen with that content the key is taken into the baseline instead of reported as drift.
+// The returned function withdraws the expectation when the write fails.
+func (d *Detector) ExpectConfigMapData(namespace, name, key, value string) func() {
+	resourceKey := ResourceKey("ConfigMap", namespace, name)
+
+	d.expectedMutex.Lock()
+	if d.expected[resourceKey] == nil {
+		d.expected[resourceKey] = make(map[string]string)
+	}
+	d.expected[resourceKey][key] = value
+	d.expectedMutex.Unlock()
+
+	return func() {
+		d.expectedMutex.Lock()
+		defer d.expectedMutex.Unlock()
+		if d.expected[resourceKey][key] == value {
+			delete(d.expected[resourceKey], key)
+			if len(d.expected[resourceKey]) == 0 {
+				delete(d.expected, resourceKey)
+			}
+		}
+	}
+}
+
+// acceptExpected moves the expected data keys the ConfigMap now holds into its baseline
+func (d *Detector) acceptExpected(resourceKey string, baseline *BaselineConfig, cm *corev1.ConfigMap) {
+	d.expectedMutex.Lock()
+	defer d.expectedMutex.Unlock()
+
+	expected := d.expected[resourceKey]
+	accepted := false
+	for key, value := range expected {
+		current, exists := cm.Data[key]
+		if !exists || current != value {
+			continue
+		}
+		if !accepted {
+			data := make(map[string]string, len(baseline.Data)+1)
+			for k, v := range baseline.Data {
+				data[k] = v
+			}
+			baseline.Data = data
+			accepted = true
+		}
+		baseline.Data[key] = value
+		delete(expected, key)
+	}
+	if len(expected) == 0 {
+		delete(d.expected, resourceKey)
+	}
+	if !accepted {
+		return
+	}
+
+	baseline.Hash = calculateDataHash(baseline.Data, baseline.Labels, baseline.Annotations)
+	baseline.LastModified = time.Now()
+	d.baselineStore.StoreBaseline(resourceKey, baseline)
+	logrus.Infof("🔍 [DRIFT] Accepted change to %s made through mimirInsights into the baseline", resourceKey)
+}
+
+// SetManagedConfigMaps registers the check for ConfigMaps another component writes and keeps the history of,
+// e.g. the runtime overrides; remediation leaves those to that component
+func (d *Detector) SetManagedConfigMaps(managed func(namespace, name string) bool) {
+	d.managed = managed
+}
+
+// SetGitSource sets the Git checkout holding the expected state
+func (d *Detector) SetGitSource(source *GitSource) {
+	d.gitSource = source
+}
+
 // DetectDrift performs drift detection across all monitored resources
 func (d *Detector) DetectDrift(ctx context.Context, namespaces []string) (*DriftReport, error) {
 	logrus.Info("Starting configuration drift detection")
@@ -102,12 +192,17 @@ func (d *Detector) DetectDrift(ctx context.Context, namespaces []string) (*Drift
 		}
 		report.DriftStatuses = append(report.DriftStatuses, configMapStatuses...)
 
-		// Future: Add other resource types (Secrets, Deployments, etc.)
+		// Detect Deployment, StatefulSet, DaemonSet, HPA and PDB drift
+		report.DriftStatuses = append(report.DriftStatuses, d.detectWorkloadDrift(ctx, namespace)...)
 	}
 
 	// Calculate summary statistics
 	d.calculateSummary(report)
 
+	// Keep a record of what changed so it can be reviewed later
+	recorded := d.historyStore.Record(report.DriftStatuses)
+	logrus.Debugf("Recorded %d drift events", recorded)
+
 	logrus.Infof("Drift detection completed. Found %d drifted resources out of %d total",
 		report.DriftedCount, report.TotalResources)
 
@@ -181,6 +276,7 @@ func (d *Detector) analyzeConfigMapDrift(cm *corev1.ConfigMap) DriftStatus {
 		return status
 	}
 
+	d.acceptExpected(resourceKey, baseline, cm)
 	status.BaselineHash = baseline.Hash
 
 	// Compare current state with baseline
@@ -191,19 +287,57 @@ func (d *Detector) analyzeConfigMapDrift(cm *corev1.ConfigMap) DriftStatus {
 		status.Status = "drifted"
 		status.Changes = d.analyzeConfigMapChanges(baseline, cm)
 		status.RiskLevel = d.calculateRiskLevel(status.Changes)
+	}
+	status.alreadyReported = d.observe(resourceKey, baseline, currentHash)
 
-		// Update baseline with current state
-		baseline.Hash = currentHash
-		baseline.Data = cm.Data
-		baseline.Labels = cm.Labels
-		baseline.Annotations = cm.Annotations
-		baseline.LastModified = time.Now()
-		d.baselineStore.StoreBaseline(resourceKey, baseline)
+	return status
+}
+
+// observe remembers the state reported for a baseline and returns whether it was already reported.
+// The baseline itself is kept as the desired state so drift can be remediated.
+func (d *Detector) observe(resourceKey string, baseline *BaselineConfig, currentHash string) bool {
+	observed := currentHash
+	if currentHash == baseline.Hash {
+		observed = ""
+	}
+	if baseline.ObservedHash == observed {
+		return observed != ""
 	}
 
+	baseline.ObservedHash = observed
+	d.baselineStore.StoreBaseline(resourceKey, baseline)
+	return false
+}
+
+// deletedStatus reports a baselined resource that no longer exists
+func (d *Detector) deletedStatus(resourceKey string, baseline *BaselineConfig) DriftStatus {
+	status := deletedDriftStatus(baseline)
+	status.alreadyReported = d.observe(resourceKey, baseline, "deleted")
 	return status
 }
 
+// deletedDriftStatus describes a baselined resource that is missing from the compared state
+func deletedDriftStatus(baseline *BaselineConfig) DriftStatus {
+	return DriftStatus{
+		Resource:     baseline.Resource,
+		Namespace:    baseline.Namespace,
+		Name:         baseline.Name,
+		Status:       "deleted",
+		LastChecked:  time.Now(),
+		RiskLevel:    "high",
+		BaselineHash: baseline.Hash,
+		CurrentHash:  "",
+		Changes: []ConfigChange{{
+			Type:        "deleted",
+			Key:         "resource",
+			OldValue:    "exists",
+			NewValue:    "deleted",
+			Impact:      "high",
+			Description: fmt.Sprintf("%s was deleted", baseline.Resource),
+		}},
+	}
+}
+
 // analyzeConfigMapChanges analyzes specific changes between baseline and current
 func (d *Detector) analyzeConfigMapChanges(baseline *BaselineConfig, current *corev1.ConfigMap) []ConfigChange {
 	var changes []ConfigChange
@@ -212,12 +346,17 @@ func (d *Detector) analyzeConfigMapChanges(baseline *BaselineConfig, current *co
 	for key, oldValue := range baseline.Data {
 		if newValue, exists := current.Data[key]; exists {
 			if oldValue != newValue {
+				// Report individual settings for YAML payloads instead of the whole key
+				if pathChanges, ok := d.analyzeYAMLChanges(key, oldValue, newValue); ok {
+					changes = append(changes, pathChanges...)
+					continue
+				}
 				changes = append(changes, ConfigChange{
 					Type:        "modified",
 					Key:         fmt.Sprintf("data.%s", key),
 					OldValue:    oldValue,
 					NewValue:    newValue,
-					Impact:      d.assessDataChangeImpact(key, oldValue, newValue),
+					Impact:      d.assessDataChangeImpact(key, "", oldValue, newValue),
 					Description: fmt.Sprintf("Configuration value changed for key '%s'", key),
 				})
 			}
@@ -227,7 +366,7 @@ func (d *Detector) analyzeConfigMapChanges(baseline *BaselineConfig, current *co
 				Key:         fmt.Sprintf("data.%s", key),
 				OldValue:    oldValue,
 				NewValue:    "",
-				Impact:      d.assessDataChangeImpact(key, oldValue, ""),
+				Impact:      d.assessDataChangeImpact(key, "", oldValue, ""),
 				Description: fmt.Sprintf("Configuration key '%s' was deleted", key),
 			})
 		}
@@ -241,7 +380,7 @@ func (d *Detector) analyzeConfigMapChanges(baseline *BaselineConfig, current *co
 				Key:         fmt.Sprintf("data.%s", key),
 				OldValue:    "",
 				NewValue:    newValue,
-				Impact:      d.assessDataChangeImpact(key, "", newValue),
+				Impact:      d.assessDataChangeImpact(key, "", "", newValue),
 				Description: fmt.Sprintf("New configuration key '%s' was added", key),
 			})
 		}
@@ -310,8 +449,29 @@ func (d *Detector) analyzeMapChanges(mapType string, baseline, current map[strin
 	return changes
 }
 
-// assessDataChangeImpact assesses the impact of a data change
-func (d *Detector) assessDataChangeImpact(key, oldValue, newValue string) string {
+// assessDataChangeImpact assesses the impact of a data change, per YAML path when one is given
+func (d *Detector) assessDataChangeImpact(key, path, oldValue, newValue string) string {
+	if path != "" {
+		setting := lastPathSegment(path)
+		for _, critical := range criticalPathSettings {
+			if strings.HasSuffix(setting, critical) {
+				return "critical"
+			}
+		}
+		if d.isLimitRelatedChange(setting, oldValue, newValue) {
+			switch {
+			case newValue == "":
+				// A removed override falls back to the default, which may be higher or lower
+				return "high"
+			case isLimitReduction(oldValue, newValue):
+				// Lowering a limit can start rejecting traffic immediately
+				return "critical"
+			}
+			return "high"
+		}
+		return "medium"
+	}
+
 	// Critical configuration keys
 	criticalKeys := []string{
 		"runtime-config.yaml", "overrides.yaml", "limits.yaml",
@@ -346,22 +506,17 @@ func (d *Detector) assessDataChangeImpact(key, oldValue, newValue string) string
 	return "medium"
 }
 
-// isLimitRelatedChange checks if the change is related to limits
+// isLimitRelatedChange checks if the changed key or setting is related to limits
 func (d *Detector) isLimitRelatedChange(key, oldValue, newValue string) bool {
 	limitIndicators := []string{
 		"limit", "max_", "rate", "timeout", "threshold",
-		"ingestion", "series", "memory", "cpu",
+		"ingestion", "series", "memory", "cpu", "burst",
 	}
 
-	keyLower := key
+	keyLower := strings.ToLower(key)
 	for _, indicator := range limitIndicators {
-		if len(keyLower) > 0 && len(indicator) > 0 {
-			// Simple contains check
-			for i := 0; i <= len(keyLower)-len(indicator); i++ {
-				if keyLower[i:i+len(indicator)] == indicator {
-					return true
-				}
-			}
+		if strings.Contains(keyLower, indicator) {
+			return true
 		}
 	}
 
@@ -404,11 +559,16 @@ func (d *Detector) calculateRiskLevel(changes []ConfigChange) string {
 
 // calculateConfigMapHash calculates a hash of the ConfigMap data
 func (d *Detector) calculateConfigMapHash(cm *corev1.ConfigMap) string {
+	return calculateDataHash(cm.Data, cm.Labels, cm.Annotations)
+}
+
+// calculateDataHash calculates a hash of baseline data, labels and annotations
+func calculateDataHash(data, labels, annotations map[string]string) string {
 	hasher := sha256.New()
 
 	// Sort keys for consistent hashing
 	var keys []string
-	for k := range cm.Data {
+	for k := range data {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
@@ -416,28 +576,28 @@ func (d *Detector) calculateConfigMapHash(cm *corev1.ConfigMap) string {
 	// Hash data
 	for _, k := range keys {
 		hasher.Write([]byte(k))
-		hasher.Write([]byte(cm.Data[k]))
+		hasher.Write([]byte(data[k]))
 	}
 
 	// Include labels and annotations in hash
 	var labelKeys []string
-	for k := range cm.Labels {
+	for k := range labels {
 		labelKeys = append(labelKeys, k)
 	}
 	sort.Strings(labelKeys)
 	for _, k := range labelKeys {
 		hasher.Write([]byte("label:" + k))
-		hasher.Write([]byte(cm.Labels[k]))
+		hasher.Write([]byte(labels[k]))
 	}
 
 	var annotationKeys []string
-	for k := range cm.Annotations {
+	for k := range annotations {
 		annotationKeys = append(annotationKeys, k)
 	}
 	sort.Strings(annotationKeys)
 	for _, k := range annotationKeys {
 		hasher.Write([]byte("annotation:" + k))
-		hasher.Write([]byte(cm.Annotations[k]))
+		hasher.Write([]byte(annotations[k]))
 	}
 
 	return hex.EncodeToString(hasher.Sum(nil))
@@ -490,24 +650,7 @@ func (d *Detector) checkForDeletedConfigMaps(namespace string, currentCMs []core
 	// Check for deleted ConfigMaps
 	for key, baseline := range baselines {
 		if baseline.Resource == "ConfigMap" && !currentCMMap[key] {
-			statuses = append(statuses, DriftStatus{
-				Resource:     "ConfigMap",
-				Namespace:    baseline.Namespace,
-				Name:         baseline.Name,
-				Status:       "deleted",
-				LastChecked:  time.Now(),
-				RiskLevel:    "high",
-				BaselineHash: baseline.Hash,
-				CurrentHash:  "",
-				Changes: []ConfigChange{{
-					Type:        "deleted",
-					Key:         "resource",
-					OldValue:    "exists",
-					NewValue:    "deleted",
-					Impact:      "high",
-					Description: "ConfigMap was deleted",
-				}},
-			})
+			statuses = append(statuses, d.deletedStatus(key, baseline))
 		}
 	}
 
@@ -541,16 +684,36 @@ func (d *Detector) calculateSummary(report *DriftReport) {
 	}
 }
 
-// GetDriftHistory returns historical drift information
+// GetDriftHistory returns the drift recorded for a resource over the last days, newest first
 func (d *Detector) GetDriftHistory(resourceKey string, days int) ([]DriftStatus, error) {
-	// TODO: Implement drift history storage and retrieval
-	return []DriftStatus{}, nil
+	filter := HistoryFilter{Resource: resourceKey}
+	if days > 0 {
+		filter.Since = time.Now().AddDate(0, 0, -days)
+	}
+	return d.historyStore.Query(filter)
+}
+
+// QueryDriftHistory returns the recorded drift matching the filter, newest first
+func (d *Detector) QueryDriftHistory(filter HistoryFilter) ([]DriftStatus, error) {
+	return d.historyStore.Query(filter)
 }
 
 // CreateBaseline creates a baseline for the current state
 func (d *Detector) CreateBaseline(ctx context.Context, namespaces []string) error {
 	logrus.Info("Creating configuration baselines")
 
+	for resourceKey, baseline := range d.captureBaselines(ctx, namespaces) {
+		d.baselineStore.StoreBaseline(resourceKey, baseline)
+	}
+
+	logrus.Info("Configuration baselines created successfully")
+	return nil
+}
+
+// captureBaselines snapshots the monitored ConfigMaps and workloads of the namespaces, keyed by resource key
+func (d *Detector) captureBaselines(ctx context.Context, namespaces []string) map[string]*BaselineConfig {
+	baselines := make(map[string]*BaselineConfig)
+
 	for _, namespace := range namespaces {
 		configMaps, err := d.k8sClient.GetConfigMaps(ctx, namespace, metav1.ListOptions{})
 		if err != nil {
@@ -561,7 +724,7 @@ func (d *Detector) CreateBaseline(ctx context.Context, namespaces []string) erro
 		for _, cm := range configMaps.Items {
 			if d.shouldMonitorConfigMap(&cm) {
 				resourceKey := fmt.Sprintf("configmap/%s/%s", cm.Namespace, cm.Name)
-				baseline := &BaselineConfig{
+				baselines[resourceKey] = &BaselineConfig{
 					Resource:     "ConfigMap",
 					Namespace:    cm.Namespace,
 					Name:         cm.Name,
@@ -572,11 +735,16 @@ func (d *Detector) CreateBaseline(ctx context.Context, namespaces []string) erro
 					CreatedAt:    time.Now(),
 					LastModified: time.Now(),
 				}
-				d.baselineStore.StoreBaseline(resourceKey, baseline)
 			}
 		}
+
+		snapshots, _ := d.collectWorkloads(ctx, namespace)
+		for _, snapshot := range snapshots {
+			resourceKey := ResourceKey(snapshot.Resource, snapshot.Namespace, snapshot.Name)
+			hash := calculateDataHash(snapshot.Data, snapshot.Labels, snapshot.Annotations)
+			baselines[resourceKey] = snapshot.baseline(hash)
+		}
 	}
 
-	logrus.Info("Configuration baselines created successfully")
-	return nil
+	return baselines
 }
@@ -0,0 +1,182 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/monitoring"
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestDetector() *Detector {
+	store := storage.NewMemoryStore()
+	return &Detector{
+		baselineStore: NewBaselineStoreWith(store),
+		historyStore:  NewHistoryStore(store),
+		expected:      make(map[string]map[string]string),
+	}
+}
+
+func overridesConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "runtime-overrides", Namespace: "mimir"},
+		Data:       data,
+	}
+}
+
+func TestExpectedConfigMapChangeIsNotDrift(t *testing.T) {
+	tests := []struct {
+		name       string
+		expect     map[string]string
+		withdraw   bool
+		current    map[string]string
+		wantStatus string
+	}{
+		{
+			name:       "sanctioned write",
+			expect:     map[string]string{"overrides.yaml": "overrides:\n  a:\n    ingestion_rate: 2\n"},
+			current:    map[string]string{"overrides.yaml": "overrides:\n  a:\n    ingestion_rate: 2\n", "notes": "x"},
+			wantStatus: "no_drift",
+		},
+		{
+			name:       "other content is still drift",
+			expect:     map[string]string{"overrides.yaml": "overrides:\n  a:\n    ingestion_rate: 2\n"},
+			current:    map[string]string{"overrides.yaml": "overrides:\n  a:\n    ingestion_rate: 3\n", "notes": "x"},
+			wantStatus: "drifted",
+		},
+		{
+			name:       "other keys are still drift",
+			expect:     map[string]string{"overrides.yaml": "overrides:\n  a:\n    ingestion_rate: 2\n"},
+			current:    map[string]string{"overrides.yaml": "overrides:\n  a:\n    ingestion_rate: 2\n", "notes": "changed"},
+			wantStatus: "drifted",
+		},
+		{
+			name:       "withdrawn after a failed write",
+			expect:     map[string]string{"overrides.yaml": "overrides:\n  a:\n    ingestion_rate: 2\n"},
+			withdraw:   true,
+			current:    map[string]string{"overrides.yaml": "overrides:\n  a:\n    ingestion_rate: 2\n", "notes": "x"},
+			wantStatus: "drifted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := newTestDetector()
+			baseline := map[string]string{"overrides.yaml": "overrides:\n  a:\n    ingestion_rate: 1\n", "notes": "x"}
+			if status := detector.analyzeConfigMapDrift(overridesConfigMap(baseline)); status.Status != "new" {
+				t.Fatalf("first analysis = %s, want new", status.Status)
+			}
+
+			for key, value := range tt.expect {
+				withdraw := detector.ExpectConfigMapData("mimir", "runtime-overrides", key, value)
+				if tt.withdraw {
+					withdraw()
+				}
+			}
+
+			status := detector.analyzeConfigMapDrift(overridesConfigMap(tt.current))
+			if status.Status != tt.wantStatus {
+				t.Errorf("analysis = %s (%v), want %s", status.Status, status.Changes, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestExpectationIsConsumedOnce(t *testing.T) {
+	detector := newTestDetector()
+	detector.analyzeConfigMapDrift(overridesConfigMap(map[string]string{"overrides.yaml": "v1"}))
+
+	detector.ExpectConfigMapData("mimir", "runtime-overrides", "overrides.yaml", "v2")
+	if status := detector.analyzeConfigMapDrift(overridesConfigMap(map[string]string{"overrides.yaml": "v2"})); status.Status != "no_drift" {
+		t.Fatalf("sanctioned change = %s, want no_drift", status.Status)
+	}
+	if len(detector.expected) != 0 {
+		t.Errorf("expectations left after being accepted: %v", detector.expected)
+	}
+
+	// Someone reverting to the old content by hand is drift from the new baseline
+	if status := detector.analyzeConfigMapDrift(overridesConfigMap(map[string]string{"overrides.yaml": "v1"})); status.Status != "drifted" {
+		t.Errorf("manual revert = %s, want drifted", status.Status)
+	}
+}
+
+func TestWatcherDoesNotStartAfterCancel(t *testing.T) {
+	watcher := NewWatcher(newTestDetector(), nil, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := watcher.Start(ctx, []string{"mimir"}); err == nil {
+		t.Fatal("Start() after cancel error = nil, want error")
+	}
+	if watcher.isRunning || len(watcher.factories) != 0 {
+		t.Errorf("watcher started informers after its context was cancelled")
+	}
+}
+
+func TestRemediationRefusesManagedConfigMaps(t *testing.T) {
+	detector := newTestDetector()
+	detector.SetManagedConfigMaps(func(namespace, name string) bool {
+		return namespace == "mimir" && name == "runtime-overrides"
+	})
+
+	_, err := detector.RemediateResource(context.Background(), RemediationRequest{ResourceKey: "configmap/mimir/runtime-overrides"})
+	if !errors.Is(err, ErrRemediationUnsupported) {
+		t.Errorf("RemediateResource(runtime overrides) error = %v, want %v", err, ErrRemediationUnsupported)
+	}
+
+	// Other ConfigMaps get as far as the baseline lookup
+	_, err = detector.RemediateResource(context.Background(), RemediationRequest{ResourceKey: "configmap/mimir/mimir-config"})
+	if !errors.Is(err, ErrBaselineNotFound) {
+		t.Errorf("RemediateResource(other ConfigMap) error = %v, want %v", err, ErrBaselineNotFound)
+	}
+}
+
+func TestWatcherResolvesDriftAlerts(t *testing.T) {
+	alertManager := monitoring.NewAlertManager(monitoring.HealthConfig{})
+	watcher := NewWatcher(newTestDetector(), alertManager, time.Minute)
+	resourceKey := ResourceKey("ConfigMap", "alerts", "runtime-overrides")
+	firing := func() bool {
+		for _, alert := range alertManager.GetActiveAlerts() {
+			if alert.Name == driftAlertName(resourceKey) {
+				return true
+			}
+		}
+		return false
+	}
+	status := func(state, risk string, alreadyReported bool) func() DriftStatus {
+		return func() DriftStatus {
+			return DriftStatus{Resource: "ConfigMap", Namespace: "alerts", Name: "runtime-overrides",
+				Status: state, RiskLevel: risk, alreadyReported: alreadyReported}
+		}
+	}
+
+	tests := []struct {
+		name       string
+		event      func()
+		wantFiring bool
+	}{
+		{name: "high risk drift fires", event: func() { watcher.handle(status("drifted", "high", false)) }, wantFiring: true},
+		{name: "drift reported before keeps firing", event: func() { watcher.handle(status("drifted", "high", true)) }, wantFiring: true},
+		{name: "back in sync resolves", event: func() { watcher.handle(status("no_drift", "low", true)) }},
+		{name: "critical drift fires again", event: func() { watcher.handle(status("drifted", "critical", false)) }, wantFiring: true},
+		{name: "low risk drift resolves", event: func() { watcher.handle(status("drifted", "low", false)) }},
+		{name: "drift fires before remediation", event: func() { watcher.handle(status("drifted", "high", false)) }, wantFiring: true},
+		{name: "remediation resolves", event: func() { watcher.ResolveAlert(resourceKey) }},
+		{name: "drift fires before deletion", event: func() { watcher.handle(status("drifted", "high", false)) }, wantFiring: true},
+		{name: "deletion without a baseline resolves", event: func() {
+			watcher.handleDeletion("ConfigMap", metav1.ObjectMeta{Namespace: "alerts", Name: "runtime-overrides"})
+		}},
+	}
+
+	// The steps build on each other, so they run in order without subtests
+	for _, tt := range tests {
+		tt.event()
+		if got := firing(); got != tt.wantFiring {
+			t.Errorf("%s: alert firing = %v, want %v", tt.name, got, tt.wantFiring)
+		}
+	}
+}
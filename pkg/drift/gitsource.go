@@ -0,0 +1,363 @@
+package drift
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ErrGitSourceNotConfigured is returned when drift is compared against Git without a checkout
+var ErrGitSourceNotConfigured = errors.New("git source not configured")
+
+// deployAnnotations are added by Helm and kubectl when applying and never appear in Git
+var deployAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"meta.helm.sh/release-name",
+	"meta.helm.sh/release-namespace",
+}
+
+// GitSource reads the expected state from rendered manifests in a local Git checkout
+type GitSource struct {
+	repoPath         string
+	manifestsDir     string
+	defaultNamespace string
+}
+
+// GitCommit identifies the commit a comparison was made against
+type GitCommit struct {
+	Hash string `json:"hash"`
+	Ref  string `json:"ref,omitempty"` // Branch checked out, empty when HEAD is detached
+	Path string `json:"path"`
+}
+
+// gitManifests holds the manifests of a checkout, grouped by namespace
+type gitManifests struct {
+	Commit     *GitCommit
+	ConfigMaps []corev1.ConfigMap
+	Workloads  map[string]*workloadObjects
+	Files      int
+	Skipped    int
+}
+
+// NewGitSource creates a Git source reading manifests below manifestsDir of the checkout at repoPath.
+// Manifests without a namespace are assigned defaultNamespace.
+func NewGitSource(repoPath, manifestsDir, defaultNamespace string) *GitSource {
+	return &GitSource{
+		repoPath:         repoPath,
+		manifestsDir:     manifestsDir,
+		defaultNamespace: defaultNamespace,
+	}
+}
+
+// Commit returns the commit checked out in the repository
+func (g *GitSource) Commit() (*GitCommit, error) {
+	gitDir, err := resolveGitDir(g.repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD of %s: %w", g.repoPath, err)
+	}
+	commit := &GitCommit{Path: g.repoPath}
+
+	ref, symbolic := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !symbolic {
+		commit.Hash = ref
+		return commit, nil
+	}
+	commit.Ref = strings.TrimPrefix(ref, "refs/heads/")
+	if commit.Hash, err = resolveGitRef(gitDir, ref); err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// load reads every Kubernetes manifest of the checkout
+func (g *GitSource) load() (*gitManifests, error) {
+	commit, err := g.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := &gitManifests{
+		Commit:    commit,
+		Workloads: make(map[string]*workloadObjects),
+	}
+	root := filepath.Join(g.repoPath, g.manifestsDir)
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		manifests.Files++
+		if err := manifests.addFile(path, g.defaultNamespace); err != nil {
+			// Helm templates and other non-manifest YAML are expected in a chart repository
+			logrus.Debugf("Skipping %s: %v", path, err)
+			manifests.Skipped++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests from %s: %w", root, err)
+	}
+	return manifests, nil
+}
+
+// addFile decodes every document of a YAML or JSON file, unpacking List kinds
+func (m *gitManifests) addFile(path, defaultNamespace string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bufio.NewReader(file), 4096)
+	for {
+		var document json.RawMessage
+		if err := decoder.Decode(&document); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err := m.addDocument(document, defaultNamespace); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *gitManifests) addDocument(document json.RawMessage, defaultNamespace string) error {
+	if len(document) == 0 || string(document) == "null" {
+		return nil
+	}
+
+	var header struct {
+		Kind     string            `json:"kind"`
+		Items    []json.RawMessage `json:"items"`
+		Metadata struct {
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(document, &header); err != nil {
+		// Scalars and sequences are not manifests
+		return nil
+	}
+	if strings.HasSuffix(header.Kind, "List") {
+		for _, item := range header.Items {
+			if err := m.addDocument(item, defaultNamespace); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	namespace := header.Metadata.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	objects := m.Workloads[namespace]
+	if objects == nil {
+		objects = &workloadObjects{}
+	}
+
+	switch header.Kind {
+	case "ConfigMap":
+		var cm corev1.ConfigMap
+		if err := json.Unmarshal(document, &cm); err != nil {
+			return err
+		}
+		cm.Namespace = namespace
+		m.ConfigMaps = append(m.ConfigMaps, cm)
+		return nil
+	case ResourceDeployment:
+		var deployment appsv1.Deployment
+		if err := json.Unmarshal(document, &deployment); err != nil {
+			return err
+		}
+		deployment.Namespace = namespace
+		// The API server defaults the strategy, so an unset strategy in Git is not drift
+		if deployment.Spec.Strategy.Type == "" {
+			deployment.Spec.Strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+		}
+		objects.Deployments = append(objects.Deployments, deployment)
+	case ResourceStatefulSet:
+		var statefulSet appsv1.StatefulSet
+		if err := json.Unmarshal(document, &statefulSet); err != nil {
+			return err
+		}
+		statefulSet.Namespace = namespace
+		objects.StatefulSets = append(objects.StatefulSets, statefulSet)
+	case ResourceDaemonSet:
+		var daemonSet appsv1.DaemonSet
+		if err := json.Unmarshal(document, &daemonSet); err != nil {
+			return err
+		}
+		daemonSet.Namespace = namespace
+		objects.DaemonSets = append(objects.DaemonSets, daemonSet)
+	case ResourceHorizontalPodAutoscaler:
+		var hpa autoscalingv2.HorizontalPodAutoscaler
+		if err := json.Unmarshal(document, &hpa); err != nil {
+			return err
+		}
+		hpa.Namespace = namespace
+		objects.HPAs = append(objects.HPAs, hpa)
+	case ResourcePodDisruptionBudget:
+		var pdb policyv1.PodDisruptionBudget
+		if err := json.Unmarshal(document, &pdb); err != nil {
+			return err
+		}
+		pdb.Namespace = namespace
+		objects.PDBs = append(objects.PDBs, pdb)
+	default:
+		return nil
+	}
+
+	m.Workloads[namespace] = objects
+	return nil
+}
+
+// namespaces returns the namespaces the manifests deploy to
+func (m *gitManifests) namespaces() []string {
+	var namespaces []string
+	for _, cm := range m.ConfigMaps {
+		namespaces = append(namespaces, cm.Namespace)
+	}
+	for namespace := range m.Workloads {
+		namespaces = append(namespaces, namespace)
+	}
+	return uniqueNamespaces(namespaces)
+}
+
+// resolveGitDir returns the Git directory of a checkout, following "gitdir:" files of worktrees and submodules
+func resolveGitDir(repoPath string) (string, error) {
+	gitPath := filepath.Join(repoPath, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return "", fmt.Errorf("%s is not a Git checkout: %w", repoPath, err)
+	}
+	if info.IsDir() {
+		return gitPath, nil
+	}
+
+	content, err := os.ReadFile(gitPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", gitPath, err)
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("%s is not a Git checkout: unexpected .git file", repoPath)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(repoPath, gitDir)
+	}
+	return gitDir, nil
+}
+
+// resolveGitRef returns the commit a ref points to from loose or packed refs
+func resolveGitRef(gitDir, ref string) (string, error) {
+	dirs := []string{gitDir}
+	// Worktrees keep branch refs in the main repository
+	if commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(commonDir))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		dirs = append(dirs, common)
+	}
+
+	for _, dir := range dirs {
+		if hash, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(hash)), nil
+		}
+		packed, err := os.ReadFile(filepath.Join(dir, "packed-refs"))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(packed), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[1] == ref {
+				return fields[0], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to resolve %s in %s", ref, gitDir)
+}
+
+// gitBaselines converts the monitored manifests into baselines, keyed by resource key
+func (d *Detector) gitBaselines(manifests *gitManifests) map[string]*BaselineConfig {
+	baselines := make(map[string]*BaselineConfig)
+
+	for i := range manifests.ConfigMaps {
+		cm := &manifests.ConfigMaps[i]
+		if d.shouldMonitorConfigMap(cm) {
+			baselines[ResourceKey("ConfigMap", cm.Namespace, cm.Name)] = &BaselineConfig{
+				Resource:    "ConfigMap",
+				Namespace:   cm.Namespace,
+				Name:        cm.Name,
+				Hash:        d.calculateConfigMapHash(cm),
+				Data:        cm.Data,
+				Labels:      cm.Labels,
+				Annotations: cm.Annotations,
+			}
+		}
+	}
+
+	namespaces := make([]string, 0, len(manifests.Workloads))
+	for namespace := range manifests.Workloads {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		for _, snapshot := range d.workloadSnapshots(*manifests.Workloads[namespace]) {
+			hash := calculateDataHash(snapshot.Data, snapshot.Labels, snapshot.Annotations)
+			baselines[ResourceKey(snapshot.Resource, snapshot.Namespace, snapshot.Name)] = snapshot.baseline(hash)
+		}
+	}
+
+	return baselines
+}
+
+// withoutDeployAnnotations drops the annotations added at apply time so they are not reported as drift from Git
+func withoutDeployAnnotations(baselines map[string]*BaselineConfig) map[string]*BaselineConfig {
+	result := make(map[string]*BaselineConfig, len(baselines))
+	for key, baseline := range baselines {
+		normalized := *baseline
+		normalized.Annotations = make(map[string]string)
+		for name, value := range baseline.Annotations {
+			normalized.Annotations[name] = value
+		}
+		for _, name := range deployAnnotations {
+			delete(normalized.Annotations, name)
+		}
+		normalized.Hash = calculateDataHash(normalized.Data, normalized.Labels, normalized.Annotations)
+		result[key] = &normalized
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package drift
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGitSourceCommit(t *testing.T) {
+	const otherCommit = "89e6c98d92887913cadf06b2adb97f26cde4849b"
+	tests := []struct {
+		name    string
+		files   map[string]string
+		repo    string // Checkout within the fixture, defaults to its root
+		want    GitCommit
+		wantErr bool
+	}{
+		{
+			name: "loose branch ref",
+			want: GitCommit{Hash: testCommit, Ref: "main"},
+		},
+		{
+			name: "packed branch ref",
+			files: map[string]string{
+				".git/HEAD": "ref: refs/heads/release\n",
+				".git/packed-refs": "# pack-refs with: peeled fully-peeled sorted\n" +
+					otherCommit + " refs/heads/main\n" +
+					testCommit + " refs/heads/release\n" +
+					otherCommit + " refs/tags/v1.0.0\n" +
+					"^" + testCommit + "\n",
+			},
+			want: GitCommit{Hash: testCommit, Ref: "release"},
+		},
+		{
+			name:  "detached HEAD",
+			files: map[string]string{".git/HEAD": otherCommit + "\n"},
+			want:  GitCommit{Hash: otherCommit},
+		},
+		{
+			name: "worktree with branch refs in the main repository",
+			files: map[string]string{
+				"worktree/.git":                     "gitdir: ../.git/worktrees/worktree\n",
+				".git/worktrees/worktree/HEAD":      "ref: refs/heads/main\n",
+				".git/worktrees/worktree/commondir": "../..\n",
+			},
+			repo: "worktree",
+			want: GitCommit{Hash: testCommit, Ref: "main"},
+		},
+		{
+			name:    "unknown branch",
+			files:   map[string]string{".git/HEAD": "ref: refs/heads/missing\n"},
+			wantErr: true,
+		},
+		{
+			name:    "not a checkout",
+			repo:    "manifests",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeGitCheckout(t, tt.files)
+			repo := filepath.Join(dir, tt.repo)
+
+			commit, err := NewGitSource(repo, "", "mimir").Commit()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Commit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			tt.want.Path = repo
+			if *commit != tt.want {
+				t.Errorf("Commit() = %+v, want %+v", *commit, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitSourceLoad(t *testing.T) {
+	dir := writeGitCheckout(t, map[string]string{
+		"deploy/mimir.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: runtime-overrides
+data:
+  overrides.yaml: "a: 1"
+---
+# A document holding only a comment
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: mimir-distributor
+  namespace: mimir-prod
+spec:
+  replicas: 2
+`,
+		"deploy/list.json": `{"apiVersion": "v1", "kind": "List", "items": [
+			{"apiVersion": "apps/v1", "kind": "StatefulSet", "metadata": {"name": "mimir-ingester"}},
+			{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "mimir-ingester"}}
+		]}`,
+		"deploy/values.yaml": "replicas: 3\n",
+		// Helm templates are not valid YAML until rendered
+		"deploy/templates/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}-config\n",
+		"deploy/README.md":                "kind: ConfigMap\n",
+		"deploy/.git/config.yml":          "kind: ConfigMap\nmetadata:\n  name: vendored\n",
+		"outside/mimir-config.yaml":       "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: mimir-config\n",
+	})
+
+	manifests, err := NewGitSource(dir, "deploy", "mimir").load()
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	if manifests.Commit.Hash != testCommit {
+		t.Errorf("commit = %+v, want %s", manifests.Commit, testCommit)
+	}
+	if manifests.Files != 4 || manifests.Skipped != 1 {
+		t.Errorf("read %d files, skipped %d; want 4 manifest files with the Helm template skipped", manifests.Files, manifests.Skipped)
+	}
+	if len(manifests.ConfigMaps) != 1 || manifests.ConfigMaps[0].Name != "runtime-overrides" || manifests.ConfigMaps[0].Namespace != "mimir" {
+		t.Errorf("ConfigMaps = %+v, want runtime-overrides in the default namespace", manifests.ConfigMaps)
+	}
+	if got, want := manifests.namespaces(), []string{"mimir", "mimir-prod"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("namespaces() = %v, want %v", got, want)
+	}
+
+	var workloads []string
+	for namespace, objects := range manifests.Workloads {
+		for _, deployment := range objects.Deployments {
+			workloads = append(workloads, namespace+"/"+deployment.Name+" "+string(deployment.Spec.Strategy.Type))
+		}
+		for _, statefulSet := range objects.StatefulSets {
+			workloads = append(workloads, namespace+"/"+statefulSet.Name)
+		}
+	}
+	sort.Strings(workloads)
+	// The API server's default strategy is filled in so it is not reported as drift
+	if want := []string{"mimir-prod/mimir-distributor RollingUpdate", "mimir/mimir-ingester"}; !reflect.DeepEqual(workloads, want) {
+		t.Errorf("workloads = %v, want %v", workloads, want)
+	}
+
+	if _, err := NewGitSource(dir, "missing", "mimir").load(); err == nil {
+		t.Errorf("load() of a missing manifests directory succeeded")
+	}
+}
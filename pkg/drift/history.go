@@ -0,0 +1,154 @@
+package drift
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// historyRetention is how long drift events are kept
+	historyRetention = 90 * 24 * time.Hour
+	// historyPruneInterval is how often recording drift also removes expired events
+	historyPruneInterval = time.Hour
+)
+
+// HistoryFilter selects drift events; zero values match everything
+type HistoryFilter struct {
+	Namespace string
+	Resource  string // Resource key ("configmap/<namespace>/<name>") or resource name
+	RiskLevel string
+	Since     time.Time
+}
+
+// HistoryStore keeps a time-indexed history of detected drift
+type HistoryStore struct {
+	store     storage.Store
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+}
+
+var eventSequence uint64
+
+// NewHistoryStore creates a new drift history store backed by the given storage
+func NewHistoryStore(store storage.Store) *HistoryStore {
+	return &HistoryStore{store: store}
+}
+
+// Record appends drift statuses to the history; statuses without new drift are skipped
+func (h *HistoryStore) Record(statuses []DriftStatus) int {
+	recorded := 0
+	for _, status := range statuses {
+		if status.Status == "no_drift" || status.alreadyReported {
+			continue
+		}
+
+		key := fmt.Sprintf("%s_%d", historyKey(status.LastChecked), atomic.AddUint64(&eventSequence, 1))
+		if err := storage.PutJSON(h.store, storage.BucketDriftHistory, key, status); err != nil {
+			logrus.Errorf("Failed to record drift event for %s: %v", ResourceKey(status.Resource, status.Namespace, status.Name), err)
+			continue
+		}
+		recorded++
+	}
+
+	if recorded > 0 {
+		h.pruneIfDue(time.Now())
+	}
+	return recorded
+}
+
+// Query returns the drift events matching the filter, newest first
+func (h *HistoryStore) Query(filter HistoryFilter) ([]DriftStatus, error) {
+	result := []DriftStatus{}
+	// Keys are in chronological order, so reading backwards can stop at the first event before Since
+	err := h.store.ForEachReverse(storage.BucketDriftHistory, "", func(key string, value []byte) error {
+		var status DriftStatus
+		if err := storage.DecodeJSON(value, &status); err != nil {
+			logrus.Warnf("Skipping unreadable drift event %s: %v", key, err)
+			return nil
+		}
+		if !filter.Since.IsZero() && status.LastChecked.Before(filter.Since) {
+			return storage.ErrStopIteration
+		}
+		if filter.matches(status) {
+			result = append(result, status)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drift history: %w", err)
+	}
+	return result, nil
+}
+
+// Prune removes drift events older than the retention and returns how many were removed
+func (h *HistoryStore) Prune(now time.Time) int {
+	cutoff := historyKey(now.Add(-historyRetention))
+
+	var expired []string
+	err := h.store.ForEach(storage.BucketDriftHistory, func(key string, value []byte) error {
+		if key >= cutoff {
+			return storage.ErrStopIteration
+		}
+		expired = append(expired, key)
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to scan drift history: %v", err)
+		return 0
+	}
+
+	for _, key := range expired {
+		if err := h.store.Delete(storage.BucketDriftHistory, key); err != nil {
+			logrus.Errorf("Failed to prune drift event %s: %v", key, err)
+		}
+	}
+	return len(expired)
+}
+
+// pruneIfDue prunes at most once per historyPruneInterval
+func (h *HistoryStore) pruneIfDue(now time.Time) {
+	h.pruneMu.Lock()
+	if now.Sub(h.lastPrune) < historyPruneInterval {
+		h.pruneMu.Unlock()
+		return
+	}
+	h.lastPrune = now
+	h.pruneMu.Unlock()
+
+	if pruned := h.Prune(now); pruned > 0 {
+		logrus.Debugf("Pruned %d drift events older than %v", pruned, historyRetention)
+	}
+}
+
+// historyKey zero-pads the timestamp so keys sort chronologically
+func historyKey(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+func (f HistoryFilter) matches(status DriftStatus) bool {
+	if !f.Since.IsZero() && status.LastChecked.Before(f.Since) {
+		return false
+	}
+	if f.Namespace != "" && status.Namespace != f.Namespace {
+		return false
+	}
+	if f.Resource != "" && f.Resource != status.Name &&
+		!strings.EqualFold(f.Resource, ResourceKey(status.Resource, status.Namespace, status.Name)) {
+		return false
+	}
+	if f.RiskLevel != "" && status.RiskLevel != f.RiskLevel {
+		return false
+	}
+	return true
+}
+
+// ResourceKey returns the key identifying a resource in baselines and history
+func ResourceKey(resource, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", strings.ToLower(resource), namespace, name)
+}
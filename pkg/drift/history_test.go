@@ -0,0 +1,77 @@
+package drift
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+)
+
+func driftEvent(name, risk string, checked time.Time) DriftStatus {
+	return DriftStatus{Resource: "ConfigMap", Namespace: "mimir", Name: name, Status: "drifted", RiskLevel: risk, LastChecked: checked}
+}
+
+func eventNames(statuses []DriftStatus) string {
+	names := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		names = append(names, status.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestHistoryQuery(t *testing.T) {
+	now := time.Now()
+	history := NewHistoryStore(storage.NewMemoryStore())
+	history.Record([]DriftStatus{
+		driftEvent("b", "low", now.Add(-2*time.Hour)),
+		driftEvent("a", "high", now.Add(-3*time.Hour)),
+		driftEvent("c", "high", now.Add(-time.Hour)),
+		{Resource: "ConfigMap", Namespace: "mimir", Name: "in-sync", Status: "no_drift", LastChecked: now},
+	})
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   string
+	}{
+		{name: "everything newest first", want: "c,b,a"},
+		{name: "since", filter: HistoryFilter{Since: now.Add(-150 * time.Minute)}, want: "c,b"},
+		{name: "since and risk", filter: HistoryFilter{Since: now.Add(-150 * time.Minute), RiskLevel: "high"}, want: "c"},
+		{name: "resource key", filter: HistoryFilter{Resource: "configmap/mimir/a"}, want: "a"},
+		{name: "since after every event", filter: HistoryFilter{Since: now.Add(-time.Minute)}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := history.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if names := eventNames(got); names != tt.want {
+				t.Errorf("Query() = %s, want %s", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryPrune(t *testing.T) {
+	now := time.Now()
+	history := NewHistoryStore(storage.NewMemoryStore())
+	// Recording the first events prunes once, leaving nothing to prune until the interval passes
+	history.Record([]DriftStatus{driftEvent("recent", "low", now.Add(-time.Hour))})
+	history.Record([]DriftStatus{
+		driftEvent("expired", "low", now.Add(-historyRetention-time.Hour)),
+		driftEvent("kept", "low", now.Add(-historyRetention+time.Hour)),
+	})
+
+	if got, _ := history.Query(HistoryFilter{}); eventNames(got) != "recent,kept,expired" {
+		t.Fatalf("history before pruning = %s, want the expired event kept until the prune interval passes", eventNames(got))
+	}
+	if pruned := history.Prune(now); pruned != 1 {
+		t.Errorf("Prune() = %d, want 1", pruned)
+	}
+	if got, _ := history.Query(HistoryFilter{}); eventNames(got) != "recent,kept" {
+		t.Errorf("history after pruning = %s, want recent,kept", eventNames(got))
+	}
+}
@@ -0,0 +1,150 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// ErrBaselineNotFound is returned when a resource has no stored baseline
+	ErrBaselineNotFound = errors.New("baseline not found")
+	// ErrResourceNotFound is returned when the drifted resource no longer exists
+	ErrResourceNotFound = errors.New("resource not found")
+	// ErrRemediationConflict is returned when the resource changed since it was inspected
+	ErrRemediationConflict = errors.New("resource changed concurrently")
+	// ErrRemediationUnsupported is returned for resource kinds that cannot be remediated
+	ErrRemediationUnsupported = errors.New("remediation not supported")
+	// ErrInvalidResourceKey is returned for resource keys not of the form <kind>/<namespace>/<name>
+	ErrInvalidResourceKey = errors.New("invalid resource key")
+)
+
+// RemediationRequest describes a request to revert a resource to its baseline
+type RemediationRequest struct {
+	ResourceKey     string // e.g. "configmap/mimir/runtime-overrides"
+	ResourceVersion string // Expected current resourceVersion; empty skips the check
+	DryRun          bool
+	Reason          string
+	User            string
+}
+
+// RemediationResult describes a reverted (or, in dry-run mode, revertible) resource
+type RemediationResult struct {
+	ResourceKey             string         `json:"resource_key"`
+	Resource                string         `json:"resource"`
+	Namespace               string         `json:"namespace"`
+	Name                    string         `json:"name"`
+	DryRun                  bool           `json:"dry_run"`
+	InSync                  bool           `json:"in_sync"`
+	Changes                 []ConfigChange `json:"changes"`
+	RiskLevel               string         `json:"risk_level"`
+	BaselineHash            string         `json:"baseline_hash"`
+	PreviousResourceVersion string         `json:"previous_resource_version"`
+	ResourceVersion         string         `json:"resource_version,omitempty"`
+	RemediatedAt            *time.Time     `json:"remediated_at,omitempty"`
+}
+
+// RemediateResource restores a resource's data, labels and annotations from its stored baseline
+func (d *Detector) RemediateResource(ctx context.Context, request RemediationRequest) (*RemediationResult, error) {
+	parts := strings.SplitN(request.ResourceKey, "/", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("%w %q, expected <kind>/<namespace>/<name>", ErrInvalidResourceKey, request.ResourceKey)
+	}
+	kind, namespace, name := strings.ToLower(parts[0]), parts[1], parts[2]
+	if kind != "configmap" {
+		return nil, fmt.Errorf("%w: only ConfigMaps can be reverted, got %s", ErrRemediationUnsupported, parts[0])
+	}
+	resourceKey := ResourceKey("ConfigMap", namespace, name)
+	if d.managed != nil && d.managed(namespace, name) {
+		return nil, fmt.Errorf("%w: %s holds the runtime overrides, roll back limit changes through the limits API instead",
+			ErrRemediationUnsupported, resourceKey)
+	}
+
+	baseline := d.baselineStore.GetBaseline(resourceKey)
+	if baseline == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBaselineNotFound, resourceKey)
+	}
+
+	cm, err := d.k8sClient.GetConfigMap(ctx, namespace, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, resourceKey)
+		}
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	if request.ResourceVersion != "" && request.ResourceVersion != cm.ResourceVersion {
+		return nil, fmt.Errorf("%w: %s is at resourceVersion %s, expected %s",
+			ErrRemediationConflict, resourceKey, cm.ResourceVersion, request.ResourceVersion)
+	}
+	// Sanctioned writes are part of the baseline, not drift to revert
+	d.acceptExpected(resourceKey, baseline, cm)
+
+	result := &RemediationResult{
+		ResourceKey:             resourceKey,
+		Resource:                "ConfigMap",
+		Namespace:               namespace,
+		Name:                    name,
+		DryRun:                  request.DryRun,
+		Changes:                 []ConfigChange{},
+		RiskLevel:               "low",
+		BaselineHash:            baseline.Hash,
+		PreviousResourceVersion: cm.ResourceVersion,
+	}
+
+	if d.calculateConfigMapHash(cm) == baseline.Hash {
+		result.InSync = true
+		return result, nil
+	}
+
+	// The changes are the drift that reverting undoes
+	result.Changes = d.analyzeConfigMapChanges(baseline, cm)
+	result.RiskLevel = d.calculateRiskLevel(result.Changes)
+	if request.DryRun {
+		return result, nil
+	}
+
+	// The update carries the observed resourceVersion, so concurrent edits are rejected
+	reverted := cm.DeepCopy()
+	reverted.Data = baseline.Data
+	reverted.Labels = baseline.Labels
+	reverted.Annotations = baseline.Annotations
+	updated, err := d.k8sClient.UpdateConfigMap(ctx, namespace, reverted, metav1.UpdateOptions{})
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			return nil, fmt.Errorf("%w: %s was modified during remediation", ErrRemediationConflict, resourceKey)
+		}
+		return nil, fmt.Errorf("failed to update ConfigMap %s/%s: %w", namespace, name, err)
+	}
+
+	now := time.Now()
+	result.ResourceVersion = updated.ResourceVersion
+	result.RemediatedAt = &now
+
+	d.observe(resourceKey, baseline, baseline.Hash)
+	d.historyStore.Record([]DriftStatus{{
+		Resource:     "ConfigMap",
+		Namespace:    namespace,
+		Name:         name,
+		Status:       "remediated",
+		LastChecked:  now,
+		Changes:      result.Changes,
+		RiskLevel:    result.RiskLevel,
+		BaselineHash: baseline.Hash,
+		CurrentHash:  baseline.Hash,
+		Metadata: map[string]interface{}{
+			"remediated_by":             request.User,
+			"reason":                    request.Reason,
+			"previous_resource_version": cm.ResourceVersion,
+			"resource_version":          updated.ResourceVersion,
+		},
+	}})
+
+	logrus.Infof("🔧 [DRIFT] Reverted %s to its baseline (%d changes) for %s", resourceKey, len(result.Changes), request.User)
+	return result, nil
+}
@@ -1,44 +1,55 @@
 package drift
 
 import (
+	"errors"
 	"sync"
 	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+	"github.com/sirupsen/logrus"
 )
 
 // BaselineStore manages configuration baselines
 type BaselineStore struct {
-	baselines map[string]*BaselineConfig
-	mutex     sync.RWMutex
+	store    storage.Store
+	setMutex sync.Mutex // Serializes version allocation of named baseline sets
 }
 
-// NewBaselineStore creates a new baseline store
+// NewBaselineStore creates a new baseline store backed by the global storage
 func NewBaselineStore() *BaselineStore {
+	return NewBaselineStoreWith(storage.Get())
+}
+
+// NewBaselineStoreWith creates a new baseline store backed by the given storage
+func NewBaselineStoreWith(store storage.Store) *BaselineStore {
 	return &BaselineStore{
-		baselines: make(map[string]*BaselineConfig),
+		store: store,
 	}
 }
 
 // StoreBaseline stores a baseline configuration
 func (s *BaselineStore) StoreBaseline(key string, baseline *BaselineConfig) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.baselines[key] = baseline
+	if err := storage.PutJSON(s.store, storage.BucketDriftBaselines, key, baseline); err != nil {
+		logrus.Errorf("Failed to store baseline %s: %v", key, err)
+	}
 }
 
 // GetBaseline retrieves a baseline configuration
 func (s *BaselineStore) GetBaseline(key string) *BaselineConfig {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	return s.baselines[key]
+	var baseline BaselineConfig
+	if err := storage.GetJSON(s.store, storage.BucketDriftBaselines, key, &baseline); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			logrus.Errorf("Failed to load baseline %s: %v", key, err)
+		}
+		return nil
+	}
+	return &baseline
 }
 
 // GetBaselinesForNamespace retrieves all baselines for a namespace
 func (s *BaselineStore) GetBaselinesForNamespace(namespace string) map[string]*BaselineConfig {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
 	result := make(map[string]*BaselineConfig)
-	for key, baseline := range s.baselines {
+	for key, baseline := range s.GetAllBaselines() {
 		if baseline.Namespace == namespace {
 			result[key] = baseline
 		}
@@ -48,34 +59,37 @@ func (s *BaselineStore) GetBaselinesForNamespace(namespace string) map[string]*B
 
 // DeleteBaseline deletes a baseline configuration
 func (s *BaselineStore) DeleteBaseline(key string) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	delete(s.baselines, key)
+	if err := s.store.Delete(storage.BucketDriftBaselines, key); err != nil {
+		logrus.Errorf("Failed to delete baseline %s: %v", key, err)
+	}
 }
 
 // GetAllBaselines returns all baseline configurations
 func (s *BaselineStore) GetAllBaselines() map[string]*BaselineConfig {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
 	result := make(map[string]*BaselineConfig)
-	for key, baseline := range s.baselines {
-		result[key] = baseline
+	err := s.store.ForEach(storage.BucketDriftBaselines, func(key string, value []byte) error {
+		var baseline BaselineConfig
+		if err := storage.DecodeJSON(value, &baseline); err != nil {
+			logrus.Warnf("Skipping unreadable baseline %s: %v", key, err)
+			return nil
+		}
+		result[key] = &baseline
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to list baselines: %v", err)
 	}
 	return result
 }
 
 // CleanupOldBaselines removes baselines older than the specified duration
 func (s *BaselineStore) CleanupOldBaselines(maxAge time.Duration) int {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
 	cutoff := time.Now().Add(-maxAge)
 	cleaned := 0
 
-	for key, baseline := range s.baselines {
+	for key, baseline := range s.GetAllBaselines() {
 		if baseline.LastModified.Before(cutoff) {
-			delete(s.baselines, key)
+			s.DeleteBaseline(key)
 			cleaned++
 		}
 	}
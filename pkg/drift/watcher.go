@@ -0,0 +1,397 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/monitoring"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Watcher continuously detects drift from Kubernetes watch events and raises alerts
+type Watcher struct {
+	detector     *Detector
+	alertManager *monitoring.AlertManager
+	resync       time.Duration
+	factories    map[string]informers.SharedInformerFactory
+	stopCh       chan struct{}
+	isRunning    bool
+	mutex        sync.Mutex // Serializes event handling so baselines are updated in order
+	runningLock  sync.Mutex
+}
+
+// NewWatcher creates a drift watcher that reports high and critical drift to the alert manager
+func NewWatcher(detector *Detector, alertManager *monitoring.AlertManager, resync time.Duration) *Watcher {
+	return &Watcher{
+		detector:     detector,
+		alertManager: alertManager,
+		resync:       resync,
+		factories:    make(map[string]informers.SharedInformerFactory),
+	}
+}
+
+// Start begins watching ConfigMaps and workloads in the given namespaces until ctx is done or Stop is called
+func (w *Watcher) Start(ctx context.Context, namespaces []string) error {
+	w.runningLock.Lock()
+	defer w.runningLock.Unlock()
+
+	if w.isRunning {
+		return fmt.Errorf("drift watcher is already running")
+	}
+	// Stop may have run while the caller was still discovering namespaces
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("drift watcher not started: %w", err)
+	}
+
+	w.stopCh = make(chan struct{})
+	for _, namespace := range namespaces {
+		if namespace == "" || w.factories[namespace] != nil {
+			continue
+		}
+		factory := w.detector.k8sClient.NewInformerFactory(namespace, w.resync)
+		w.registerHandlers(factory)
+		factory.Start(w.stopCh)
+		w.factories[namespace] = factory
+	}
+
+	w.isRunning = true
+	logrus.Infof("👀 [DRIFT] Watching %d namespaces for configuration drift", len(w.factories))
+
+	go func(stopCh chan struct{}) {
+		select {
+		case <-ctx.Done():
+			w.Stop()
+		case <-stopCh:
+		}
+	}(w.stopCh)
+	return nil
+}
+
+// Stop stops all informers
+func (w *Watcher) Stop() {
+	w.runningLock.Lock()
+	defer w.runningLock.Unlock()
+
+	if !w.isRunning {
+		return
+	}
+
+	close(w.stopCh)
+	w.factories = make(map[string]informers.SharedInformerFactory)
+	w.isRunning = false
+	logrus.Info("Drift watcher stopped")
+}
+
+// registerHandlers wires the watch events of every monitored resource kind
+func (w *Watcher) registerHandlers(factory informers.SharedInformerFactory) {
+	hpaLister := factory.Autoscaling().V2().HorizontalPodAutoscalers().Lister()
+	deploymentLister := factory.Apps().V1().Deployments().Lister()
+	statefulSetLister := factory.Apps().V1().StatefulSets().Lister()
+	daemonSetLister := factory.Apps().V1().DaemonSets().Lister()
+
+	isAutoscaled := func(kind, namespace, name string) bool {
+		hpas, err := hpaLister.HorizontalPodAutoscalers(namespace).List(labels.Everything())
+		if err != nil {
+			return false
+		}
+		for _, hpa := range hpas {
+			if hpaTargetKey(hpa) == kind+"/"+name {
+				return true
+			}
+		}
+		return false
+	}
+
+	// monitoredPodLabels returns the pod template labels of the monitored workloads in a namespace
+	monitoredPodLabels := func(namespace string) []map[string]string {
+		var podLabels []map[string]string
+		if deployments, err := deploymentLister.Deployments(namespace).List(labels.Everything()); err == nil {
+			for _, deployment := range deployments {
+				if w.detector.shouldMonitorWorkload(deployment.Name, deployment.Labels, deployment.Annotations) {
+					podLabels = append(podLabels, deployment.Spec.Template.Labels)
+				}
+			}
+		}
+		if statefulSets, err := statefulSetLister.StatefulSets(namespace).List(labels.Everything()); err == nil {
+			for _, statefulSet := range statefulSets {
+				if w.detector.shouldMonitorWorkload(statefulSet.Name, statefulSet.Labels, statefulSet.Annotations) {
+					podLabels = append(podLabels, statefulSet.Spec.Template.Labels)
+				}
+			}
+		}
+		if daemonSets, err := daemonSetLister.DaemonSets(namespace).List(labels.Everything()); err == nil {
+			for _, daemonSet := range daemonSets {
+				if w.detector.shouldMonitorWorkload(daemonSet.Name, daemonSet.Labels, daemonSet.Annotations) {
+					podLabels = append(podLabels, daemonSet.Spec.Template.Labels)
+				}
+			}
+		}
+		return podLabels
+	}
+
+	factory.Core().V1().ConfigMaps().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if cm, ok := obj.(*corev1.ConfigMap); ok && w.detector.shouldMonitorConfigMap(cm) {
+				w.handle(func() DriftStatus { return w.detector.analyzeConfigMapDrift(cm) })
+			}
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			// ConfigMaps have no status or generation, so every update may change data
+			cm, ok := newObj.(*corev1.ConfigMap)
+			if !ok || !w.detector.shouldMonitorConfigMap(cm) {
+				return
+			}
+			w.handle(func() DriftStatus { return w.detector.analyzeConfigMapDrift(cm) })
+		},
+		DeleteFunc: func(obj interface{}) {
+			if cm, ok := deletedObject(obj).(*corev1.ConfigMap); ok && w.detector.shouldMonitorConfigMap(cm) {
+				w.handleDeletion("ConfigMap", cm.ObjectMeta)
+			}
+		},
+	})
+
+	factory.Apps().V1().Deployments().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if deployment, ok := obj.(*appsv1.Deployment); ok {
+				w.handleWorkload(deployment.ObjectMeta, func() workloadSnapshot {
+					return deploymentSnapshot(deployment, isAutoscaled(ResourceDeployment, deployment.Namespace, deployment.Name))
+				})
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldDeployment, okOld := oldObj.(*appsv1.Deployment)
+			deployment, okNew := newObj.(*appsv1.Deployment)
+			if okOld && okNew && needsAnalysis(oldDeployment.ObjectMeta, deployment.ObjectMeta) {
+				w.handleWorkload(deployment.ObjectMeta, func() workloadSnapshot {
+					return deploymentSnapshot(deployment, isAutoscaled(ResourceDeployment, deployment.Namespace, deployment.Name))
+				})
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if deployment, ok := deletedObject(obj).(*appsv1.Deployment); ok {
+				w.handleDeletion(ResourceDeployment, deployment.ObjectMeta)
+			}
+		},
+	})
+
+	factory.Apps().V1().StatefulSets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if statefulSet, ok := obj.(*appsv1.StatefulSet); ok {
+				w.handleWorkload(statefulSet.ObjectMeta, func() workloadSnapshot {
+					return statefulSetSnapshot(statefulSet, isAutoscaled(ResourceStatefulSet, statefulSet.Namespace, statefulSet.Name))
+				})
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldStatefulSet, okOld := oldObj.(*appsv1.StatefulSet)
+			statefulSet, okNew := newObj.(*appsv1.StatefulSet)
+			if okOld && okNew && needsAnalysis(oldStatefulSet.ObjectMeta, statefulSet.ObjectMeta) {
+				w.handleWorkload(statefulSet.ObjectMeta, func() workloadSnapshot {
+					return statefulSetSnapshot(statefulSet, isAutoscaled(ResourceStatefulSet, statefulSet.Namespace, statefulSet.Name))
+				})
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if statefulSet, ok := deletedObject(obj).(*appsv1.StatefulSet); ok {
+				w.handleDeletion(ResourceStatefulSet, statefulSet.ObjectMeta)
+			}
+		},
+	})
+
+	factory.Apps().V1().DaemonSets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if daemonSet, ok := obj.(*appsv1.DaemonSet); ok {
+				w.handleWorkload(daemonSet.ObjectMeta, func() workloadSnapshot { return daemonSetSnapshot(daemonSet) })
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldDaemonSet, okOld := oldObj.(*appsv1.DaemonSet)
+			daemonSet, okNew := newObj.(*appsv1.DaemonSet)
+			if okOld && okNew && needsAnalysis(oldDaemonSet.ObjectMeta, daemonSet.ObjectMeta) {
+				w.handleWorkload(daemonSet.ObjectMeta, func() workloadSnapshot { return daemonSetSnapshot(daemonSet) })
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if daemonSet, ok := deletedObject(obj).(*appsv1.DaemonSet); ok {
+				w.handleDeletion(ResourceDaemonSet, daemonSet.ObjectMeta)
+			}
+		},
+	})
+
+	onHPA := func(hpa *autoscalingv2.HorizontalPodAutoscaler) {
+		// Only HPAs scaling a monitored workload are tracked
+		if !w.hasBaseline(ResourceKey(hpa.Spec.ScaleTargetRef.Kind, hpa.Namespace, hpa.Spec.ScaleTargetRef.Name)) {
+			return
+		}
+		w.handle(func() DriftStatus { return w.detector.analyzeWorkloadDrift(hpaSnapshot(hpa)) })
+	}
+	factory.Autoscaling().V2().HorizontalPodAutoscalers().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if hpa, ok := obj.(*autoscalingv2.HorizontalPodAutoscaler); ok {
+				onHPA(hpa)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldHPA, okOld := oldObj.(*autoscalingv2.HorizontalPodAutoscaler)
+			hpa, okNew := newObj.(*autoscalingv2.HorizontalPodAutoscaler)
+			if okOld && okNew && needsAnalysis(oldHPA.ObjectMeta, hpa.ObjectMeta) {
+				onHPA(hpa)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if hpa, ok := deletedObject(obj).(*autoscalingv2.HorizontalPodAutoscaler); ok {
+				w.handleDeletion(ResourceHorizontalPodAutoscaler, hpa.ObjectMeta)
+			}
+		},
+	})
+
+	onPDB := func(pdb *policyv1.PodDisruptionBudget) {
+		if snapshot, ok := pdbSnapshot(pdb, monitoredPodLabels(pdb.Namespace)); ok {
+			w.handle(func() DriftStatus { return w.detector.analyzeWorkloadDrift(snapshot) })
+		}
+	}
+	factory.Policy().V1().PodDisruptionBudgets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pdb, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+				onPDB(pdb)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPDB, okOld := oldObj.(*policyv1.PodDisruptionBudget)
+			pdb, okNew := newObj.(*policyv1.PodDisruptionBudget)
+			if okOld && okNew && needsAnalysis(oldPDB.ObjectMeta, pdb.ObjectMeta) {
+				onPDB(pdb)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if pdb, ok := deletedObject(obj).(*policyv1.PodDisruptionBudget); ok {
+				w.handleDeletion(ResourcePodDisruptionBudget, pdb.ObjectMeta)
+			}
+		},
+	})
+}
+
+// handleWorkload analyzes a workload event if the workload is monitored
+func (w *Watcher) handleWorkload(meta metav1.ObjectMeta, snapshot func() workloadSnapshot) {
+	if !w.detector.shouldMonitorWorkload(meta.Name, meta.Labels, meta.Annotations) {
+		return
+	}
+	w.handle(func() DriftStatus { return w.detector.analyzeWorkloadDrift(snapshot()) })
+}
+
+// handleDeletion reports the deletion of a resource that has a baseline.
+// Drift reported for the resource no longer applies, so its alert is resolved first.
+func (w *Watcher) handleDeletion(resourceKind string, meta metav1.ObjectMeta) {
+	resourceKey := ResourceKey(resourceKind, meta.Namespace, meta.Name)
+	w.ResolveAlert(resourceKey)
+	w.handle(func() DriftStatus {
+		baseline := w.detector.baselineStore.GetBaseline(resourceKey)
+		if baseline == nil {
+			return DriftStatus{Status: "no_drift"}
+		}
+		return w.detector.deletedStatus(resourceKey, baseline)
+	})
+}
+
+// handle runs an analysis, records any drift and alerts on high or critical risk.
+// The alert of a resource that is back in sync or no longer risky is resolved.
+func (w *Watcher) handle(analyze func() DriftStatus) {
+	w.mutex.Lock()
+	status := analyze()
+	w.mutex.Unlock()
+
+	alerting := status.Status != "no_drift" && (status.RiskLevel == "high" || status.RiskLevel == "critical")
+	if status.Resource != "" && !alerting {
+		w.ResolveAlert(ResourceKey(status.Resource, status.Namespace, status.Name))
+	}
+	if status.Status == "no_drift" || status.alreadyReported {
+		return
+	}
+
+	w.detector.historyStore.Record([]DriftStatus{status})
+	logrus.Infof("🔍 [DRIFT] %s %s/%s is %s (risk: %s, %d changes)",
+		status.Resource, status.Namespace, status.Name, status.Status, status.RiskLevel, len(status.Changes))
+
+	if alerting && w.alertManager != nil {
+		w.alertManager.TriggerAlert(driftAlert(status))
+	}
+}
+
+// ResolveAlert resolves the drift alert of a resource, e.g. after it was remediated
+func (w *Watcher) ResolveAlert(resourceKey string) {
+	if w.alertManager != nil {
+		w.alertManager.ResolveAlert(driftAlertName(resourceKey))
+	}
+}
+
+// driftAlertName names the alert of a resource, so raising and resolving it refer to the same alert
+func driftAlertName(resourceKey string) string {
+	return fmt.Sprintf("Configuration drift: %s", resourceKey)
+}
+
+func (w *Watcher) hasBaseline(resourceKey string) bool {
+	return w.detector.baselineStore.GetBaseline(resourceKey) != nil
+}
+
+// driftAlert builds the alert raised for a drifted resource
+func driftAlert(status DriftStatus) monitoring.ActiveAlert {
+	severity := "warning"
+	if status.RiskLevel == "critical" {
+		severity = "critical"
+	}
+
+	var details []string
+	for i, change := range status.Changes {
+		if i == 5 {
+			details = append(details, fmt.Sprintf("... and %d more", len(status.Changes)-i))
+			break
+		}
+		details = append(details, change.Description)
+	}
+
+	return monitoring.ActiveAlert{
+		Name: driftAlertName(ResourceKey(status.Resource, status.Namespace, status.Name)),
+		Description: fmt.Sprintf("%s %s/%s %s with %s risk: %s", status.Resource, status.Namespace, status.Name,
+			status.Status, status.RiskLevel, strings.Join(details, "; ")),
+		Severity:  severity,
+		Timestamp: status.LastChecked,
+		Component: "drift-detector",
+		Status:    "firing",
+		Metadata: map[string]interface{}{
+			"resource":   status.Resource,
+			"namespace":  status.Namespace,
+			"name":       status.Name,
+			"risk_level": status.RiskLevel,
+			"changes":    status.Changes,
+		},
+	}
+}
+
+// needsAnalysis skips status-only updates, which make up most workload events.
+// Periodic resyncs deliver unchanged objects and are re-analyzed against the baseline.
+func needsAnalysis(oldMeta, newMeta metav1.ObjectMeta) bool {
+	return oldMeta.ResourceVersion == newMeta.ResourceVersion ||
+		oldMeta.Generation != newMeta.Generation ||
+		!reflect.DeepEqual(oldMeta.Labels, newMeta.Labels) ||
+		!reflect.DeepEqual(oldMeta.Annotations, newMeta.Annotations)
+}
+
+// deletedObject unwraps objects whose deletion was observed only after a relist
+func deletedObject(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
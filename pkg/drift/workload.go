@@ -0,0 +1,516 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Workload resource kinds tracked alongside ConfigMaps
+const (
+	ResourceDeployment              = "Deployment"
+	ResourceStatefulSet             = "StatefulSet"
+	ResourceDaemonSet               = "DaemonSet"
+	ResourceHorizontalPodAutoscaler = "HorizontalPodAutoscaler"
+	ResourcePodDisruptionBudget     = "PodDisruptionBudget"
+)
+
+// ignoredWorkloadAnnotations change on every rollout or apply and are not drift
+var ignoredWorkloadAnnotations = map[string]bool{
+	"deployment.kubernetes.io/revision":                true,
+	"kubectl.kubernetes.io/last-applied-configuration": true,
+}
+
+// workloadSnapshot is the drift-relevant state of a workload, flattened to comparable keys
+type workloadSnapshot struct {
+	Resource    string
+	Namespace   string
+	Name        string
+	Data        map[string]string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// workloadObjects holds the workload resources of one namespace
+type workloadObjects struct {
+	Deployments  []appsv1.Deployment
+	StatefulSets []appsv1.StatefulSet
+	DaemonSets   []appsv1.DaemonSet
+	HPAs         []autoscalingv2.HorizontalPodAutoscaler
+	PDBs         []policyv1.PodDisruptionBudget
+}
+
+// collectWorkloads snapshots the Mimir workloads of a namespace with their HPAs and PDBs.
+// The returned set lists the resource kinds that were read successfully.
+func (d *Detector) collectWorkloads(ctx context.Context, namespace string) ([]workloadSnapshot, map[string]bool) {
+	var objects workloadObjects
+	listed := make(map[string]bool)
+
+	if hpas, err := d.k8sClient.GetHorizontalPodAutoscalers(ctx, namespace, metav1.ListOptions{}); err != nil {
+		logrus.Warnf("Failed to get HorizontalPodAutoscalers for drift detection in %s: %v", namespace, err)
+	} else {
+		listed[ResourceHorizontalPodAutoscaler] = true
+		objects.HPAs = hpas.Items
+	}
+
+	if deployments, err := d.k8sClient.GetDeployments(ctx, namespace, metav1.ListOptions{}); err != nil {
+		logrus.Warnf("Failed to get Deployments for drift detection in %s: %v", namespace, err)
+	} else {
+		listed[ResourceDeployment] = true
+		objects.Deployments = deployments.Items
+	}
+
+	if statefulSets, err := d.k8sClient.GetStatefulSets(ctx, namespace, metav1.ListOptions{}); err != nil {
+		logrus.Warnf("Failed to get StatefulSets for drift detection in %s: %v", namespace, err)
+	} else {
+		listed[ResourceStatefulSet] = true
+		objects.StatefulSets = statefulSets.Items
+	}
+
+	if daemonSets, err := d.k8sClient.GetDaemonSets(ctx, namespace, metav1.ListOptions{}); err != nil {
+		logrus.Warnf("Failed to get DaemonSets for drift detection in %s: %v", namespace, err)
+	} else {
+		listed[ResourceDaemonSet] = true
+		objects.DaemonSets = daemonSets.Items
+	}
+
+	if pdbs, err := d.k8sClient.GetPodDisruptionBudgets(ctx, namespace, metav1.ListOptions{}); err != nil {
+		logrus.Warnf("Failed to get PodDisruptionBudgets for drift detection in %s: %v", namespace, err)
+	} else {
+		listed[ResourcePodDisruptionBudget] = true
+		objects.PDBs = pdbs.Items
+	}
+
+	return d.workloadSnapshots(objects), listed
+}
+
+// workloadSnapshots snapshots the monitored workloads and the HPAs and PDBs that belong to them
+func (d *Detector) workloadSnapshots(objects workloadObjects) []workloadSnapshot {
+	var snapshots []workloadSnapshot
+	var podLabels []map[string]string
+
+	// Replica counts of autoscaled workloads change constantly and are tracked through the HPA instead
+	autoscaled := make(map[string]bool)
+	for i := range objects.HPAs {
+		autoscaled[hpaTargetKey(&objects.HPAs[i])] = true
+	}
+
+	workloads := make(map[string]bool)
+
+	for i := range objects.Deployments {
+		deployment := &objects.Deployments[i]
+		if !d.shouldMonitorWorkload(deployment.Name, deployment.Labels, deployment.Annotations) {
+			continue
+		}
+		snapshots = append(snapshots, deploymentSnapshot(deployment, autoscaled[ResourceDeployment+"/"+deployment.Name]))
+		podLabels = append(podLabels, deployment.Spec.Template.Labels)
+		workloads[ResourceDeployment+"/"+deployment.Name] = true
+	}
+
+	for i := range objects.StatefulSets {
+		statefulSet := &objects.StatefulSets[i]
+		if !d.shouldMonitorWorkload(statefulSet.Name, statefulSet.Labels, statefulSet.Annotations) {
+			continue
+		}
+		snapshots = append(snapshots, statefulSetSnapshot(statefulSet, autoscaled[ResourceStatefulSet+"/"+statefulSet.Name]))
+		podLabels = append(podLabels, statefulSet.Spec.Template.Labels)
+		workloads[ResourceStatefulSet+"/"+statefulSet.Name] = true
+	}
+
+	for i := range objects.DaemonSets {
+		daemonSet := &objects.DaemonSets[i]
+		if !d.shouldMonitorWorkload(daemonSet.Name, daemonSet.Labels, daemonSet.Annotations) {
+			continue
+		}
+		snapshots = append(snapshots, daemonSetSnapshot(daemonSet))
+		podLabels = append(podLabels, daemonSet.Spec.Template.Labels)
+		workloads[ResourceDaemonSet+"/"+daemonSet.Name] = true
+	}
+
+	for i := range objects.HPAs {
+		if workloads[hpaTargetKey(&objects.HPAs[i])] {
+			snapshots = append(snapshots, hpaSnapshot(&objects.HPAs[i]))
+		}
+	}
+
+	for i := range objects.PDBs {
+		if snapshot, ok := pdbSnapshot(&objects.PDBs[i], podLabels); ok {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	return snapshots
+}
+
+func deploymentSnapshot(deployment *appsv1.Deployment, autoscaled bool) workloadSnapshot {
+	data := podTemplateData(&deployment.Spec.Template)
+	if !autoscaled {
+		data["replicas"] = replicaString(deployment.Spec.Replicas)
+	}
+	if deployment.Spec.Strategy.Type != "" {
+		data["strategy"] = string(deployment.Spec.Strategy.Type)
+	}
+	return newWorkloadSnapshot(ResourceDeployment, deployment.ObjectMeta, data)
+}
+
+func statefulSetSnapshot(statefulSet *appsv1.StatefulSet, autoscaled bool) workloadSnapshot {
+	data := podTemplateData(&statefulSet.Spec.Template)
+	if !autoscaled {
+		data["replicas"] = replicaString(statefulSet.Spec.Replicas)
+	}
+	for _, claim := range statefulSet.Spec.VolumeClaimTemplates {
+		if storage, exists := claim.Spec.Resources.Requests[corev1.ResourceStorage]; exists {
+			data[fmt.Sprintf("volumeClaimTemplates.%s.storage", claim.Name)] = storage.String()
+		}
+	}
+	return newWorkloadSnapshot(ResourceStatefulSet, statefulSet.ObjectMeta, data)
+}
+
+func daemonSetSnapshot(daemonSet *appsv1.DaemonSet) workloadSnapshot {
+	return newWorkloadSnapshot(ResourceDaemonSet, daemonSet.ObjectMeta, podTemplateData(&daemonSet.Spec.Template))
+}
+
+func hpaSnapshot(hpa *autoscalingv2.HorizontalPodAutoscaler) workloadSnapshot {
+	data := map[string]string{
+		"scaleTargetRef": hpaTargetKey(hpa),
+		"minReplicas":    replicaString(hpa.Spec.MinReplicas),
+		"maxReplicas":    fmt.Sprintf("%d", hpa.Spec.MaxReplicas),
+	}
+	for i, metric := range hpa.Spec.Metrics {
+		if encoded, err := json.Marshal(metric); err == nil {
+			data[fmt.Sprintf("metrics[%d]", i)] = string(encoded)
+		}
+	}
+	return newWorkloadSnapshot(ResourceHorizontalPodAutoscaler, hpa.ObjectMeta, data)
+}
+
+// pdbSnapshot snapshots a PDB if it selects the pods of a monitored workload
+func pdbSnapshot(pdb *policyv1.PodDisruptionBudget, podLabels []map[string]string) (workloadSnapshot, bool) {
+	selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+	if err != nil || selector.Empty() || !selectsAny(selector, podLabels) {
+		return workloadSnapshot{}, false
+	}
+	data := map[string]string{
+		"selector": selector.String(),
+	}
+	if pdb.Spec.MinAvailable != nil {
+		data["minAvailable"] = pdb.Spec.MinAvailable.String()
+	}
+	if pdb.Spec.MaxUnavailable != nil {
+		data["maxUnavailable"] = pdb.Spec.MaxUnavailable.String()
+	}
+	return newWorkloadSnapshot(ResourcePodDisruptionBudget, pdb.ObjectMeta, data), true
+}
+
+func hpaTargetKey(hpa *autoscalingv2.HorizontalPodAutoscaler) string {
+	return hpa.Spec.ScaleTargetRef.Kind + "/" + hpa.Spec.ScaleTargetRef.Name
+}
+
+// detectWorkloadDrift detects drift in Mimir workloads and their HPAs and PDBs
+func (d *Detector) detectWorkloadDrift(ctx context.Context, namespace string) []DriftStatus {
+	var statuses []DriftStatus
+
+	snapshots, listed := d.collectWorkloads(ctx, namespace)
+	current := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		current[ResourceKey(snapshot.Resource, snapshot.Namespace, snapshot.Name)] = true
+		statuses = append(statuses, d.analyzeWorkloadDrift(snapshot))
+	}
+
+	// Only report deletions for kinds that could be listed, so API errors are not mistaken for deletions
+	for key, baseline := range d.baselineStore.GetBaselinesForNamespace(namespace) {
+		if baseline.Resource == "ConfigMap" || !listed[baseline.Resource] || current[key] {
+			continue
+		}
+		statuses = append(statuses, d.deletedStatus(key, baseline))
+	}
+
+	return statuses
+}
+
+// analyzeWorkloadDrift analyzes drift for a workload snapshot
+func (d *Detector) analyzeWorkloadDrift(snapshot workloadSnapshot) DriftStatus {
+	resourceKey := ResourceKey(snapshot.Resource, snapshot.Namespace, snapshot.Name)
+	currentHash := calculateDataHash(snapshot.Data, snapshot.Labels, snapshot.Annotations)
+
+	status := DriftStatus{
+		Resource:    snapshot.Resource,
+		Namespace:   snapshot.Namespace,
+		Name:        snapshot.Name,
+		LastChecked: time.Now(),
+		CurrentHash: currentHash,
+		Changes:     []ConfigChange{},
+		Metadata: map[string]interface{}{
+			"labels":      snapshot.Labels,
+			"annotations": snapshot.Annotations,
+		},
+	}
+
+	baseline := d.baselineStore.GetBaseline(resourceKey)
+	if baseline == nil {
+		status.Status = "new"
+		status.RiskLevel = "medium"
+		d.baselineStore.StoreBaseline(resourceKey, snapshot.baseline(currentHash))
+		return status
+	}
+
+	status.BaselineHash = baseline.Hash
+
+	if currentHash == baseline.Hash {
+		status.Status = "no_drift"
+		status.RiskLevel = "low"
+	} else {
+		status.Status = "drifted"
+		status.Changes = d.analyzeWorkloadChanges(baseline, snapshot)
+		status.RiskLevel = d.calculateRiskLevel(status.Changes)
+	}
+	status.alreadyReported = d.observe(resourceKey, baseline, currentHash)
+
+	return status
+}
+
+// analyzeWorkloadChanges compares a workload snapshot against its baseline
+func (d *Detector) analyzeWorkloadChanges(baseline *BaselineConfig, snapshot workloadSnapshot) []ConfigChange {
+	var changes []ConfigChange
+
+	keys := make([]string, 0, len(baseline.Data)+len(snapshot.Data))
+	for key := range baseline.Data {
+		keys = append(keys, key)
+	}
+	for key := range snapshot.Data {
+		if _, exists := baseline.Data[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		oldValue, inOld := baseline.Data[key]
+		newValue, inNew := snapshot.Data[key]
+
+		change := ConfigChange{
+			Key:      key,
+			OldValue: oldValue,
+			NewValue: newValue,
+			Impact:   d.assessWorkloadChangeImpact(snapshot.Resource, key, oldValue, newValue),
+		}
+		switch {
+		case inOld && inNew:
+			if oldValue == newValue {
+				continue
+			}
+			change.Type = "modified"
+			change.Description = fmt.Sprintf("%s changed from %s to %s", key, oldValue, newValue)
+		case inOld:
+			change.Type = "deleted"
+			change.Description = fmt.Sprintf("%s (was %s) was removed", key, oldValue)
+		default:
+			change.Type = "added"
+			change.Description = fmt.Sprintf("%s was set to %s", key, newValue)
+		}
+		changes = append(changes, change)
+	}
+
+	changes = append(changes, d.analyzeMapChanges("labels", baseline.Labels, snapshot.Labels)...)
+	changes = append(changes, d.analyzeMapChanges("annotations", baseline.Annotations, snapshot.Annotations)...)
+
+	return changes
+}
+
+// assessWorkloadChangeImpact assesses the impact of a change to a workload setting
+func (d *Detector) assessWorkloadChangeImpact(resourceKind, key, oldValue, newValue string) string {
+	switch {
+	case strings.Contains(key, ".args."):
+		// Judge Mimir flags like limits in YAML, e.g. -ingester.max-global-series-per-user
+		flag := key[strings.Index(key, ".args.")+len(".args."):]
+		setting := strings.ReplaceAll(strings.TrimLeft(flag, "-"), "-", "_")
+		return d.assessDataChangeImpact("", setting, oldValue, newValue)
+	case strings.Contains(key, ".resources.limits."), strings.Contains(key, ".resources.requests."):
+		// Lower memory or CPU limits risk OOM kills and throttling
+		if isQuantityReduction(oldValue, newValue) {
+			return "critical"
+		}
+		return "high"
+	case strings.HasSuffix(key, ".image"), strings.HasSuffix(key, ".command"):
+		return "high"
+	case key == "replicas", key == "minReplicas", key == "maxReplicas":
+		if isLimitReduction(oldValue, newValue) {
+			return "high"
+		}
+		return "medium"
+	case strings.HasPrefix(key, "volumeClaimTemplates."):
+		return "high"
+	case resourceKind == ResourcePodDisruptionBudget:
+		return "high"
+	}
+	return "medium"
+}
+
+// podTemplateData flattens the drift-relevant settings of a pod template
+func podTemplateData(template *corev1.PodTemplateSpec) map[string]string {
+	data := make(map[string]string)
+	addContainerData(data, "containers", template.Spec.Containers)
+	addContainerData(data, "initContainers", template.Spec.InitContainers)
+
+	// Template annotations often carry config checksums that trigger rollouts
+	for key, value := range template.Annotations {
+		data["template.annotations."+key] = value
+	}
+	if template.Spec.ServiceAccountName != "" {
+		data["serviceAccountName"] = template.Spec.ServiceAccountName
+	}
+	for key, value := range template.Spec.NodeSelector {
+		data["nodeSelector."+key] = value
+	}
+	return data
+}
+
+// isFlagArg reports whether a container argument is a flag rather than a value, e.g. a negative number
+func isFlagArg(arg string) bool {
+	if !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
+
+func addContainerData(data map[string]string, prefix string, containers []corev1.Container) {
+	for _, container := range containers {
+		base := fmt.Sprintf("%s.%s", prefix, container.Name)
+		data[base+".image"] = container.Image
+		if len(container.Command) > 0 {
+			data[base+".command"] = strings.Join(container.Command, " ")
+		}
+
+		// Key flags by name so a changed value is reported as that flag changing.
+		// A flag's value is either joined with = or the next argument.
+		args := container.Args
+		for i := 0; i < len(args); i++ {
+			if !isFlagArg(args[i]) {
+				data[fmt.Sprintf("%s.args[%d]", base, i)] = args[i]
+				continue
+			}
+			name, value, hasValue := strings.Cut(args[i], "=")
+			if !hasValue {
+				value = "true"
+				if i+1 < len(args) && !isFlagArg(args[i+1]) {
+					i++
+					value = args[i]
+				}
+			}
+			data[fmt.Sprintf("%s.args.%s", base, name)] = value
+		}
+
+		for name, quantity := range container.Resources.Requests {
+			data[fmt.Sprintf("%s.resources.requests.%s", base, name)] = quantity.String()
+		}
+		for name, quantity := range container.Resources.Limits {
+			data[fmt.Sprintf("%s.resources.limits.%s", base, name)] = quantity.String()
+		}
+
+		for _, env := range container.Env {
+			value := env.Value
+			if env.ValueFrom != nil {
+				value = describeEnvSource(env.ValueFrom)
+			}
+			data[fmt.Sprintf("%s.env.%s", base, env.Name)] = value
+		}
+	}
+}
+
+// describeEnvSource describes where an env var comes from without resolving secrets
+func describeEnvSource(source *corev1.EnvVarSource) string {
+	switch {
+	case source.SecretKeyRef != nil:
+		return fmt.Sprintf("secret:%s/%s", source.SecretKeyRef.Name, source.SecretKeyRef.Key)
+	case source.ConfigMapKeyRef != nil:
+		return fmt.Sprintf("configmap:%s/%s", source.ConfigMapKeyRef.Name, source.ConfigMapKeyRef.Key)
+	case source.FieldRef != nil:
+		return fmt.Sprintf("field:%s", source.FieldRef.FieldPath)
+	case source.ResourceFieldRef != nil:
+		return fmt.Sprintf("resource:%s", source.ResourceFieldRef.Resource)
+	}
+	return "valueFrom"
+}
+
+// shouldMonitorWorkload determines if a workload should be monitored
+func (d *Detector) shouldMonitorWorkload(name string, labels, annotations map[string]string) bool {
+	if d.discoveryEngine != nil {
+		return d.discoveryEngine.IsMimirComponent(name, labels, annotations)
+	}
+	lowerName := strings.ToLower(name)
+	return strings.Contains(lowerName, "mimir") && !strings.Contains(lowerName, "mimir-insights")
+}
+
+func newWorkloadSnapshot(resourceKind string, meta metav1.ObjectMeta, data map[string]string) workloadSnapshot {
+	annotations := make(map[string]string)
+	for key, value := range meta.Annotations {
+		if !ignoredWorkloadAnnotations[key] {
+			annotations[key] = value
+		}
+	}
+	return workloadSnapshot{
+		Resource:    resourceKind,
+		Namespace:   meta.Namespace,
+		Name:        meta.Name,
+		Data:        data,
+		Labels:      meta.Labels,
+		Annotations: annotations,
+	}
+}
+
+func (s workloadSnapshot) baseline(hash string) *BaselineConfig {
+	return &BaselineConfig{
+		Resource:     s.Resource,
+		Namespace:    s.Namespace,
+		Name:         s.Name,
+		Hash:         hash,
+		Data:         s.Data,
+		Labels:       s.Labels,
+		Annotations:  s.Annotations,
+		CreatedAt:    time.Now(),
+		LastModified: time.Now(),
+	}
+}
+
+func replicaString(replicas *int32) string {
+	if replicas == nil {
+		return "1"
+	}
+	return fmt.Sprintf("%d", *replicas)
+}
+
+func selectsAny(selector labels.Selector, podLabels []map[string]string) bool {
+	for _, set := range podLabels {
+		if selector.Matches(labels.Set(set)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isQuantityReduction reports whether a resource quantity was lowered
+func isQuantityReduction(oldValue, newValue string) bool {
+	oldQuantity, err := resource.ParseQuantity(oldValue)
+	if err != nil {
+		return false
+	}
+	newQuantity, err := resource.ParseQuantity(newValue)
+	if err != nil {
+		return false
+	}
+	return newQuantity.Cmp(oldQuantity) < 0
+}
@@ -0,0 +1,157 @@
+package drift
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func ingesterDeployment(args ...string) *appsv1.Deployment {
+	replicas := int32(3)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mimir-ingester",
+			Namespace:   "mimir",
+			Labels:      map[string]string{"app": "mimir"},
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "7"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "ingester", Image: "grafana/mimir:2.10.0", Args: args}},
+				},
+			},
+		},
+	}
+}
+
+// argData returns the entries of a snapshot recorded for container args
+func argData(data map[string]string) map[string]string {
+	args := make(map[string]string)
+	for key, value := range data {
+		if strings.HasPrefix(key, "containers.ingester.args") {
+			args[strings.TrimPrefix(key, "containers.ingester.")] = value
+		}
+	}
+	return args
+}
+
+func TestPodTemplateDataArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want map[string]string
+	}{
+		{
+			name: "joined value",
+			args: []string{"-target=ingester"},
+			want: map[string]string{"args.-target": "ingester"},
+		},
+		{
+			name: "value as next argument",
+			args: []string{"-target", "ingester", "--config.file", "/etc/mimir/mimir.yaml"},
+			want: map[string]string{"args.-target": "ingester", "args.--config.file": "/etc/mimir/mimir.yaml"},
+		},
+		{
+			name: "boolean flag before another flag",
+			args: []string{"-config.expand-env", "-target=ingester", "-log.json"},
+			want: map[string]string{"args.-config.expand-env": "true", "args.-target": "ingester", "args.-log.json": "true"},
+		},
+		{
+			name: "negative number is a value",
+			args: []string{"-ingester.max-global-series-per-user", "-1"},
+			want: map[string]string{"args.-ingester.max-global-series-per-user": "-1"},
+		},
+		{
+			name: "positional argument",
+			args: []string{"run", "-target=ingester"},
+			want: map[string]string{"args[0]": "run", "args.-target": "ingester"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := argData(podTemplateData(&ingesterDeployment(tt.args...).Spec.Template))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("args data = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeploymentSnapshot(t *testing.T) {
+	deployment := ingesterDeployment("-target=ingester")
+
+	snapshot := deploymentSnapshot(deployment, false)
+	if snapshot.Resource != ResourceDeployment || snapshot.Namespace != "mimir" || snapshot.Name != "mimir-ingester" {
+		t.Errorf("snapshot is for %s %s/%s", snapshot.Resource, snapshot.Namespace, snapshot.Name)
+	}
+	if snapshot.Data["replicas"] != "3" || snapshot.Data["containers.ingester.image"] != "grafana/mimir:2.10.0" {
+		t.Errorf("snapshot data = %v, want the replicas and image", snapshot.Data)
+	}
+	if _, kept := snapshot.Annotations["deployment.kubernetes.io/revision"]; kept {
+		t.Errorf("snapshot annotations = %v, want the revision annotation ignored", snapshot.Annotations)
+	}
+
+	if _, recorded := deploymentSnapshot(deployment, true).Data["replicas"]; recorded {
+		t.Errorf("autoscaled snapshot records replicas, which the HPA owns")
+	}
+}
+
+func TestWorkloadDriftHashing(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseline    []string
+		current     []string
+		wantStatus  string
+		wantChanged string
+	}{
+		{
+			name:       "same args",
+			baseline:   []string{"-target=ingester"},
+			current:    []string{"-target=ingester"},
+			wantStatus: "no_drift",
+		},
+		{
+			name:       "value moved to the next argument",
+			baseline:   []string{"-target=ingester", "-ingester.max-global-series-per-user=150000"},
+			current:    []string{"-target", "ingester", "-ingester.max-global-series-per-user", "150000"},
+			wantStatus: "no_drift",
+		},
+		{
+			name:        "changed value",
+			baseline:    []string{"-ingester.max-global-series-per-user", "150000"},
+			current:     []string{"-ingester.max-global-series-per-user", "100000"},
+			wantStatus:  "drifted",
+			wantChanged: "containers.ingester.args.-ingester.max-global-series-per-user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := newTestDetector()
+
+			if status := detector.analyzeWorkloadDrift(deploymentSnapshot(ingesterDeployment(tt.baseline...), false)); status.Status != "new" {
+				t.Fatalf("first check status = %s, want new", status.Status)
+			}
+			status := detector.analyzeWorkloadDrift(deploymentSnapshot(ingesterDeployment(tt.current...), false))
+			if status.Status != tt.wantStatus {
+				t.Fatalf("status = %s with changes %+v, want %s", status.Status, status.Changes, tt.wantStatus)
+			}
+			if tt.wantChanged == "" {
+				if status.CurrentHash != status.BaselineHash {
+					t.Errorf("hash %s differs from baseline %s", status.CurrentHash, status.BaselineHash)
+				}
+				return
+			}
+			if len(status.Changes) != 1 || status.Changes[0].Key != tt.wantChanged || status.Changes[0].Type != "modified" {
+				t.Errorf("changes = %+v, want only %s modified", status.Changes, tt.wantChanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// criticalPathSettings are settings whose change can cause data loss or an outage
+var criticalPathSettings = []string{
+	"replication_factor", "backend", "bucket_name", "join_members",
+	"kvstore", "tenant_federation", "storage_prefix", "retention_period",
+}
+
+// analyzeYAMLChanges emits one change per YAML path that differs between two payloads.
+// It returns false when either payload is not a YAML mapping.
+func (d *Detector) analyzeYAMLChanges(key, oldValue, newValue string) ([]ConfigChange, bool) {
+	oldPaths, ok := flattenYAML(oldValue)
+	if !ok {
+		return nil, false
+	}
+	newPaths, ok := flattenYAML(newValue)
+	if !ok {
+		return nil, false
+	}
+
+	paths := make([]string, 0, len(oldPaths)+len(newPaths))
+	for path := range oldPaths {
+		paths = append(paths, path)
+	}
+	for path := range newPaths {
+		if _, exists := oldPaths[path]; !exists {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var changes []ConfigChange
+	for _, path := range paths {
+		oldPathValue, inOld := oldPaths[path]
+		newPathValue, inNew := newPaths[path]
+
+		change := ConfigChange{
+			Key:      fmt.Sprintf("data.%s", key),
+			Path:     path,
+			OldValue: oldPathValue,
+			NewValue: newPathValue,
+		}
+		switch {
+		case inOld && inNew:
+			if oldPathValue == newPathValue {
+				continue
+			}
+			change.Type = "modified"
+			change.Description = fmt.Sprintf("%s changed from %s to %s in '%s'", path, oldPathValue, newPathValue, key)
+		case inOld:
+			change.Type = "deleted"
+			change.Description = fmt.Sprintf("%s (was %s) was removed from '%s'", path, oldPathValue, key)
+		default:
+			change.Type = "added"
+			change.Description = fmt.Sprintf("%s was set to %s in '%s'", path, newPathValue, key)
+		}
+		change.Impact = d.assessDataChangeImpact(key, path, oldPathValue, newPathValue)
+		changes = append(changes, change)
+	}
+
+	if len(changes) == 0 {
+		// Only comments, ordering or formatting differ
+		changes = append(changes, ConfigChange{
+			Type:        "modified",
+			Key:         fmt.Sprintf("data.%s", key),
+			OldValue:    oldValue,
+			NewValue:    newValue,
+			Impact:      "low",
+			Description: fmt.Sprintf("Formatting of '%s' changed without changing any setting", key),
+		})
+	}
+
+	return changes, true
+}
+
+// isLimitReduction reports whether a numeric value was lowered.
+// A removed value is not a reduction: what applies instead is a default this diff does not know.
+func isLimitReduction(oldValue, newValue string) bool {
+	oldNumber, err := strconv.ParseFloat(oldValue, 64)
+	if err != nil {
+		return false
+	}
+	newNumber, err := strconv.ParseFloat(newValue, 64)
+	if err != nil {
+		return false
+	}
+	return newNumber < oldNumber
+}
+
+// flattenYAML parses a YAML mapping into dotted paths and scalar values
+func flattenYAML(content string) (map[string]string, bool) {
+	var document interface{}
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return nil, false
+	}
+	root, isMap := document.(map[interface{}]interface{})
+	if !isMap {
+		return nil, false
+	}
+
+	paths := make(map[string]string)
+	if len(root) > 0 {
+		// An empty document has no settings rather than an empty one at the root
+		flattenYAMLValue("", root, paths)
+	}
+	return paths, true
+}
+
+func flattenYAMLValue(prefix string, value interface{}, paths map[string]string) {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		if len(v) == 0 {
+			paths[prefix] = "{}"
+			return
+		}
+		for key, child := range v {
+			path := fmt.Sprintf("%v", key)
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+			flattenYAMLValue(path, child, paths)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			paths[prefix] = "[]"
+			return
+		}
+		for i, child := range v {
+			flattenYAMLValue(fmt.Sprintf("%s[%d]", prefix, i), child, paths)
+		}
+	case nil:
+		paths[prefix] = "null"
+	default:
+		paths[prefix] = fmt.Sprintf("%v", v)
+	}
+}
+
+// lastPathSegment returns the setting name at the end of a YAML path
+func lastPathSegment(path string) string {
+	if idx := strings.LastIndex(path, "["); idx > 0 && strings.HasSuffix(path, "]") {
+		path = path[:idx]
+	}
+	if idx := strings.LastIndex(path, "."); idx >= 0 {
+		return path[idx+1:]
+	}
+	return path
+}
@@ -0,0 +1,146 @@
+package drift
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlattenYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantOK  bool
+	}{
+		{
+			name: "nested overrides",
+			content: `overrides:
+  team-a:
+    ingestion_rate: 10000
+    max_global_series_per_user: 1.5e6
+  team-b: {}
+`,
+			want: map[string]string{
+				"overrides.team-a.ingestion_rate":             "10000",
+				"overrides.team-a.max_global_series_per_user": "1.5e+06",
+				"overrides.team-b":                            "{}",
+			},
+			wantOK: true,
+		},
+		{
+			name:    "lists, nulls and empty lists",
+			content: "memberlist:\n  join_members: [a, b]\n  abort_if_join_fails: ~\nalerts: []\n",
+			want: map[string]string{
+				"memberlist.join_members[0]":     "a",
+				"memberlist.join_members[1]":     "b",
+				"memberlist.abort_if_join_fails": "null",
+				"alerts":                         "[]",
+			},
+			wantOK: true,
+		},
+		{
+			name:    "list of maps",
+			content: "rules:\n  - name: a\n    for: 5m\n",
+			want:    map[string]string{"rules[0].name": "a", "rules[0].for": "5m"},
+			wantOK:  true,
+		},
+		{name: "empty mapping", content: "{}", want: map[string]string{}, wantOK: true},
+		{name: "scalar document", content: "just text"},
+		{name: "list document", content: "- a\n- b\n"},
+		{name: "empty document", content: ""},
+		{name: "invalid YAML", content: "a: [b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := flattenYAML(tt.content)
+			if ok != tt.wantOK {
+				t.Fatalf("flattenYAML() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flattenYAML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLimitReduction(t *testing.T) {
+	tests := []struct {
+		oldValue, newValue string
+		want               bool
+	}{
+		{oldValue: "10000", newValue: "5000", want: true},
+		{oldValue: "1e6", newValue: "999999", want: true},
+		{oldValue: "5000", newValue: "10000", want: false},
+		{oldValue: "5000", newValue: "5000", want: false},
+		{oldValue: "5000", newValue: "", want: false}, // Removed, the default applies
+		{oldValue: "", newValue: "5000", want: false},
+		{oldValue: "30d", newValue: "7d", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isLimitReduction(tt.oldValue, tt.newValue); got != tt.want {
+			t.Errorf("isLimitReduction(%q, %q) = %v, want %v", tt.oldValue, tt.newValue, got, tt.want)
+		}
+	}
+}
+
+func TestAssessDataChangeImpact(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		path     string
+		oldValue string
+		newValue string
+		want     string
+	}{
+		{name: "lowered limit", key: "overrides.yaml", path: "overrides.team-a.ingestion_rate", oldValue: "10000", newValue: "5000", want: "critical"},
+		{name: "raised limit", key: "overrides.yaml", path: "overrides.team-a.ingestion_rate", oldValue: "5000", newValue: "10000", want: "high"},
+		{name: "added limit", key: "overrides.yaml", path: "overrides.team-a.max_global_series_per_user", newValue: "150000", want: "high"},
+		{name: "removed limit", key: "overrides.yaml", path: "overrides.team-a.max_global_series_per_user", oldValue: "150000", want: "high"},
+		{name: "critical setting", key: "mimir.yaml", path: "ingester.ring.replication_factor", oldValue: "3", newValue: "2", want: "critical"},
+		{name: "removed critical setting", key: "mimir.yaml", path: "blocks_storage.backend", oldValue: "s3", want: "critical"},
+		{name: "critical setting in a list", key: "mimir.yaml", path: "memberlist.join_members[0]", oldValue: "a", newValue: "b", want: "critical"},
+		{name: "other setting", key: "mimir.yaml", path: "server.log_level", oldValue: "info", newValue: "debug", want: "medium"},
+		{name: "whole critical key", key: "runtime-config.yaml", oldValue: "a", newValue: "b", want: "critical"},
+		{name: "whole high impact key", key: "nginx.conf", oldValue: "a", newValue: "b", want: "high"},
+		{name: "whole limit key", key: "max_series", oldValue: "1", newValue: "2", want: "high"},
+		{name: "whole other key", key: "notes.txt", oldValue: "a", newValue: "b", want: "medium"},
+	}
+
+	detector := &Detector{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detector.assessDataChangeImpact(tt.key, tt.path, tt.oldValue, tt.newValue); got != tt.want {
+				t.Errorf("assessDataChangeImpact() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeYAMLChanges(t *testing.T) {
+	oldValue := "overrides:\n  team-a:\n    ingestion_rate: 10000\n    ingestion_burst_size: 200000\n"
+	newValue := "overrides:\n  team-a:\n    ingestion_rate: 5000 # lowered\n    max_label_names_per_series: 40\n"
+
+	changes, ok := (&Detector{}).analyzeYAMLChanges("overrides.yaml", oldValue, newValue)
+	if !ok {
+		t.Fatal("analyzeYAMLChanges() ok = false")
+	}
+
+	got := map[string][2]string{}
+	for _, change := range changes {
+		got[change.Path] = [2]string{change.Type, change.Impact}
+	}
+	want := map[string][2]string{
+		"overrides.team-a.ingestion_burst_size":       {"deleted", "high"},
+		"overrides.team-a.ingestion_rate":             {"modified", "critical"},
+		"overrides.team-a.max_label_names_per_series": {"added", "high"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+
+	if changes, _ := (&Detector{}).analyzeYAMLChanges("overrides.yaml", oldValue, "# reformatted\n"+oldValue); len(changes) != 1 || changes[0].Impact != "low" {
+		t.Errorf("formatting-only change = %v, want one low impact change", changes)
+	}
+}
@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/akshaydubey29/mimirInsights/pkg/config"
 	"github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -259,3 +263,18 @@ func (c *Client) GetClusterInfo(ctx context.Context) (map[string]interface{}, er
 func (c *Client) GetIngresses(ctx context.Context, namespace string, opts metav1.ListOptions) (*networkingv1.IngressList, error) {
 	return c.clientset.NetworkingV1().Ingresses(namespace).List(ctx, opts)
 }
+
+// GetHorizontalPodAutoscalers gets HorizontalPodAutoscalers in a namespace
+func (c *Client) GetHorizontalPodAutoscalers(ctx context.Context, namespace string, opts metav1.ListOptions) (*autoscalingv2.HorizontalPodAutoscalerList, error) {
+	return c.clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, opts)
+}
+
+// GetPodDisruptionBudgets gets PodDisruptionBudgets in a namespace
+func (c *Client) GetPodDisruptionBudgets(ctx context.Context, namespace string, opts metav1.ListOptions) (*policyv1.PodDisruptionBudgetList, error) {
+	return c.clientset.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, opts)
+}
+
+// NewInformerFactory creates a shared informer factory scoped to a namespace
+func (c *Client) NewInformerFactory(namespace string, resync time.Duration) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactoryWithOptions(c.clientset, resync, informers.WithNamespace(namespace))
+}
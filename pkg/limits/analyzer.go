@@ -20,6 +20,7 @@ type Analyzer struct {
 	metricsClient *metrics.Client
 	config        *config.Config
 	autoDiscovery *AutoDiscovery
+	history       *ChangeHistory
 }
 
 // LimitRecommendation represents a recommended limit value
@@ -108,6 +109,7 @@ func NewAnalyzer(metricsClient *metrics.Client) *Analyzer {
 		metricsClient: metricsClient,
 		config:        config.Get(),
 		autoDiscovery: NewAutoDiscovery(k8sClient),
+		history:       NewChangeHistory(),
 	}
 }
 
@@ -133,8 +135,11 @@ func (a *Analyzer) AnalyzeTenantLimits(ctx context.Context, tenantName string) (
 	var recommendations []LimitRecommendation
 	var missingLimits []string
 
+	usage := &usageLookup{fetch: func(ctx context.Context) (*metrics.TenantUsage, error) {
+		return a.metricsClient.GetTenantUsage(ctx, tenantName)
+	}}
 	for _, limitType := range limitTypes {
-		recommendation, err := a.analyzeLimit(ctx, tenantName, limitType, currentConfig)
+		recommendation, err := a.analyzeLimit(ctx, tenantName, limitType, currentConfig, usage)
 		if err != nil {
 			logrus.Warnf("Failed to analyze limit %s for %s: %v", limitType.Name, tenantName, err)
 			continue
@@ -227,7 +232,7 @@ func (a *Analyzer) AnalyzeTenantIntelligently(ctx context.Context, tenantName st
 }
 
 // analyzeLimit analyzes a specific limit for a tenant
-func (a *Analyzer) analyzeLimit(ctx context.Context, tenantName string, limitType LimitType, currentConfig map[string]interface{}) (*LimitRecommendation, error) {
+func (a *Analyzer) analyzeLimit(ctx context.Context, tenantName string, limitType LimitType, currentConfig map[string]interface{}, usage *usageLookup) (*LimitRecommendation, error) {
 	// Get current value
 	currentValue := a.getCurrentLimitValue(limitType.Name, currentConfig)
 	if currentValue == nil {
@@ -235,7 +240,7 @@ func (a *Analyzer) analyzeLimit(ctx context.Context, tenantName string, limitTyp
 	}
 
 	// Get observed peak from metrics
-	observedPeak, err := a.getObservedPeak(ctx, tenantName, limitType)
+	observedPeak, err := a.getObservedPeak(ctx, tenantName, limitType, usage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get observed peak: %w", err)
 	}
@@ -320,11 +325,31 @@ func (a *Analyzer) analyzeLimitIntelligently(ctx context.Context, tenantName str
 	return recommendation, nil
 }
 
+// usageLookup fetches a tenant's usage from Mimir's APIs at most once per analysis
+type usageLookup struct {
+	fetch   func(ctx context.Context) (*metrics.TenantUsage, error)
+	fetched bool
+	usage   *metrics.TenantUsage
+	err     error
+}
+
+func (l *usageLookup) get(ctx context.Context) (*metrics.TenantUsage, error) {
+	if !l.fetched {
+		l.usage, l.err = l.fetch(ctx)
+		l.fetched = true
+	}
+	return l.usage, l.err
+}
+
 // getObservedPeak gets the observed peak value for a limit type
-func (a *Analyzer) getObservedPeak(ctx context.Context, tenantName string, limitType LimitType) (float64, error) {
+func (a *Analyzer) getObservedPeak(ctx context.Context, tenantName string, limitType LimitType, usage *usageLookup) (float64, error) {
+	// Map limit type to metric
+	metricName := a.mapLimitToMetric(limitType.Name)
+
 	// Get metrics for different time ranges
 	timeRanges := metrics.GetStandardTimeRanges()
 	var maxPeak float64
+	found := false
 	for rangeName, timeRange := range timeRanges {
 		peakValues, err := a.metricsClient.GetPeakValues(ctx, tenantName, timeRange)
 		if err != nil {
@@ -332,16 +357,37 @@ func (a *Analyzer) getObservedPeak(ctx context.Context, tenantName string, limit
 			continue
 		}
 
-		// Map limit type to metric
-		metricName := a.mapLimitToMetric(limitType.Name)
 		if peakValue, exists := peakValues[metricName]; exists {
+			found = true
 			if peakValue > maxPeak {
 				maxPeak = peakValue
 			}
 		}
 	}
+	if found {
+		return maxPeak, nil
+	}
+
+	// Without self-monitoring metrics, fall back to the current usage reported by Mimir's APIs
+	if value, ok := usagePeak(ctx, metricName, usage); ok {
+		logrus.Infof("Using current %s as the observed peak of %s for %s", metricName, limitType.Name, tenantName)
+		return value, nil
+	}
+	return 0, nil
+}
 
-	return maxPeak, nil
+// usagePeak returns the current usage for the metric when Mimir's usage APIs report it.
+// They only cover ingestion rate and active series, so other metrics don't fetch the usage.
+func usagePeak(ctx context.Context, metricName string, usage *usageLookup) (float64, bool) {
+	if metricName != metrics.MetricIngestionRate && metricName != metrics.MetricActiveSeries {
+		return 0, false
+	}
+	current, err := usage.get(ctx)
+	if err != nil {
+		logrus.Debugf("No usage APIs available: %v", err)
+		return 0, false
+	}
+	return current.Value(metricName)
 }
 
 // analyzeTenantMetrics performs comprehensive metrics analysis
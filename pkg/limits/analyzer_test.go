@@ -0,0 +1,73 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/metrics"
+)
+
+func TestUsagePeak(t *testing.T) {
+	reported := &metrics.TenantUsage{
+		Tenant:        "team-a",
+		IngestionRate: 1000,
+		ActiveSeries:  5000,
+		Sources:       []string{metrics.UsageSourceUserStats},
+	}
+
+	tests := []struct {
+		name      string
+		usage     *metrics.TenantUsage
+		err       error
+		metrics   []string
+		want      []float64
+		wantOK    []bool
+		wantFetch int
+	}{
+		{
+			name:      "usage is fetched once for both metrics it reports",
+			usage:     reported,
+			metrics:   []string{metrics.MetricIngestionRate, metrics.MetricActiveSeries, metrics.MetricIngestionRate},
+			want:      []float64{1000, 5000, 1000},
+			wantOK:    []bool{true, true, true},
+			wantFetch: 1,
+		},
+		{
+			name:      "other metrics do not fetch the usage",
+			usage:     reported,
+			metrics:   []string{metrics.MetricMemoryUsage, metrics.MetricRejectedSamples, metrics.MetricLimitsReached},
+			want:      []float64{0, 0, 0},
+			wantOK:    []bool{false, false, false},
+			wantFetch: 0,
+		},
+		{
+			name:      "a failed fetch is not retried",
+			err:       errors.New("no usage reported"),
+			metrics:   []string{metrics.MetricIngestionRate, metrics.MetricActiveSeries},
+			want:      []float64{0, 0},
+			wantOK:    []bool{false, false},
+			wantFetch: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := 0
+			usage := &usageLookup{fetch: func(ctx context.Context) (*metrics.TenantUsage, error) {
+				fetches++
+				return tt.usage, tt.err
+			}}
+
+			for i, metricName := range tt.metrics {
+				got, ok := usagePeak(context.Background(), metricName, usage)
+				if got != tt.want[i] || ok != tt.wantOK[i] {
+					t.Errorf("usagePeak(%s) = %v, %v; want %v, %v", metricName, got, ok, tt.want[i], tt.wantOK[i])
+				}
+			}
+			if fetches != tt.wantFetch {
+				t.Errorf("usage fetched %d times, want %d", fetches, tt.wantFetch)
+			}
+		})
+	}
+}
@@ -11,12 +11,14 @@ import (
 	"github.com/akshaydubey29/mimirInsights/pkg/k8s"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // AutoDiscovery handles automatic discovery of limits from Mimir configurations
 type AutoDiscovery struct {
-	k8sClient *k8s.Client
+	k8sClient    *k8s.Client
+	expectChange ExpectChangeFunc
 }
 
 // DiscoveredLimits represents auto-discovered limit configurations
@@ -88,47 +90,58 @@ func (ad *AutoDiscovery) DiscoverAllLimits(ctx context.Context, mimirNamespace s
 	return discovered, nil
 }
 
+// runtimeOverrideNames lists the common names used for runtime override ConfigMaps
+var runtimeOverrideNames = []string{
+	"runtime-overrides",
+	"mimir-runtime-overrides",
+	"cortex-runtime-overrides",
+	"overrides",
+	"mimir-overrides",
+}
+
 // discoverRuntimeOverrides discovers limits from runtime override ConfigMaps
 func (ad *AutoDiscovery) discoverRuntimeOverrides(ctx context.Context, namespace string, discovered *DiscoveredLimits) error {
-	// Try multiple common names for runtime overrides
-	overrideNames := []string{
-		"runtime-overrides",
-		"mimir-runtime-overrides",
-		"cortex-runtime-overrides",
-		"overrides",
-		"mimir-overrides",
+	configMap, err := ad.findRuntimeOverrides(ctx, namespace)
+	if err != nil {
+		return nil // No runtime overrides in this namespace
 	}
 
-	for _, name := range overrideNames {
-		configMap, err := ad.k8sClient.GetConfigMap(ctx, namespace, name, metav1.GetOptions{})
-		if err != nil {
-			continue // Try next name
-		}
+	logrus.Infof("Found runtime overrides ConfigMap: %s", configMap.Name)
 
-		logrus.Infof("Found runtime overrides ConfigMap: %s", name)
-
-		// Add to config sources
-		discovered.ConfigSources = append(discovered.ConfigSources, ConfigSource{
-			Name:      name,
-			Namespace: namespace,
-			Type:      "runtime-override",
-			Keys:      getConfigMapKeys(configMap.Data),
-			LastSeen:  time.Now(),
-		})
+	// Add to config sources
+	discovered.ConfigSources = append(discovered.ConfigSources, ConfigSource{
+		Name:      configMap.Name,
+		Namespace: namespace,
+		Type:      "runtime-override",
+		Keys:      getConfigMapKeys(configMap.Data),
+		LastSeen:  time.Now(),
+	})
 
-		// Parse the overrides
-		for key, value := range configMap.Data {
-			if strings.HasSuffix(key, ".yaml") || strings.HasSuffix(key, ".yml") {
-				ad.parseOverridesYAML(value, discovered)
-			}
+	// Parse the overrides
+	for key, value := range configMap.Data {
+		if isYAMLKey(key) {
+			ad.parseOverridesYAML(value, discovered)
 		}
-
-		break // Stop after finding the first one
 	}
 
 	return nil
 }
 
+// findRuntimeOverrides returns the first runtime overrides ConfigMap found in the namespace
+func (ad *AutoDiscovery) findRuntimeOverrides(ctx context.Context, namespace string) (*corev1.ConfigMap, error) {
+	// Try multiple common names for runtime overrides
+	for _, name := range runtimeOverrideNames {
+		configMap, err := ad.k8sClient.GetConfigMap(ctx, namespace, name, metav1.GetOptions{})
+		if err != nil {
+			continue // Try next name
+		}
+		return configMap, nil
+	}
+
+	return nil, fmt.Errorf("%w in namespace %s (tried %s)",
+		ErrOverridesNotFound, namespace, strings.Join(runtimeOverrideNames, ", "))
+}
+
 // discoverMimirConfig discovers limits from main Mimir configuration
 func (ad *AutoDiscovery) discoverMimirConfig(ctx context.Context, namespace string, discovered *DiscoveredLimits) error {
 	configNames := []string{
@@ -585,6 +598,10 @@ func (ad *AutoDiscovery) extractLimitsFromSection(section map[interface{}]interf
 	}
 }
 
+func isYAMLKey(key string) bool {
+	return strings.HasSuffix(key, ".yaml") || strings.HasSuffix(key, ".yml")
+}
+
 func getConfigMapKeys(data map[string]string) []string {
 	keys := make([]string, 0, len(data))
 	for key := range data {
@@ -0,0 +1,255 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrEmptySelector is returned when a selector without criteria is used without asking for all recommendations
+var ErrEmptySelector = errors.New("recommendation selector has no criteria; set all to apply every recommendation")
+
+// RecommendationSelector selects which recommendations to apply; empty fields match everything.
+// A selector without any criteria is only valid with All set, so a bare {} cannot rewrite every tenant.
+type RecommendationSelector struct {
+	Tenants    []string `json:"tenants"`
+	Priorities []string `json:"priorities"`
+	RiskLevels []string `json:"risk_levels"`
+	Categories []string `json:"categories"`
+	LimitNames []string `json:"limit_names"`
+	All        bool     `json:"all"`
+}
+
+// BulkApplyItem represents the outcome for a single recommendation
+type BulkApplyItem struct {
+	TenantID         string      `json:"tenant_id"`
+	LimitName        string      `json:"limit_name"`
+	Category         string      `json:"category"`
+	Priority         string      `json:"priority"`
+	RiskLevel        string      `json:"risk_level"`
+	CurrentValue     interface{} `json:"current_value"`
+	RecommendedValue interface{} `json:"recommended_value"`
+	Status           string      `json:"status"` // "applied", "planned", "skipped", "failed"
+	Message          string      `json:"message,omitempty"`
+	ChangeID         string      `json:"change_id,omitempty"`
+}
+
+// BulkApplyResult represents the outcome of applying a selection of recommendations
+type BulkApplyResult struct {
+	DryRun          bool            `json:"dry_run"`
+	Items           []BulkApplyItem `json:"items"`
+	Applied         int             `json:"applied"`
+	Skipped         int             `json:"skipped"`
+	Failed          int             `json:"failed"`
+	ConfigMap       string          `json:"config_map,omitempty"`
+	Namespace       string          `json:"namespace,omitempty"`
+	ResourceVersion string          `json:"resource_version,omitempty"`
+	Diff            string          `json:"diff,omitempty"`
+	Timestamp       time.Time       `json:"timestamp"`
+}
+
+// Validate rejects a selector that would match everything unless All is set
+func (sel RecommendationSelector) Validate() error {
+	if sel.All {
+		return nil
+	}
+	if len(sel.Tenants)+len(sel.Priorities)+len(sel.RiskLevels)+len(sel.Categories)+len(sel.LimitNames) == 0 {
+		return ErrEmptySelector
+	}
+	return nil
+}
+
+// Matches reports whether a recommendation for the tenant is selected
+func (sel RecommendationSelector) Matches(tenantName string, rec IntelligentLimitRecommendation) bool {
+	return matchesAny(sel.Tenants, tenantName) &&
+		matchesAny(sel.Priorities, rec.Priority) &&
+		matchesAny(sel.RiskLevels, rec.RiskLevel) &&
+		matchesAny(sel.Categories, rec.Category) &&
+		matchesAny(sel.LimitNames, rec.LimitName)
+}
+
+// ApplyRecommendations applies the selected recommendations as one batched runtime overrides update
+func (a *Analyzer) ApplyRecommendations(ctx context.Context, tenantNames []string, selector RecommendationSelector, reason, user string, dryRun bool) (*BulkApplyResult, error) {
+	if err := selector.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &BulkApplyResult{
+		DryRun:    dryRun,
+		Items:     []BulkApplyItem{},
+		Timestamp: time.Now(),
+	}
+
+	var changes []LimitChange
+	var pending []int // Indexes into result.Items for items included in the batch
+
+	for _, tenantName := range tenantNames {
+		if !matchesAny(selector.Tenants, tenantName) {
+			continue
+		}
+
+		analysis, err := a.AnalyzeTenantIntelligently(ctx, tenantName)
+		if err != nil {
+			result.Items = append(result.Items, BulkApplyItem{
+				TenantID: tenantName,
+				Status:   "failed",
+				Message:  fmt.Sprintf("failed to analyze tenant: %v", err),
+			})
+			continue
+		}
+
+		for _, rec := range analysis.Recommendations {
+			if !selector.Matches(tenantName, rec) {
+				continue
+			}
+
+			item := BulkApplyItem{
+				TenantID:         tenantName,
+				LimitName:        rec.LimitName,
+				Category:         rec.Category,
+				Priority:         rec.Priority,
+				RiskLevel:        rec.RiskLevel,
+				CurrentValue:     rec.CurrentValue,
+				RecommendedValue: a.roundRecommendedValue(rec),
+			}
+
+			if skip := a.skipReason(rec, item.RecommendedValue); skip != "" {
+				item.Status = "skipped"
+				item.Message = skip
+				result.Items = append(result.Items, item)
+				continue
+			}
+
+			if validation := a.ValidateLimitValue(rec.LimitName, item.RecommendedValue); !validation.Valid {
+				item.Status = "failed"
+				item.Message = strings.Join(validation.Errors, "; ")
+				result.Items = append(result.Items, item)
+				continue
+			}
+
+			changes = append(changes, LimitChange{
+				TenantID:  tenantName,
+				LimitName: rec.LimitName,
+				NewValue:  item.RecommendedValue,
+			})
+			pending = append(pending, len(result.Items))
+			result.Items = append(result.Items, item)
+		}
+	}
+
+	if len(changes) > 0 {
+		a.applyBatch(ctx, changes, pending, reason, user, result)
+	}
+
+	for _, item := range result.Items {
+		switch item.Status {
+		case "applied", "planned":
+			result.Applied++
+		case "skipped":
+			result.Skipped++
+		case "failed":
+			result.Failed++
+		}
+	}
+
+	logrus.Infof("Bulk recommendation apply (dry run: %v): %d applied, %d skipped, %d failed",
+		dryRun, result.Applied, result.Skipped, result.Failed)
+
+	return result, nil
+}
+
+// applyBatch writes (or previews) all pending changes in a single ConfigMap update
+func (a *Analyzer) applyBatch(ctx context.Context, changes []LimitChange, pending []int, reason, user string, result *BulkApplyResult) {
+	namespace := a.config.Mimir.Namespace
+
+	if result.DryRun {
+		preview, err := a.autoDiscovery.PreviewTenantOverrides(ctx, namespace, changes)
+		if err != nil {
+			markBatchFailed(result, pending, err)
+			return
+		}
+		result.ConfigMap = preview.ConfigMap
+		result.Namespace = preview.Namespace
+		result.ResourceVersion = preview.ResourceVersion
+		result.Diff = preview.Diff
+		for i, idx := range pending {
+			result.Items[idx].Status = "planned"
+			result.Items[idx].CurrentValue = preview.Changes[i].OldValue
+		}
+		return
+	}
+
+	update, err := a.autoDiscovery.UpdateTenantOverrides(ctx, namespace, changes)
+	if err != nil {
+		markBatchFailed(result, pending, err)
+		return
+	}
+
+	if reason == "" {
+		reason = "Applied limit recommendation"
+	}
+	a.history.RecordResult(update, reason, user, "")
+
+	result.ConfigMap = update.ConfigMap
+	result.Namespace = update.Namespace
+	result.ResourceVersion = update.ResourceVersion
+	for i, idx := range pending {
+		result.Items[idx].Status = "applied"
+		result.Items[idx].ChangeID = update.Changes[i].ChangeID
+		result.Items[idx].CurrentValue = update.Changes[i].OldValue
+	}
+}
+
+// roundRecommendedValue turns a computed recommendation into a value Mimir accepts
+func (a *Analyzer) roundRecommendedValue(rec IntelligentLimitRecommendation) interface{} {
+	value, ok := numericValue(rec.RecommendedValue)
+	if !ok {
+		return rec.RecommendedValue
+	}
+
+	for _, limitType := range a.getLimitTypes() {
+		if limitType.Name == rec.LimitName && limitType.Unit == "samples/sec" {
+			return math.Ceil(value*100) / 100
+		}
+	}
+	return int64(math.Ceil(value))
+}
+
+// skipReason explains why a recommendation should not be applied, or returns ""
+func (a *Analyzer) skipReason(rec IntelligentLimitRecommendation, recommended interface{}) string {
+	value, ok := numericValue(recommended)
+	if !ok {
+		return "recommendation has no numeric value"
+	}
+	if value <= 0 {
+		return "no usage data to base a recommendation on"
+	}
+	if rec.CurrentValue != nil && a.convertToFloat(rec.CurrentValue) == value {
+		return "limit is already at the recommended value"
+	}
+	return ""
+}
+
+func markBatchFailed(result *BulkApplyResult, pending []int, err error) {
+	for _, idx := range pending {
+		result.Items[idx].Status = "failed"
+		result.Items[idx].Message = fmt.Sprintf("batched overrides update failed: %v", err)
+	}
+}
+
+func matchesAny(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, candidate := range allowed {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package limits
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRecommendationSelectorValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector RecommendationSelector
+		wantErr  bool
+	}{
+		{name: "empty", selector: RecommendationSelector{}, wantErr: true},
+		{name: "empty lists", selector: RecommendationSelector{Tenants: []string{}, LimitNames: []string{}}, wantErr: true},
+		{name: "explicit all", selector: RecommendationSelector{All: true}},
+		{name: "tenants", selector: RecommendationSelector{Tenants: []string{"team-a"}}},
+		{name: "priorities", selector: RecommendationSelector{Priorities: []string{"high"}}},
+		{name: "risk levels", selector: RecommendationSelector{RiskLevels: []string{"low"}}},
+		{name: "categories", selector: RecommendationSelector{Categories: []string{"ingestion"}}},
+		{name: "limit names", selector: RecommendationSelector{LimitNames: []string{"ingestion_rate"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.selector.Validate()
+			if tt.wantErr != errors.Is(err, ErrEmptySelector) || (!tt.wantErr && err != nil) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecommendationSelectorMatches(t *testing.T) {
+	rec := IntelligentLimitRecommendation{LimitName: "ingestion_rate", Priority: "high", RiskLevel: "low", Category: "ingestion"}
+
+	tests := []struct {
+		name     string
+		selector RecommendationSelector
+		tenant   string
+		want     bool
+	}{
+		{name: "all", selector: RecommendationSelector{All: true}, tenant: "team-a", want: true},
+		{name: "tenant is case-insensitive", selector: RecommendationSelector{Tenants: []string{"Team-A"}}, tenant: "team-a", want: true},
+		{name: "other tenant", selector: RecommendationSelector{Tenants: []string{"team-b"}}, tenant: "team-a", want: false},
+		{name: "every criterion must match", selector: RecommendationSelector{Priorities: []string{"high"}, RiskLevels: []string{"high"}}, tenant: "team-a", want: false},
+		{name: "any listed value matches", selector: RecommendationSelector{LimitNames: []string{"max_global_series_per_user", "ingestion_rate"}}, tenant: "team-a", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.selector.Matches(tt.tenant, rec); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
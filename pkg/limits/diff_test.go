@@ -0,0 +1,128 @@
+package limits
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "identical",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   "",
+		},
+		{
+			name:   "single line change",
+			before: "a\nb\nc\n",
+			after:  "a\nB\nc\n",
+			want: `--- before
++++ after
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+`,
+		},
+		{
+			name:   "addition to empty text",
+			before: "",
+			after:  "a\nb\n",
+			want: `--- before
++++ after
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			name:   "removal of everything",
+			before: "a\n",
+			after:  "",
+			want: `--- before
++++ after
+@@ -1,1 +0,0 @@
+-a
+`,
+		},
+		{
+			name:   "distant changes get separate hunks",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			after:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want: `--- before
++++ after
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++twelve
+`,
+		},
+		{
+			name:   "close changes share a hunk",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n",
+			after:  "one\n2\n3\n4\n5\n6\n7\neight\n",
+			want: `--- before
++++ after
+@@ -1,8 +1,8 @@
+-1
++one
+ 2
+ 3
+ 4
+ 5
+ 6
+ 7
+-8
++eight
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("before", "after", tt.before, tt.after); got != tt.want {
+				t.Errorf("unifiedDiff() mismatch\n got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeDocument(t *testing.T) {
+	// A full LCS table for these documents would hold 2.5 billion entries
+	const lines = 50000
+	before := make([]string, lines)
+	for i := range before {
+		before[i] = fmt.Sprintf("    tenant_%d: %d", i, i)
+	}
+	after := append([]string(nil), before...)
+	after[lines/2] = "    changed: 1"
+
+	ops := diffLines(before, after)
+	if len(ops) != lines+1 {
+		t.Fatalf("diffLines() returned %d ops, want %d", len(ops), lines+1)
+	}
+	changed := ""
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changed += string(op.kind) + op.line + "\n"
+		}
+	}
+	if want := "-" + before[lines/2] + "\n+" + after[lines/2] + "\n"; changed != want {
+		t.Errorf("diffLines() changes = %q, want %q", changed, want)
+	}
+}
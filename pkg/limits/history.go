@@ -0,0 +1,144 @@
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// LimitChangeRecord represents a limit change applied to the runtime overrides
+type LimitChangeRecord struct {
+	ID              string      `json:"id"`
+	TenantID        string      `json:"tenant_id"`
+	LimitName       string      `json:"limit_name"`
+	OldValue        interface{} `json:"old_value"`
+	NewValue        interface{} `json:"new_value"`
+	Reason          string      `json:"reason"`
+	User            string      `json:"user"`
+	ConfigMap       string      `json:"config_map"`
+	Namespace       string      `json:"namespace"`
+	ResourceVersion string      `json:"resource_version"`
+	Timestamp       time.Time   `json:"timestamp"`
+	RollbackOf      string      `json:"rollback_of,omitempty"`
+	RolledBackBy    string      `json:"rolled_back_by,omitempty"`
+}
+
+// ChangeHistory keeps track of applied limit changes in persistent storage
+type ChangeHistory struct {
+	store storage.Store
+	mutex sync.Mutex
+}
+
+var changeSequence uint64
+
+// NewChangeHistory creates a new change history backed by the global store
+func NewChangeHistory() *ChangeHistory {
+	return &ChangeHistory{store: storage.Get()}
+}
+
+// RecordResult stores one record per change in an applied update and returns them
+func (h *ChangeHistory) RecordResult(result *LimitUpdateResult, reason, user, rollbackOf string) []*LimitChangeRecord {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	records := make([]*LimitChangeRecord, 0, len(result.Changes))
+	for i, change := range result.Changes {
+		record := &LimitChangeRecord{
+			ID:              fmt.Sprintf("chg_%d_%d", result.UpdatedAt.Unix(), atomic.AddUint64(&changeSequence, 1)),
+			TenantID:        change.TenantID,
+			LimitName:       change.LimitName,
+			OldValue:        change.OldValue,
+			NewValue:        change.NewValue,
+			Reason:          reason,
+			User:            user,
+			ConfigMap:       result.ConfigMap,
+			Namespace:       result.Namespace,
+			ResourceVersion: result.ResourceVersion,
+			Timestamp:       result.UpdatedAt,
+			RollbackOf:      rollbackOf,
+		}
+		if err := storage.PutJSON(h.store, storage.BucketLimitChanges, record.ID, record); err != nil {
+			logrus.Errorf("Failed to persist limit change %s: %v", record.ID, err)
+		}
+		result.Changes[i].ChangeID = record.ID
+		records = append(records, record)
+	}
+	return records
+}
+
+// GetChange retrieves a change record by ID
+func (h *ChangeHistory) GetChange(id string) *LimitChangeRecord {
+	var record LimitChangeRecord
+	if err := storage.GetJSON(h.store, storage.BucketLimitChanges, id, &record); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			logrus.Errorf("Failed to load limit change %s: %v", id, err)
+		}
+		return nil
+	}
+	record.restoreValues()
+	return &record
+}
+
+// GetTenantHistory returns the changes for a tenant, newest first
+func (h *ChangeHistory) GetTenantHistory(tenantID, limitName string) []*LimitChangeRecord {
+	var result []*LimitChangeRecord
+	err := h.store.ForEach(storage.BucketLimitChanges, func(key string, value []byte) error {
+		var record LimitChangeRecord
+		if err := storage.DecodeJSON(value, &record); err != nil {
+			logrus.Warnf("Skipping malformed limit change %s: %v", key, err)
+			return nil
+		}
+		if record.TenantID != tenantID {
+			return nil
+		}
+		if limitName != "" && record.LimitName != limitName {
+			return nil
+		}
+		record.restoreValues()
+		result = append(result, &record)
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to load limit history for tenant %s: %v", tenantID, err)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp.After(result[j].Timestamp)
+	})
+	return result
+}
+
+// MarkRolledBack links a change to the change that reverted it
+func (h *ChangeHistory) MarkRolledBack(id, rollbackID string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	record := h.GetChange(id)
+	if record == nil {
+		return
+	}
+	record.RolledBackBy = rollbackID
+	if err := storage.PutJSON(h.store, storage.BucketLimitChanges, id, record); err != nil {
+		logrus.Errorf("Failed to persist rollback of limit change %s: %v", id, err)
+	}
+}
+
+// restoreValues undoes the JSON decoding of integer limits as floats
+func (r *LimitChangeRecord) restoreValues() {
+	if r.OldValue != nil {
+		if value, err := normalizeLimitValue(r.OldValue); err == nil {
+			r.OldValue = value
+		}
+	}
+	if r.NewValue != nil {
+		if value, err := normalizeLimitValue(r.NewValue); err == nil {
+			r.NewValue = value
+		}
+	}
+}
@@ -0,0 +1,589 @@
+package limits
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	// ErrChangeNotFound is returned when a recorded limit change does not exist
+	ErrChangeNotFound = errors.New("limit change not found")
+	// ErrChangeConflict is returned when a limit change can no longer be applied as recorded
+	ErrChangeConflict = errors.New("limit change conflict")
+	// ErrOverridesNotFound is returned when the Mimir namespace has no runtime overrides ConfigMap
+	ErrOverridesNotFound = errors.New("no runtime overrides ConfigMap found")
+	// ErrInvalidLimitChange is returned for limit changes that fail validation
+	ErrInvalidLimitChange = errors.New("invalid limit change")
+)
+
+// defaultOverridesKey is used when the runtime overrides ConfigMap has no YAML data key yet
+const defaultOverridesKey = "overrides.yaml"
+
+// ExpectChangeFunc is told about the content of a runtime overrides data key before it is written,
+// e.g. so drift detection does not report the change; the returned function withdraws it when the write fails
+type ExpectChangeFunc func(namespace, configMap, dataKey, content string) func()
+
+// LimitChange represents a single tenant limit change in the runtime overrides
+type LimitChange struct {
+	ChangeID  string      `json:"change_id,omitempty"`
+	TenantID  string      `json:"tenant_id"`
+	LimitName string      `json:"limit_name"`
+	Path      string      `json:"path,omitempty"`
+	Action    string      `json:"action,omitempty"` // "added", "modified", "removed", "unchanged"
+	OldValue  interface{} `json:"old_value"`
+	NewValue  interface{} `json:"new_value"`
+	Remove    bool        `json:"remove,omitempty"`
+
+	CheckOldValue bool `json:"-"` // Apply only while the limit still has OldValue, e.g. for approved changes
+}
+
+// LimitUpdateResult represents the outcome of a runtime overrides update
+type LimitUpdateResult struct {
+	ConfigMap       string        `json:"config_map"`
+	Namespace       string        `json:"namespace"`
+	DataKey         string        `json:"data_key"`
+	Changes         []LimitChange `json:"changes"`
+	ResourceVersion string        `json:"resource_version"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// LimitUpdatePreview represents a dry-run of a runtime overrides update
+type LimitUpdatePreview struct {
+	ConfigMap       string                            `json:"config_map"`
+	Namespace       string                            `json:"namespace"`
+	DataKey         string                            `json:"data_key"`
+	ResourceVersion string                            `json:"resource_version"`
+	Changes         []LimitChange                     `json:"changes"`
+	CurrentLimits   map[string]map[string]interface{} `json:"current_limits"`
+	Diff            string                            `json:"diff"`
+	Validation      []*LimitValidation                `json:"validation,omitempty"`
+	Valid           bool                              `json:"valid"`
+}
+
+// overridesPlan holds a rendered, not yet applied, runtime overrides change
+type overridesPlan struct {
+	configMap *corev1.ConfigMap
+	dataKey   string
+	before    string
+	after     string
+	changes   []LimitChange
+}
+
+// planOverrideChanges renders the runtime overrides YAML with the requested changes applied
+func (ad *AutoDiscovery) planOverrideChanges(ctx context.Context, namespace string, changes []LimitChange) (*overridesPlan, error) {
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: no limit changes requested", ErrInvalidLimitChange)
+	}
+
+	configMap, err := ad.findRuntimeOverrides(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := selectOverridesKey(configMap.Data)
+	before := configMap.Data[dataKey]
+	document, err := parseOverridesDocument(before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s in ConfigMap %s/%s: %w", dataKey, namespace, configMap.Name, err)
+	}
+
+	planned := make([]LimitChange, 0, len(changes))
+	for _, change := range changes {
+		if change.TenantID == "" || change.LimitName == "" {
+			return nil, fmt.Errorf("%w: tenant and limit name are required", ErrInvalidLimitChange)
+		}
+
+		var newValue, oldValue interface{}
+		var err error
+		action := "modified"
+
+		if change.Remove {
+			oldValue, err = removeTenantOverride(document, change.TenantID, change.LimitName)
+			if err != nil {
+				return nil, err
+			}
+			action = "removed"
+			if oldValue == nil {
+				action = "unchanged"
+			}
+		} else {
+			newValue, err = normalizeLimitValue(change.NewValue)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid value for %s: %v", ErrInvalidLimitChange, change.LimitName, err)
+			}
+
+			oldValue, err = setTenantOverride(document, change.TenantID, change.LimitName, newValue)
+			if err != nil {
+				return nil, err
+			}
+
+			if oldValue == nil {
+				action = "added"
+			} else if fmt.Sprintf("%v", oldValue) == fmt.Sprintf("%v", newValue) {
+				action = "unchanged"
+			}
+		}
+
+		if change.CheckOldValue && !sameLimitValue(oldValue, change.OldValue) {
+			return nil, fmt.Errorf("%w: %s of tenant %s is now %v, not %v",
+				ErrChangeConflict, change.LimitName, change.TenantID, formatLimitValue(oldValue), formatLimitValue(change.OldValue))
+		}
+
+		planned = append(planned, LimitChange{
+			TenantID:  change.TenantID,
+			LimitName: change.LimitName,
+			Path:      fmt.Sprintf("overrides.%s.%s", change.TenantID, change.LimitName),
+			Action:    action,
+			OldValue:  oldValue,
+			NewValue:  newValue,
+			Remove:    change.Remove,
+		})
+	}
+
+	// Leave the document byte for byte alone when nothing changes
+	after := before
+	for _, change := range planned {
+		if change.Action != "unchanged" {
+			if after, err = renderOverridesDocument(document, before); err != nil {
+				return nil, fmt.Errorf("failed to render overrides YAML: %w", err)
+			}
+			break
+		}
+	}
+
+	return &overridesPlan{
+		configMap: configMap,
+		dataKey:   dataKey,
+		before:    before,
+		after:     after,
+		changes:   planned,
+	}, nil
+}
+
+// PreviewTenantOverrides computes the diff a runtime overrides update would produce without writing it
+func (ad *AutoDiscovery) PreviewTenantOverrides(ctx context.Context, namespace string, changes []LimitChange) (*LimitUpdatePreview, error) {
+	plan, err := ad.planOverrideChanges(ctx, namespace, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the current state the same way auto-discovery does
+	current := &DiscoveredLimits{
+		GlobalLimits: make(map[string]interface{}),
+		TenantLimits: make(map[string]TenantLimit),
+	}
+	ad.parseOverridesYAML(plan.before, current)
+
+	currentLimits := make(map[string]map[string]interface{})
+	for _, change := range plan.changes {
+		if tenantLimit, exists := current.TenantLimits[change.TenantID]; exists {
+			currentLimits[change.TenantID] = tenantLimit.Limits
+		} else {
+			currentLimits[change.TenantID] = map[string]interface{}{}
+		}
+	}
+
+	name := fmt.Sprintf("%s/%s/%s", namespace, plan.configMap.Name, plan.dataKey)
+	return &LimitUpdatePreview{
+		ConfigMap:       plan.configMap.Name,
+		Namespace:       namespace,
+		DataKey:         plan.dataKey,
+		ResourceVersion: plan.configMap.ResourceVersion,
+		Changes:         plan.changes,
+		CurrentLimits:   currentLimits,
+		Diff:            unifiedDiff("a/"+name, "b/"+name, plan.before, plan.after),
+		Valid:           true,
+	}, nil
+}
+
+// UpdateTenantOverrides rewrites tenant entries under `overrides:` in the runtime overrides ConfigMap
+func (ad *AutoDiscovery) UpdateTenantOverrides(ctx context.Context, namespace string, changes []LimitChange) (*LimitUpdateResult, error) {
+	plan, err := ad.planOverrideChanges(ctx, namespace, changes)
+	if err != nil {
+		return nil, err
+	}
+	configMap := plan.configMap
+
+	// Pin the resourceVersion we read so concurrent edits fail instead of being overwritten
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": configMap.ResourceVersion,
+		},
+		"data": map[string]string{
+			plan.dataKey: plan.after,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ConfigMap patch: %w", err)
+	}
+
+	withdraw := func() {}
+	if ad.expectChange != nil {
+		withdraw = ad.expectChange(namespace, configMap.Name, plan.dataKey, plan.after)
+	}
+	updated, err := ad.k8sClient.PatchConfigMap(ctx, namespace, configMap.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		withdraw()
+		return nil, fmt.Errorf("failed to patch ConfigMap %s/%s: %w", namespace, configMap.Name, err)
+	}
+
+	logrus.Infof("Updated %d tenant limit(s) in runtime overrides %s/%s (resourceVersion %s)",
+		len(plan.changes), namespace, configMap.Name, updated.ResourceVersion)
+
+	return &LimitUpdateResult{
+		ConfigMap:       configMap.Name,
+		Namespace:       namespace,
+		DataKey:         plan.dataKey,
+		Changes:         plan.changes,
+		ResourceVersion: updated.ResourceVersion,
+		UpdatedAt:       time.Now(),
+	}, nil
+}
+
+// ManagesConfigMap reports whether the ConfigMap is one UpdateTenantOverrides writes
+func (a *Analyzer) ManagesConfigMap(namespace, name string) bool {
+	return namespace == a.config.Mimir.Namespace && slices.Contains(runtimeOverrideNames, name)
+}
+
+// SetExpectChange registers the function told about runtime overrides writes before they happen
+func (a *Analyzer) SetExpectChange(expectChange ExpectChangeFunc) {
+	a.autoDiscovery.expectChange = expectChange
+}
+
+// UpdateTenantLimit sets a single limit for a tenant in the runtime overrides and records it in the history
+func (a *Analyzer) UpdateTenantLimit(ctx context.Context, tenantName, limitName string, newValue interface{}, reason, user string) (*LimitUpdateResult, error) {
+	return a.ApplyLimitChanges(ctx, []LimitChange{
+		{TenantID: tenantName, LimitName: limitName, NewValue: newValue},
+	}, reason, user)
+}
+
+// ApplyLimitChanges validates limit changes, writes them in one runtime overrides update and records them in the history
+func (a *Analyzer) ApplyLimitChanges(ctx context.Context, changes []LimitChange, reason, user string) (*LimitUpdateResult, error) {
+	for _, change := range changes {
+		if change.Remove {
+			continue
+		}
+		if validation := a.ValidateLimitValue(change.LimitName, change.NewValue); !validation.Valid {
+			return nil, fmt.Errorf("%w: invalid value for %s: %s", ErrInvalidLimitChange, change.LimitName, strings.Join(validation.Errors, "; "))
+		}
+	}
+
+	result, err := a.autoDiscovery.UpdateTenantOverrides(ctx, a.config.Mimir.Namespace, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	a.history.RecordResult(result, reason, user, "")
+	return result, nil
+}
+
+// GetLimitHistory returns the recorded limit changes for a tenant, newest first
+func (a *Analyzer) GetLimitHistory(tenantName, limitName string) []*LimitChangeRecord {
+	return a.history.GetTenantHistory(tenantName, limitName)
+}
+
+// RollbackLimitChange restores the value a recorded change replaced
+func (a *Analyzer) RollbackLimitChange(ctx context.Context, tenantName, changeID, reason, user string, force bool) (*LimitUpdateResult, error) {
+	restore, err := a.rollbackChange(tenantName, changeID, force)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.autoDiscovery.UpdateTenantOverrides(ctx, a.config.Mimir.Namespace, []LimitChange{restore})
+	if err != nil {
+		return nil, err
+	}
+
+	if reason == "" {
+		reason = fmt.Sprintf("Rollback of change %s", changeID)
+	}
+	records := a.history.RecordResult(result, reason, user, changeID)
+	a.history.MarkRolledBack(changeID, records[0].ID)
+
+	return result, nil
+}
+
+// PreviewRollback renders the diff of rolling back a recorded change without applying it
+func (a *Analyzer) PreviewRollback(ctx context.Context, tenantName, changeID string, force bool) (*LimitUpdatePreview, error) {
+	restore, err := a.rollbackChange(tenantName, changeID, force)
+	if err != nil {
+		return nil, err
+	}
+	return a.autoDiscovery.PreviewTenantOverrides(ctx, a.config.Mimir.Namespace, []LimitChange{restore})
+}
+
+// rollbackChange returns the change restoring the value a recorded change replaced
+func (a *Analyzer) rollbackChange(tenantName, changeID string, force bool) (LimitChange, error) {
+	record := a.history.GetChange(changeID)
+	if record == nil || record.TenantID != tenantName {
+		return LimitChange{}, fmt.Errorf("%w: %s", ErrChangeNotFound, changeID)
+	}
+	if record.RolledBackBy != "" {
+		return LimitChange{}, fmt.Errorf("%w: change %s was already rolled back by %s", ErrChangeConflict, changeID, record.RolledBackBy)
+	}
+
+	return LimitChange{
+		TenantID:  record.TenantID,
+		LimitName: record.LimitName,
+		NewValue:  record.OldValue,
+		Remove:    record.OldValue == nil, // The change added the limit, so rolling back removes it
+		// Refuse to clobber a value someone changed after this record unless forced
+		OldValue:      record.NewValue,
+		CheckOldValue: !force,
+	}, nil
+}
+
+// PreviewTenantLimit validates a limit change and renders its diff without applying it
+func (a *Analyzer) PreviewTenantLimit(ctx context.Context, tenantName, limitName string, newValue interface{}) (*LimitUpdatePreview, error) {
+	validation := a.ValidateLimitValue(limitName, newValue)
+
+	preview, err := a.autoDiscovery.PreviewTenantOverrides(ctx, a.config.Mimir.Namespace, []LimitChange{
+		{TenantID: tenantName, LimitName: limitName, NewValue: newValue},
+	})
+	if err != nil {
+		if !validation.Valid {
+			return nil, fmt.Errorf("%w: invalid value for %s: %s", ErrInvalidLimitChange, limitName, strings.Join(validation.Errors, "; "))
+		}
+		return nil, err
+	}
+
+	preview.Validation = []*LimitValidation{validation}
+	preview.Valid = validation.Valid
+	return preview, nil
+}
+
+// selectOverridesKey picks the data key holding the `overrides:` section
+func selectOverridesKey(data map[string]string) string {
+	var yamlKeys []string
+	for key := range data {
+		if isYAMLKey(key) {
+			yamlKeys = append(yamlKeys, key)
+		}
+	}
+	if len(yamlKeys) == 0 {
+		return defaultOverridesKey
+	}
+	sort.Strings(yamlKeys)
+
+	for _, key := range yamlKeys {
+		document, err := parseOverridesDocument(data[key])
+		if err != nil {
+			continue
+		}
+		if _, value := mappingGet(document.Content[0], "overrides"); value != nil {
+			return key
+		}
+	}
+
+	return yamlKeys[0]
+}
+
+// parseOverridesDocument parses overrides YAML into a node tree that keeps comments, order and styles
+func parseOverridesDocument(content string) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		// Empty data key
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}, nil
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("runtime overrides are not a YAML map")
+	}
+	return &document, nil
+}
+
+// renderOverridesDocument writes the node tree back with the indentation of the original YAML
+func renderOverridesDocument(document *yaml.Node, original string) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(detectIndent(original))
+	if err := encoder.Encode(document); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// detectIndent returns the indentation of the first nested line, defaulting to two spaces
+func detectIndent(content string) int {
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if indent := len(line) - len(trimmed); indent > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return indent
+		}
+	}
+	return 2
+}
+
+// setTenantOverride sets overrides.<tenant>.<limit> in place and returns the previous value
+func setTenantOverride(document *yaml.Node, tenantID, limitName string, value interface{}) (interface{}, error) {
+	overrides, err := childMapping(document.Content[0], "overrides", true)
+	if err != nil {
+		return nil, err
+	}
+	tenantConfig, err := childMapping(overrides, tenantID, true)
+	if err != nil {
+		return nil, fmt.Errorf("overrides for tenant %s: %w", tenantID, err)
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to encode %v: %w", value, err)
+	}
+
+	_, existing := mappingGet(tenantConfig, limitName)
+	if existing == nil {
+		tenantConfig.Content = append(tenantConfig.Content, scalarNode(limitName), &valueNode)
+		return nil, nil
+	}
+
+	var oldValue interface{}
+	if err := existing.Decode(&oldValue); err != nil {
+		return nil, fmt.Errorf("failed to read %s of tenant %s: %w", limitName, tenantID, err)
+	}
+	// Keep the comments attached to the old value
+	valueNode.HeadComment, valueNode.LineComment, valueNode.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+	*existing = valueNode
+	return oldValue, nil
+}
+
+// normalizeLimitValue converts JSON-decoded values into the scalars Mimir expects in YAML
+func normalizeLimitValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("value is required")
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return int64(v), nil
+		}
+		return v, nil
+	case int, int64, bool:
+		return v, nil
+	case json.Number:
+		if intVal, err := v.Int64(); err == nil {
+			return intVal, nil
+		}
+		return v.Float64()
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if trimmed == "" {
+			return nil, fmt.Errorf("value is empty")
+		}
+		if intVal, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			return intVal, nil
+		}
+		if floatVal, err := strconv.ParseFloat(trimmed, 64); err == nil {
+			return floatVal, nil
+		}
+		return trimmed, nil // Durations and other string-typed limits
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// sameLimitValue compares limit values regardless of whether they were decoded from YAML or JSON
+func sameLimitValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return formatLimitValue(a) == formatLimitValue(b)
+}
+
+// formatLimitValue prints a limit value, showing JSON-decoded integers without an exponent
+func formatLimitValue(value interface{}) string {
+	if value == nil {
+		return "unset"
+	}
+	if normalized, err := normalizeLimitValue(value); err == nil {
+		value = normalized
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// removeTenantOverride deletes overrides.<tenant>.<limit> in place and returns the removed value
+func removeTenantOverride(document *yaml.Node, tenantID, limitName string) (interface{}, error) {
+	overrides, err := childMapping(document.Content[0], "overrides", false)
+	if err != nil || overrides == nil {
+		return nil, err
+	}
+	tenantConfig, err := childMapping(overrides, tenantID, false)
+	if err != nil {
+		return nil, fmt.Errorf("overrides for tenant %s: %w", tenantID, err)
+	}
+	if tenantConfig == nil {
+		return nil, nil
+	}
+
+	index, existing := mappingGet(tenantConfig, limitName)
+	if existing == nil {
+		return nil, nil
+	}
+	var oldValue interface{}
+	if err := existing.Decode(&oldValue); err != nil {
+		return nil, fmt.Errorf("failed to read %s of tenant %s: %w", limitName, tenantID, err)
+	}
+
+	tenantConfig.Content = append(tenantConfig.Content[:index], tenantConfig.Content[index+2:]...)
+	if len(tenantConfig.Content) == 0 {
+		tenantIndex, _ := mappingGet(overrides, tenantID)
+		overrides.Content = append(overrides.Content[:tenantIndex], overrides.Content[tenantIndex+2:]...)
+	}
+	return oldValue, nil
+}
+
+// childMapping returns the map under key, creating it when asked to; a null value counts as missing
+func childMapping(parent *yaml.Node, key string, create bool) (*yaml.Node, error) {
+	_, value := mappingGet(parent, key)
+	if value != nil && value.Kind == yaml.MappingNode {
+		return value, nil
+	}
+	if value != nil && value.Tag != "!!null" {
+		return nil, fmt.Errorf("%s is not a map", key)
+	}
+	if !create {
+		return nil, nil
+	}
+
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if value != nil {
+		// Turn `key:` with no value into the map, keeping its comments
+		mapping.HeadComment, mapping.LineComment, mapping.FootComment = value.HeadComment, value.LineComment, value.FootComment
+		*value = *mapping
+		return value, nil
+	}
+	parent.Content = append(parent.Content, scalarNode(key), mapping)
+	return mapping, nil
+}
+
+// mappingGet returns the index of the key node and the value node of key in a mapping
+func mappingGet(mapping *yaml.Node, key string) (int, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i, mapping.Content[i+1]
+		}
+	}
+	return -1, nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
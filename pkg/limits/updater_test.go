@@ -0,0 +1,330 @@
+package limits
+
+import (
+	"strings"
+	"testing"
+)
+
+const testOverrides = `# Runtime overrides managed by the platform team
+overrides:
+    # Team A
+    tenant-a:
+        ingestion_rate: 10000 # raised for the migration
+        max_global_series_per_user: 150000
+    tenant-b:
+        ingestion_rate: 5000
+`
+
+func TestSetTenantOverride(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		tenant    string
+		limit     string
+		value     interface{}
+		wantOld   interface{}
+		want      string
+		wantError bool
+	}{
+		{
+			name:    "modify keeps comments, indentation and other tenants",
+			input:   testOverrides,
+			tenant:  "tenant-a",
+			limit:   "ingestion_rate",
+			value:   int64(20000),
+			wantOld: 10000,
+			want: `# Runtime overrides managed by the platform team
+overrides:
+    # Team A
+    tenant-a:
+        ingestion_rate: 20000 # raised for the migration
+        max_global_series_per_user: 150000
+    tenant-b:
+        ingestion_rate: 5000
+`,
+		},
+		{
+			name:   "add limit to existing tenant",
+			input:  testOverrides,
+			tenant: "tenant-b",
+			limit:  "ingestion_burst_size",
+			value:  int64(100000),
+			want: `# Runtime overrides managed by the platform team
+overrides:
+    # Team A
+    tenant-a:
+        ingestion_rate: 10000 # raised for the migration
+        max_global_series_per_user: 150000
+    tenant-b:
+        ingestion_rate: 5000
+        ingestion_burst_size: 100000
+`,
+		},
+		{
+			name:   "add new tenant",
+			input:  testOverrides,
+			tenant: "tenant-c",
+			limit:  "max_label_names_per_series",
+			value:  int64(40),
+			want: `# Runtime overrides managed by the platform team
+overrides:
+    # Team A
+    tenant-a:
+        ingestion_rate: 10000 # raised for the migration
+        max_global_series_per_user: 150000
+    tenant-b:
+        ingestion_rate: 5000
+    tenant-c:
+        max_label_names_per_series: 40
+`,
+		},
+		{
+			name:   "null overrides become a map",
+			input:  "overrides: # nothing yet\n",
+			tenant: "tenant-a",
+			limit:  "ingestion_rate",
+			value:  int64(1000),
+			want:   "overrides: # nothing yet\n  tenant-a:\n    ingestion_rate: 1000\n",
+		},
+		{
+			name:   "empty document",
+			input:  "",
+			tenant: "tenant-a",
+			limit:  "compactor_blocks_retention_period",
+			value:  "30d",
+			want:   "overrides:\n  tenant-a:\n    compactor_blocks_retention_period: 30d\n",
+		},
+		{
+			name:      "tenant that is not a map",
+			input:     "overrides:\n  tenant-a: 5\n",
+			tenant:    "tenant-a",
+			limit:     "ingestion_rate",
+			value:     int64(1),
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := parseOverridesDocument(tt.input)
+			if err != nil {
+				t.Fatalf("parseOverridesDocument() error = %v", err)
+			}
+			old, err := setTenantOverride(document, tt.tenant, tt.limit, tt.value)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("setTenantOverride() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setTenantOverride() error = %v", err)
+			}
+			if old != tt.wantOld {
+				t.Errorf("setTenantOverride() old = %#v, want %#v", old, tt.wantOld)
+			}
+			got, err := renderOverridesDocument(document, tt.input)
+			if err != nil {
+				t.Fatalf("renderOverridesDocument() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("rendered YAML mismatch\n got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveTenantOverride(t *testing.T) {
+	tests := []struct {
+		name    string
+		tenant  string
+		limit   string
+		wantOld interface{}
+		want    string
+	}{
+		{
+			name:    "remove one limit",
+			tenant:  "tenant-a",
+			limit:   "max_global_series_per_user",
+			wantOld: 150000,
+			want: `# Runtime overrides managed by the platform team
+overrides:
+    # Team A
+    tenant-a:
+        ingestion_rate: 10000 # raised for the migration
+    tenant-b:
+        ingestion_rate: 5000
+`,
+		},
+		{
+			name:    "remove last limit drops the tenant",
+			tenant:  "tenant-b",
+			limit:   "ingestion_rate",
+			wantOld: 5000,
+			want: `# Runtime overrides managed by the platform team
+overrides:
+    # Team A
+    tenant-a:
+        ingestion_rate: 10000 # raised for the migration
+        max_global_series_per_user: 150000
+`,
+		},
+		{
+			name:   "missing limit",
+			tenant: "tenant-b",
+			limit:  "ingestion_burst_size",
+			want:   testOverrides,
+		},
+		{
+			name:   "missing tenant",
+			tenant: "tenant-z",
+			limit:  "ingestion_rate",
+			want:   testOverrides,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := parseOverridesDocument(testOverrides)
+			if err != nil {
+				t.Fatalf("parseOverridesDocument() error = %v", err)
+			}
+			old, err := removeTenantOverride(document, tt.tenant, tt.limit)
+			if err != nil {
+				t.Fatalf("removeTenantOverride() error = %v", err)
+			}
+			if old != tt.wantOld {
+				t.Errorf("removeTenantOverride() old = %#v, want %#v", old, tt.wantOld)
+			}
+			got, err := renderOverridesDocument(document, testOverrides)
+			if err != nil {
+				t.Fatalf("renderOverridesDocument() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("rendered YAML mismatch\n got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOverridesDocumentRejectsNonMap(t *testing.T) {
+	for _, input := range []string{"- a\n- b\n", "just a string\n"} {
+		if _, err := parseOverridesDocument(input); err == nil {
+			t.Errorf("parseOverridesDocument(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestNormalizeLimitValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		want      interface{}
+		wantError bool
+	}{
+		{name: "integral float", value: float64(100000), want: int64(100000)},
+		{name: "fractional float", value: 0.5, want: 0.5},
+		{name: "numeric string", value: " 42 ", want: int64(42)},
+		{name: "duration string", value: "720h", want: "720h"},
+		{name: "bool", value: true, want: true},
+		{name: "nil", value: nil, wantError: true},
+		{name: "empty string", value: "  ", wantError: true},
+		{name: "unsupported", value: []string{"a"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeLimitValue(tt.value)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("normalizeLimitValue() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !tt.wantError && got != tt.want {
+				t.Errorf("normalizeLimitValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectIndent(t *testing.T) {
+	tests := []struct {
+		content string
+		want    int
+	}{
+		{content: testOverrides, want: 4},
+		{content: "overrides:\n  a:\n    b: 1\n", want: 2},
+		{content: "# header\n   # indented comment\noverrides: {}\n", want: 2},
+		{content: "", want: 2},
+	}
+
+	for _, tt := range tests {
+		if got := detectIndent(tt.content); got != tt.want {
+			t.Errorf("detectIndent(%q) = %d, want %d", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestSelectOverridesKey(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]string
+		want string
+	}{
+		{name: "no data", data: map[string]string{}, want: defaultOverridesKey},
+		{
+			name: "key with overrides wins",
+			data: map[string]string{"a.yaml": "other: 1\n", "runtime.yaml": "overrides:\n  t: {}\n"},
+			want: "runtime.yaml",
+		},
+		{
+			name: "first YAML key otherwise",
+			data: map[string]string{"b.yaml": "x: 1\n", "a.yaml": "y: 2\n", "notes.txt": "z"},
+			want: "a.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectOverridesKey(tt.data); got != tt.want {
+				t.Errorf("selectOverridesKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderOverridesDocumentRoundTrip(t *testing.T) {
+	document, err := parseOverridesDocument(testOverrides)
+	if err != nil {
+		t.Fatalf("parseOverridesDocument() error = %v", err)
+	}
+	got, err := renderOverridesDocument(document, testOverrides)
+	if err != nil {
+		t.Fatalf("renderOverridesDocument() error = %v", err)
+	}
+	if got != testOverrides {
+		t.Errorf("round trip changed the document:\n%s", unifiedDiff("before", "after", testOverrides, got))
+	}
+	if !strings.Contains(got, "# raised for the migration") {
+		t.Error("line comment lost")
+	}
+}
+
+func TestSameLimitValue(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{a: 100000, b: float64(100000), want: true}, // YAML int against a JSON number from a stored proposal
+		{a: 1000000, b: float64(1e6), want: true},
+		{a: 0.5, b: "0.5", want: true},
+		{a: "720h", b: "720h", want: true},
+		{a: nil, b: nil, want: true},
+		{a: nil, b: 0, want: false},
+		{a: 10, b: 11, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := sameLimitValue(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameLimitValue(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package limits
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// durationPattern matches Prometheus-style durations such as "30s", "12h" or "1d12h"
+var durationPattern = regexp.MustCompile(`^(\d+(ms|s|m|h|d|w|y))+$`)
+
+// LimitValidation represents the result of validating a proposed limit value
+type LimitValidation struct {
+	LimitName string   `json:"limit_name"`
+	Known     bool     `json:"known"`
+	Unit      string   `json:"unit,omitempty"`
+	Category  string   `json:"category,omitempty"`
+	Valid     bool     `json:"valid"`
+	Errors    []string `json:"errors,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// ValidateLimitValue checks a proposed value against the limit type catalogue
+func (a *Analyzer) ValidateLimitValue(limitName string, value interface{}) *LimitValidation {
+	validation := &LimitValidation{LimitName: limitName}
+
+	var limitType *LimitType
+	for _, lt := range a.getLimitTypes() {
+		if lt.Name == limitName {
+			lt := lt
+			limitType = &lt
+			break
+		}
+	}
+
+	if limitType == nil {
+		validation.Warnings = append(validation.Warnings,
+			fmt.Sprintf("%s is not in the limit catalogue; only basic checks were applied", limitName))
+		if _, err := normalizeLimitValue(value); err != nil {
+			validation.Errors = append(validation.Errors, err.Error())
+		}
+		validation.Valid = len(validation.Errors) == 0
+		return validation
+	}
+
+	validation.Known = true
+	validation.Unit = limitType.Unit
+	validation.Category = limitType.Category
+
+	switch limitType.Unit {
+	case "boolean":
+		validateBooleanLimit(value, validation)
+	case "url":
+		if s, ok := value.(string); !ok || strings.TrimSpace(s) == "" {
+			validation.Errors = append(validation.Errors, "value must be a non-empty URL string")
+		}
+	case "seconds", "minutes", "hours":
+		validateDurationLimit(value, *limitType, validation)
+	default:
+		validateCountLimit(value, *limitType, validation)
+	}
+
+	validation.Valid = len(validation.Errors) == 0
+	return validation
+}
+
+func validateBooleanLimit(value interface{}, validation *LimitValidation) {
+	switch v := value.(type) {
+	case bool:
+		return
+	case string:
+		if _, err := strconv.ParseBool(v); err == nil {
+			return
+		}
+	default:
+		if f, ok := numericValue(value); ok && (f == 0 || f == 1) {
+			return
+		}
+	}
+	validation.Errors = append(validation.Errors, "value must be true/false or 0/1")
+}
+
+func validateDurationLimit(value interface{}, limitType LimitType, validation *LimitValidation) {
+	if s, ok := value.(string); ok {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			if !durationPattern.MatchString(strings.TrimSpace(s)) {
+				validation.Errors = append(validation.Errors,
+					fmt.Sprintf("value %q is not a valid duration (e.g. 30s, 5m, 12h, 7d)", s))
+			}
+			return
+		}
+	}
+
+	f, ok := numericValue(value)
+	if !ok {
+		validation.Errors = append(validation.Errors,
+			fmt.Sprintf("value must be a number of %s or a duration string", limitType.Unit))
+		return
+	}
+	if f < 0 {
+		validation.Errors = append(validation.Errors, "value must not be negative")
+	} else if f == 0 {
+		validation.Warnings = append(validation.Warnings, "a value of 0 disables this limit in Mimir")
+	}
+}
+
+func validateCountLimit(value interface{}, limitType LimitType, validation *LimitValidation) {
+	f, ok := numericValue(value)
+	if !ok {
+		validation.Errors = append(validation.Errors,
+			fmt.Sprintf("value must be numeric (%s)", limitType.Unit))
+		return
+	}
+
+	if f < 0 {
+		validation.Errors = append(validation.Errors, "value must not be negative")
+		return
+	}
+	if f == 0 {
+		validation.Warnings = append(validation.Warnings, "a value of 0 disables this limit in Mimir")
+		return
+	}
+
+	// Rates may be fractional, everything else is a count
+	if limitType.Unit != "samples/sec" && f != math.Trunc(f) {
+		validation.Errors = append(validation.Errors,
+			fmt.Sprintf("value must be a whole number of %s", limitType.Unit))
+	}
+
+	if limitType.DefaultValue > 0 {
+		ratio := f / limitType.DefaultValue
+		switch {
+		case ratio > 1000:
+			validation.Errors = append(validation.Errors,
+				fmt.Sprintf("value is %.0fx the default of %.0f %s, which is outside the sane range",
+					ratio, limitType.DefaultValue, limitType.Unit))
+		case ratio > 100:
+			validation.Warnings = append(validation.Warnings,
+				fmt.Sprintf("value is %.0fx the default of %.0f %s", ratio, limitType.DefaultValue, limitType.Unit))
+		case ratio < 0.01:
+			validation.Warnings = append(validation.Warnings,
+				fmt.Sprintf("value is less than 1%% of the default of %.0f %s", limitType.DefaultValue, limitType.Unit))
+		}
+	}
+}
+
+// numericValue extracts a float from JSON-decoded or YAML-decoded values
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
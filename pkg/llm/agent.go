@@ -0,0 +1,544 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/cache"
+	"github.com/akshaydubey29/mimirInsights/pkg/drift"
+	"github.com/akshaydubey29/mimirInsights/pkg/limits"
+	"github.com/akshaydubey29/mimirInsights/pkg/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxAgentSteps bounds the model round trips for one question
+	maxAgentSteps = 8
+	// maxToolResultBytes keeps a single tool result from flooding the context window
+	maxToolResultBytes = 8000
+	maxQuerySeries     = 20
+	maxSeriesSamples   = 12
+	maxQueryRange      = 31 * 24 * time.Hour
+	maxDriftResources  = 25
+	maxDriftChanges    = 10
+)
+
+const agentSystemPrompt = `You are a Grafana Mimir monitoring expert. Answer the user's question about their Mimir cluster.
+Use the tools to look up real data before answering, and keep querying until you can explain the cause.
+Useful Mimir metrics include cortex_distributor_received_samples_total, cortex_discarded_samples_total (label "reason"),
+cortex_ingester_memory_series, cortex_ingester_active_series and cortex_request_duration_seconds; per-tenant series carry a "user" label.
+Base the answer on the data you retrieved, say when data was missing, and finish with concrete recommendations.
+The current time is %s.`
+
+// DataSources are the components the assistant's tools read from; nil sources disable their tools
+type DataSources struct {
+	Metrics *metrics.Client
+	Limits  *limits.Analyzer
+	Cache   *cache.Manager
+	Drift   *drift.Detector
+}
+
+// assistantTool is a tool the model can call together with its implementation
+type assistantTool struct {
+	definition ToolDefinition
+	run        func(ctx context.Context, arguments json.RawMessage) (interface{}, error)
+}
+
+// SetDataSources gives the assistant's tools access to Mimir, limits, discovery and drift data
+func (a *Assistant) SetDataSources(sources DataSources) {
+	a.metricsClient = sources.Metrics
+	a.sources = sources
+	a.tools = a.buildTools()
+}
+
+// buildTools returns the tools backed by the configured data sources
+func (a *Assistant) buildTools() map[string]assistantTool {
+	tools := make(map[string]assistantTool)
+
+	if a.sources.Metrics != nil {
+		tools["query_metrics"] = assistantTool{
+			definition: ToolDefinition{
+				Name:        "query_metrics",
+				Description: "Run a PromQL range query against Mimir and return per-series statistics and sampled values.",
+				Parameters: objectSchema(map[string]interface{}{
+					"query":  stringProperty("PromQL expression"),
+					"range":  stringProperty("How far back from end to query, e.g. 30m, 6h, 1d (default 1h)"),
+					"end":    stringProperty("End of the range as RFC3339 timestamp (default now)"),
+					"step":   stringProperty("Resolution step, e.g. 1m (default chosen from the range)"),
+					"tenant": stringProperty("Org ID to query instead of the default one; join several with | to query them together"),
+				}, "query"),
+			},
+			run: a.queryMetricsTool,
+		}
+	}
+	if a.sources.Limits != nil {
+		tools["get_tenant_limits"] = assistantTool{
+			definition: ToolDefinition{
+				Name:        "get_tenant_limits",
+				Description: "Fetch a tenant's configured Mimir limits, observed peaks and limit recommendations.",
+				Parameters: objectSchema(map[string]interface{}{
+					"tenant": stringProperty("Tenant ID"),
+				}, "tenant"),
+			},
+			run: a.tenantLimitsTool,
+		}
+	}
+	if a.sources.Cache != nil {
+		tools["list_components"] = assistantTool{
+			definition: ToolDefinition{
+				Name:        "list_components",
+				Description: "List the discovered Mimir components with status, replicas and version, and the tenant namespaces.",
+				Parameters: objectSchema(map[string]interface{}{
+					"type": stringProperty("Only return components of this type, e.g. ingester or distributor"),
+				}),
+			},
+			run: a.listComponentsTool,
+		}
+	}
+	if a.sources.Drift != nil {
+		tools["get_drift_status"] = assistantTool{
+			definition: ToolDefinition{
+				Name:        "get_drift_status",
+				Description: "Check Mimir ConfigMaps and workloads for configuration drift from their baselines.",
+				Parameters: objectSchema(map[string]interface{}{
+					"namespace": stringProperty("Only check this namespace (default: the Mimir and tenant namespaces)"),
+				}),
+			},
+			run: a.driftStatusTool,
+		}
+	}
+
+	return tools
+}
+
+// processWithTools answers a query by letting the model call tools until it can answer
+func (a *Assistant) processWithTools(ctx context.Context, client ToolClient, req QueryRequest, start time.Time, emit func(StreamEvent) error) (*AssistantResponse, error) {
+	definitions := make([]ToolDefinition, 0, len(a.tools))
+	for _, name := range []string{"query_metrics", "get_tenant_limits", "list_components", "get_drift_status"} {
+		if tool, exists := a.tools[name]; exists {
+			definitions = append(definitions, tool.definition)
+		}
+	}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: fmt.Sprintf(agentSystemPrompt, time.Now().UTC().Format(time.RFC3339))},
+		{Role: "user", Content: a.buildAgentQuestion(req)},
+	}
+
+	var sources []MetricSource
+	var usage LLMResponse
+	var answer *LLMResponse
+	for step := 0; step <= maxAgentSteps; step++ {
+		// The last round withholds the tools so the model has to answer with what it has
+		tools := definitions
+		if step == maxAgentSteps {
+			tools = nil
+			messages = append(messages, ChatMessage{Role: "user", Content: "Answer now using the data gathered so far."})
+		}
+
+		response, err := a.chat(ctx, client, messages, tools, req, emit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get LLM response: %w", err)
+		}
+		usage.TokensUsed += response.TokensUsed
+		usage.PromptTokens += response.PromptTokens
+		usage.CompletionTokens += response.CompletionTokens
+
+		if len(response.ToolCalls) == 0 || step == maxAgentSteps {
+			answer = response
+			break
+		}
+
+		calls := make([]ToolCall, len(response.ToolCalls))
+		for i, call := range response.ToolCalls {
+			if len(call.Arguments) == 0 || !json.Valid(call.Arguments) {
+				call.Arguments = json.RawMessage("{}")
+			}
+			calls[i] = call
+		}
+		messages = append(messages, ChatMessage{Role: "assistant", Content: response.Content, ToolCalls: calls})
+
+		gathered := make([]MetricSource, 0, len(calls))
+		for i, call := range calls {
+			result, source := a.runTool(ctx, call, response.ToolCalls[i].Arguments)
+			gathered = append(gathered, source)
+			messages = append(messages, ChatMessage{Role: "tool", Content: result, ToolCallID: call.ID, ToolName: call.Name})
+		}
+		sources = append(sources, gathered...)
+		if err := emitEvent(emit, StreamEventSources, gathered); err != nil {
+			return nil, err
+		}
+	}
+
+	// The metric analysis and its recommendations only read prefetched metric sources, not tool results;
+	// the model's answer carries the analysis and recommendations on this path
+	intent := a.analyzeQueryIntent(req.Query)
+	response := &AssistantResponse{
+		Answer:         answer.Content,
+		Confidence:     answer.Confidence,
+		Sources:        sources,
+		RelatedQueries: a.generateRelatedQueries(req.Query, intent),
+		ResponseMetadata: ResponseMetadata{
+			ProcessingTime:   time.Since(start),
+			TokensUsed:       usage.TokensUsed,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			ToolCalls:        len(sources),
+			Model:            answer.Model,
+			QueryComplexity:  a.assessQueryComplexity(req.Query),
+			DataPoints:       len(sources),
+			GeneratedAt:      time.Now(),
+		},
+	}
+
+	logrus.Infof("🤖 [LLM] Answered query with %d tool calls in %v, used %d tokens",
+		len(sources), response.ResponseMetadata.ProcessingTime, usage.TokensUsed)
+
+	if err := emitResponse(emit, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// chat sends one round of the conversation, streaming the model's text through emit when the client supports it
+func (a *Assistant) chat(ctx context.Context, client ToolClient, messages []ChatMessage, tools []ToolDefinition, req QueryRequest, emit func(StreamEvent) error) (*LLMResponse, error) {
+	if streamingClient, ok := client.(StreamingClient); ok && emit != nil {
+		return streamingClient.ChatStream(ctx, messages, tools, req.MaxTokens, req.Temperature, tokenEmitter(emit))
+	}
+
+	response, err := client.Chat(ctx, messages, tools, req.MaxTokens, req.Temperature)
+	if err != nil {
+		return nil, err
+	}
+	if response.Content != "" {
+		if err := emitEvent(emit, StreamEventToken, TokenEvent{Text: response.Content}); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// buildAgentQuestion adds the request context to the user's question
+func (a *Assistant) buildAgentQuestion(req QueryRequest) string {
+	var question strings.Builder
+	question.WriteString(req.Query)
+	if req.Context.TenantName != "" {
+		fmt.Fprintf(&question, "\nTenant: %s", req.Context.TenantName)
+	}
+	if req.Context.TimeRange != "" {
+		fmt.Fprintf(&question, "\nTime range: %s", req.Context.TimeRange)
+	}
+	return question.String()
+}
+
+// runTool executes a tool call and returns the result for the model and a source for the response
+func (a *Assistant) runTool(ctx context.Context, call ToolCall, rawArguments json.RawMessage) (string, MetricSource) {
+	source := MetricSource{
+		Type:      "tool",
+		Name:      call.Name,
+		Value:     string(call.Arguments),
+		Timestamp: time.Now(),
+		Relevance: 1.0,
+	}
+
+	tool, exists := a.tools[call.Name]
+	var result interface{}
+	var err error
+	switch {
+	case !exists:
+		err = fmt.Errorf("unknown tool %q", call.Name)
+	case len(bytes.TrimSpace(rawArguments)) > 0 && !json.Valid(rawArguments):
+		// Tools without parameters are often called with no arguments at all, which means {}
+		err = fmt.Errorf("arguments are not valid JSON: %s", string(rawArguments))
+	default:
+		result, err = tool.run(ctx, call.Arguments)
+	}
+
+	if err != nil {
+		logrus.Warnf("⚠️ [LLM] Tool %s failed: %v", call.Name, err)
+		source.Description = fmt.Sprintf("Failed: %v", err)
+		encoded, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return string(encoded), source
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		source.Description = fmt.Sprintf("Failed to encode result: %v", err)
+		return fmt.Sprintf(`{"error": %q}`, err.Error()), source
+	}
+	logrus.Debugf("🔧 [LLM] Tool %s(%s) returned %d bytes", call.Name, string(call.Arguments), len(encoded))
+
+	source.Description = fmt.Sprintf("%s returned %d bytes of data", call.Name, len(encoded))
+	if len(encoded) > maxToolResultBytes {
+		return string(encoded[:maxToolResultBytes]) + "... (truncated)", source
+	}
+	return string(encoded), source
+}
+
+// seriesSummary condenses a series so the model sees its shape without every sample
+type seriesSummary struct {
+	Labels  map[string]string `json:"labels"`
+	Points  int               `json:"points"`
+	Min     float64           `json:"min"`
+	Max     float64           `json:"max"`
+	Avg     float64           `json:"avg"`
+	Last    float64           `json:"last"`
+	Samples [][2]string       `json:"samples"` // Evenly spaced [timestamp, value] pairs
+}
+
+func (a *Assistant) queryMetricsTool(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Query  string `json:"query"`
+		Range  string `json:"range"`
+		End    string `json:"end"`
+		Step   string `json:"step"`
+		Tenant string `json:"tenant"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	lookback := time.Hour
+	if args.Range != "" {
+		parsed, err := parseLookback(args.Range)
+		if err != nil {
+			return nil, err
+		}
+		lookback = parsed
+	}
+	if lookback > maxQueryRange {
+		return nil, fmt.Errorf("range %s exceeds the maximum of %v", args.Range, maxQueryRange)
+	}
+	end := time.Now()
+	if args.End != "" {
+		parsed, err := time.Parse(time.RFC3339, args.End)
+		if err != nil {
+			return nil, fmt.Errorf("end must be an RFC3339 timestamp: %w", err)
+		}
+		end = parsed
+	}
+	step := args.Step
+	if step == "" {
+		// Around 60 points per series is enough to see the shape of the data
+		step = fmt.Sprintf("%ds", int(math.Max(15, lookback.Seconds()/60)))
+	}
+
+	response, err := a.sources.Metrics.QueryMetrics(ctx, metrics.MetricQuery{
+		Query:  args.Query,
+		Start:  end.Add(-lookback),
+		End:    end,
+		Step:   step,
+		Tenant: args.Tenant,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]seriesSummary, 0, len(response.Data.Result))
+	for _, result := range response.Data.Result {
+		if len(series) == maxQuerySeries {
+			break
+		}
+		series = append(series, summarizeSeries(result.Metric, result.Samples()))
+	}
+	return map[string]interface{}{
+		"query":        args.Query,
+		"start":        end.Add(-lookback).UTC().Format(time.RFC3339),
+		"end":          end.UTC().Format(time.RFC3339),
+		"step":         step,
+		"series_total": len(response.Data.Result),
+		"series":       series,
+	}, nil
+}
+
+func summarizeSeries(labels map[string]string, values []metrics.SamplePair) seriesSummary {
+	summary := seriesSummary{Labels: labels, Min: math.Inf(1), Max: math.Inf(-1), Samples: [][2]string{}}
+	sum := 0.0
+	for _, sample := range values {
+		if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+			continue
+		}
+		summary.Points++
+		sum += sample.Value
+		summary.Min = math.Min(summary.Min, sample.Value)
+		summary.Max = math.Max(summary.Max, sample.Value)
+		summary.Last = sample.Value
+	}
+	if summary.Points == 0 {
+		summary.Min, summary.Max = 0, 0
+		return summary
+	}
+	summary.Avg = sum / float64(summary.Points)
+
+	stride := int(math.Ceil(float64(len(values)) / maxSeriesSamples))
+	for i := 0; i < len(values); i += stride {
+		summary.Samples = append(summary.Samples, [2]string{
+			values[i].Timestamp.UTC().Format(time.RFC3339), strconv.FormatFloat(values[i].Value, 'f', -1, 64),
+		})
+	}
+	return summary
+}
+
+// parseLookback parses a duration that may also be given in days, e.g. "7d"
+func parseLookback(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		count, err := strconv.ParseFloat(days, 64)
+		if err != nil || count <= 0 {
+			return 0, fmt.Errorf("invalid range %q", value)
+		}
+		return time.Duration(count * float64(24*time.Hour)), nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid range %q", value)
+	}
+	return duration, nil
+}
+
+func (a *Assistant) tenantLimitsTool(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Tenant string `json:"tenant"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if args.Tenant == "" {
+		return nil, fmt.Errorf("tenant is required")
+	}
+	return a.sources.Limits.AnalyzeTenantLimits(ctx, args.Tenant)
+}
+
+func (a *Assistant) listComponentsTool(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	discovered := a.sources.Cache.GetDiscoveryResult()
+	if discovered == nil {
+		return nil, fmt.Errorf("discovery data is not available yet")
+	}
+
+	var components []map[string]interface{}
+	for _, component := range discovered.MimirComponents {
+		if args.Type != "" && !strings.EqualFold(component.Type, args.Type) {
+			continue
+		}
+		components = append(components, map[string]interface{}{
+			"name":      component.Name,
+			"type":      component.Type,
+			"namespace": component.Namespace,
+			"status":    component.Status,
+			"replicas":  component.Replicas,
+			"version":   component.Version,
+		})
+	}
+	tenants := make([]string, 0, len(discovered.TenantNamespaces))
+	for _, tenant := range discovered.TenantNamespaces {
+		tenants = append(tenants, tenant.Name)
+	}
+
+	return map[string]interface{}{
+		"components":        components,
+		"tenant_namespaces": tenants,
+		"last_updated":      discovered.LastUpdated,
+	}, nil
+}
+
+func (a *Assistant) driftStatusTool(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Namespace string `json:"namespace"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	namespaces := []string{args.Namespace}
+	if args.Namespace == "" {
+		namespaces = a.monitoredNamespaces()
+		if len(namespaces) == 0 {
+			return nil, fmt.Errorf("namespace is required until discovery has completed")
+		}
+	}
+
+	report, err := a.sources.Drift.DetectDrift(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifted []map[string]interface{}
+	for _, status := range report.DriftStatuses {
+		if status.Status == "no_drift" || len(drifted) == maxDriftResources {
+			continue
+		}
+		changes := status.Changes
+		if len(changes) > maxDriftChanges {
+			changes = changes[:maxDriftChanges]
+		}
+		drifted = append(drifted, map[string]interface{}{
+			"resource":   drift.ResourceKey(status.Resource, status.Namespace, status.Name),
+			"status":     status.Status,
+			"risk_level": status.RiskLevel,
+			"changes":    changes,
+		})
+	}
+
+	return map[string]interface{}{
+		"namespaces":      namespaces,
+		"total_resources": report.TotalResources,
+		"drifted_count":   report.DriftedCount,
+		"new_count":       report.NewCount,
+		"deleted_count":   report.DeletedCount,
+		"summary":         report.Summary,
+		"drifted":         drifted,
+	}, nil
+}
+
+// monitoredNamespaces returns the Mimir and tenant namespaces from the cached discovery
+func (a *Assistant) monitoredNamespaces() []string {
+	if a.sources.Cache == nil {
+		return nil
+	}
+	discovered := a.sources.Cache.GetDiscoveryResult()
+	if discovered == nil {
+		return nil
+	}
+
+	var namespaces []string
+	if discovered.Environment != nil && discovered.Environment.MimirNamespace != "" {
+		namespaces = append(namespaces, discovered.Environment.MimirNamespace)
+	}
+	for _, tenant := range discovered.TenantNamespaces {
+		namespaces = append(namespaces, tenant.Name)
+	}
+	return namespaces
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": description,
+	}
+}
@@ -0,0 +1,182 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// scriptedToolClient replies with one canned response per chat round and records what it was sent
+type scriptedToolClient struct {
+	replies  []*LLMResponse
+	fallback *LLMResponse // Reply once the script is used up; nil fails the round
+	messages [][]ChatMessage
+	tools    [][]ToolDefinition
+}
+
+func (c *scriptedToolClient) Chat(ctx context.Context, messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64) (*LLMResponse, error) {
+	c.messages = append(c.messages, append([]ChatMessage(nil), messages...))
+	c.tools = append(c.tools, tools)
+	if len(c.replies) == 0 {
+		if c.fallback == nil {
+			return nil, errors.New("no more replies")
+		}
+		return c.fallback, nil
+	}
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	return reply, nil
+}
+
+func (c *scriptedToolClient) GenerateResponse(ctx context.Context, prompt string, maxTokens int, temperature *float64) (*LLMResponse, error) {
+	return nil, errors.New("the agent loop should not generate plain responses")
+}
+
+func (c *scriptedToolClient) IsEnabled() bool { return true }
+
+// fakeTool records the arguments it is called with and returns result
+func fakeTool(name string, result interface{}, received *[]string) assistantTool {
+	return assistantTool{
+		definition: ToolDefinition{Name: name, Parameters: objectSchema(map[string]interface{}{})},
+		run: func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+			*received = append(*received, string(arguments))
+			return result, nil
+		},
+	}
+}
+
+// toolMessage returns the content of the tool message answering a call
+func toolMessage(t *testing.T, messages []ChatMessage, callID string) string {
+	t.Helper()
+	for _, message := range messages {
+		if message.Role == "tool" && message.ToolCallID == callID {
+			return message.Content
+		}
+	}
+	t.Fatalf("no tool message answers call %s", callID)
+	return ""
+}
+
+func TestAgentDispatchesToolCalls(t *testing.T) {
+	var componentArgs, driftArgs []string
+	client := &scriptedToolClient{replies: []*LLMResponse{
+		{ToolCalls: []ToolCall{
+			{ID: "1", Name: "list_components", Arguments: json.RawMessage(`{"type":"ingester"}`)},
+			{ID: "2", Name: "delete_cluster", Arguments: json.RawMessage(`{}`)},
+			{ID: "3", Name: "get_drift_status"},
+		}},
+		{Content: "Ingesters are healthy."},
+	}}
+	assistant := &Assistant{llmClient: client, tools: map[string]assistantTool{
+		"list_components":  fakeTool("list_components", map[string]string{"ingester": "ready"}, &componentArgs),
+		"get_drift_status": fakeTool("get_drift_status", strings.Repeat("x", 2*maxToolResultBytes), &driftArgs),
+	}}
+
+	var events []string
+	response, err := assistant.ProcessQueryStream(context.Background(), QueryRequest{Query: "Are the ingesters healthy?"}, func(event StreamEvent) error {
+		events = append(events, event.Type)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ProcessQueryStream() error = %v", err)
+	}
+
+	if len(componentArgs) != 1 || componentArgs[0] != `{"type":"ingester"}` {
+		t.Errorf("list_components called with %v, want the model's arguments", componentArgs)
+	}
+	if len(driftArgs) != 1 || driftArgs[0] != "{}" {
+		t.Errorf("get_drift_status called with %v, want empty arguments passed as {}", driftArgs)
+	}
+	if len(client.messages) != 2 {
+		t.Fatalf("chat rounds = %d, want 2", len(client.messages))
+	}
+	followUp := client.messages[1]
+	if got := toolMessage(t, followUp, "1"); got != `{"ingester":"ready"}` {
+		t.Errorf("list_components result = %s", got)
+	}
+	if got := toolMessage(t, followUp, "2"); !strings.Contains(got, `unknown tool \"delete_cluster\"`) {
+		t.Errorf("unknown tool result = %s, want an error naming the tool", got)
+	}
+	if got := toolMessage(t, followUp, "3"); len(got) != maxToolResultBytes+len("... (truncated)") || !strings.HasSuffix(got, "... (truncated)") {
+		t.Errorf("large result is %d bytes, want it truncated to %d", len(got), maxToolResultBytes)
+	}
+
+	if response.Answer != "Ingesters are healthy." || len(response.Sources) != 3 || response.ResponseMetadata.ToolCalls != 3 {
+		t.Errorf("response = %q with %d sources, want the final answer and a source per tool call", response.Answer, len(response.Sources))
+	}
+	if response.Analysis != nil || len(response.Recommendations) != 0 {
+		t.Errorf("response has analysis %v and recommendations %v not derived from the tool results", response.Analysis, response.Recommendations)
+	}
+	if got := strings.Join(events, ","); got != "sources,token,done" {
+		t.Errorf("streamed events = %s, want sources,token,done", got)
+	}
+}
+
+func TestAgentWithholdsToolsOnFinalStep(t *testing.T) {
+	var received []string
+	client := &scriptedToolClient{fallback: &LLMResponse{
+		Content:   "Still looking.",
+		ToolCalls: []ToolCall{{ID: "1", Name: "list_components", Arguments: json.RawMessage(`{}`)}},
+	}}
+	assistant := &Assistant{llmClient: client, tools: map[string]assistantTool{
+		"list_components": fakeTool("list_components", []string{}, &received),
+	}}
+
+	response, err := assistant.ProcessQuery(context.Background(), QueryRequest{Query: "What is wrong?"})
+	if err != nil {
+		t.Fatalf("ProcessQuery() error = %v", err)
+	}
+
+	if len(client.tools) != maxAgentSteps+1 || len(received) != maxAgentSteps {
+		t.Fatalf("chat rounds = %d with %d tool runs, want %d rounds and %d runs", len(client.tools), len(received), maxAgentSteps+1, maxAgentSteps)
+	}
+	for step, tools := range client.tools[:maxAgentSteps] {
+		if len(tools) != 1 {
+			t.Errorf("step %d offered %d tools, want 1", step, len(tools))
+		}
+	}
+	if final := client.tools[maxAgentSteps]; final != nil {
+		t.Errorf("final step offered tools %v, want none", final)
+	}
+	last := client.messages[maxAgentSteps]
+	if message := last[len(last)-1]; message.Role != "user" || !strings.Contains(message.Content, "Answer now") {
+		t.Errorf("final step ends with %s message %q, want the request to answer", message.Role, message.Content)
+	}
+	if response.Answer != "Still looking." || len(response.Sources) != maxAgentSteps {
+		t.Errorf("response = %q with %d sources", response.Answer, len(response.Sources))
+	}
+}
+
+func TestRunToolArguments(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		wantRun   bool
+		wantError string
+	}{
+		{name: "object", arguments: `{"type":"ingester"}`, wantRun: true},
+		{name: "empty means no arguments", arguments: "", wantRun: true},
+		{name: "whitespace means no arguments", arguments: " \n", wantRun: true},
+		{name: "invalid JSON", arguments: `{"type":`, wantError: "not valid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []string
+			assistant := &Assistant{tools: map[string]assistantTool{
+				"list_components": fakeTool("list_components", "ok", &received),
+			}}
+
+			call := ToolCall{ID: "1", Name: "list_components", Arguments: json.RawMessage("{}")}
+			result, source := assistant.runTool(context.Background(), call, json.RawMessage(tt.arguments))
+			if ran := len(received) == 1; ran != tt.wantRun {
+				t.Errorf("tool ran = %v, want %v (result %s)", ran, tt.wantRun, result)
+			}
+			if tt.wantError != "" && (!strings.Contains(result, tt.wantError) || !strings.HasPrefix(source.Description, "Failed")) {
+				t.Errorf("result = %s (%s), want an error containing %q", result, source.Description, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAnthropicEndpoint = "https://api.anthropic.com"
+	anthropicVersion         = "2023-06-01"
+	// defaultAnthropicMaxTokens is used when neither the request nor the configuration sets a limit; the API requires one
+	defaultAnthropicMaxTokens = 1024
+)
+
+// AnthropicClient talks to the Anthropic Messages API
+type AnthropicClient struct {
+	enabled   bool
+	url       string
+	apiKey    string
+	model     string
+	maxTokens int
+	transport *httpTransport
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+// anthropicBlock is a text, tool_use or tool_result content block
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicMessagesRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicMessagesResponse struct {
+	Model      string           `json:"model"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      anthropicUsage   `json:"usage"`
+}
+
+// anthropicStreamEvent is one server-sent event of a streamed message
+type anthropicStreamEvent struct {
+	Type         string                     `json:"type"`
+	Index        int                        `json:"index"`
+	Message      *anthropicMessagesResponse `json:"message"`       // message_start
+	ContentBlock *anthropicBlock            `json:"content_block"` // content_block_start
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage"` // message_delta
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewAnthropicClient creates a new Anthropic client from the global configuration
+func NewAnthropicClient() (LLMClient, error) {
+	return NewAnthropicClientWithConfig(config.Get().LLM)
+}
+
+// NewAnthropicClientWithConfig creates an Anthropic client; Endpoint overrides the API base URL
+func NewAnthropicClientWithConfig(cfg config.LLMConfig) (*AnthropicClient, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("Anthropic API key not configured")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("Anthropic model not configured")
+	}
+
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = defaultAnthropicEndpoint
+	}
+	url := endpoint
+	if !strings.HasSuffix(url, "/v1/messages") {
+		url += "/v1/messages"
+	}
+
+	logrus.Infof("🤖 [LLM] Using Anthropic endpoint %s with model %s", endpoint, cfg.Model)
+	return &AnthropicClient{
+		enabled:   cfg.Enabled,
+		url:       url,
+		apiKey:    cfg.APIKey,
+		model:     cfg.Model,
+		maxTokens: cfg.MaxTokens,
+		transport: newHTTPTransport(cfg),
+	}, nil
+}
+
+// GenerateResponse sends the prompt as a single user message and returns the text of the reply
+func (c *AnthropicClient) GenerateResponse(ctx context.Context, prompt string, maxTokens int, temperature *float64) (*LLMResponse, error) {
+	return c.Chat(ctx, []ChatMessage{{Role: "user", Content: prompt}}, nil, maxTokens, temperature)
+}
+
+// Chat sends a conversation with the tools the model may call.
+// System messages become the system prompt and tool results are sent back as user turns.
+func (c *AnthropicClient) Chat(ctx context.Context, messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64) (*LLMResponse, error) {
+	request := c.messagesRequest(messages, tools, maxTokens, temperature)
+
+	var response anthropicMessagesResponse
+	if err := c.transport.postJSON(ctx, c.url, c.headers(), request, &response); err != nil {
+		return nil, fmt.Errorf("Anthropic messages request failed: %w", err)
+	}
+	return c.llmResponse(&response)
+}
+
+// ChatStream sends a conversation like Chat and calls onChunk with each piece of text as it is generated
+func (c *AnthropicClient) ChatStream(ctx context.Context, messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64, onChunk func(string) error) (*LLMResponse, error) {
+	request := c.messagesRequest(messages, tools, maxTokens, temperature)
+	request.Stream = true
+
+	resp, err := c.transport.stream(ctx, c.url, c.headers(), request)
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic messages request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The blocks are assembled from their deltas; tool inputs arrive as fragments of JSON
+	var response anthropicMessagesResponse
+	var inputs []string
+	err = readServerSentEvents(resp.Body, func(data []byte) (bool, error) {
+		var event anthropicStreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return false, fmt.Errorf("failed to decode Anthropic stream: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				response.Model = event.Message.Model
+				response.Usage = event.Message.Usage
+			}
+		case "content_block_start":
+			if event.ContentBlock != nil && event.Index == len(response.Content) {
+				response.Content = append(response.Content, *event.ContentBlock)
+				inputs = append(inputs, "")
+			}
+		case "content_block_delta":
+			if event.Index < 0 || event.Index >= len(response.Content) {
+				return false, nil
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				response.Content[event.Index].Text += event.Delta.Text
+				if onChunk != nil && event.Delta.Text != "" {
+					return false, onChunk(event.Delta.Text)
+				}
+			case "input_json_delta":
+				inputs[event.Index] += event.Delta.PartialJSON
+			}
+		case "message_delta":
+			response.StopReason = event.Delta.StopReason
+			if event.Usage != nil {
+				response.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "message_stop":
+			return true, nil
+		case "error":
+			if event.Error != nil {
+				return false, fmt.Errorf("%s: %s", event.Error.Type, event.Error.Message)
+			}
+			return false, fmt.Errorf("stream error: %s", string(data))
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic messages request failed: %w", err)
+	}
+
+	for i, input := range inputs {
+		if response.Content[i].Type == "tool_use" {
+			response.Content[i].Input = json.RawMessage(input)
+		}
+	}
+	return c.llmResponse(&response)
+}
+
+// messagesRequest converts a conversation to the Messages API format
+func (c *AnthropicClient) messagesRequest(messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64) anthropicMessagesRequest {
+	if maxTokens <= 0 {
+		maxTokens = c.maxTokens
+	}
+	if maxTokens <= 0 {
+		maxTokens = defaultAnthropicMaxTokens
+	}
+	// The Messages API accepts temperatures between 0 and 1 only
+	if temperature != nil && *temperature > 1 {
+		maxTemperature := 1.0
+		temperature = &maxTemperature
+	}
+
+	request := anthropicMessagesRequest{
+		Model:       c.model,
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
+	}
+	var system []string
+	for _, message := range messages {
+		switch message.Role {
+		case "system":
+			system = append(system, message.Content)
+		case "tool":
+			request.Messages = appendAnthropicBlock(request.Messages, "user", anthropicBlock{
+				Type:      "tool_result",
+				ToolUseID: message.ToolCallID,
+				Content:   message.Content,
+			})
+		default:
+			if message.Content != "" {
+				request.Messages = appendAnthropicBlock(request.Messages, message.Role, anthropicBlock{Type: "text", Text: message.Content})
+			}
+			for _, call := range message.ToolCalls {
+				request.Messages = appendAnthropicBlock(request.Messages, message.Role, anthropicBlock{
+					Type:  "tool_use",
+					ID:    call.ID,
+					Name:  call.Name,
+					Input: toolInput(call.Arguments),
+				})
+			}
+		}
+	}
+	request.System = strings.Join(system, "\n\n")
+	for _, tool := range tools {
+		request.Tools = append(request.Tools, anthropicTool{Name: tool.Name, Description: tool.Description, InputSchema: tool.Parameters})
+	}
+	return request
+}
+
+func (c *AnthropicClient) headers() map[string]string {
+	return map[string]string{
+		"x-api-key":         c.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+}
+
+func (c *AnthropicClient) llmResponse(response *anthropicMessagesResponse) (*LLMResponse, error) {
+	result := &LLMResponse{
+		Confidence:       heuristicConfidence(response.StopReason == "max_tokens"),
+		Truncated:        response.StopReason == "max_tokens",
+		TokensUsed:       response.Usage.InputTokens + response.Usage.OutputTokens,
+		PromptTokens:     response.Usage.InputTokens,
+		CompletionTokens: response.Usage.OutputTokens,
+		Model:            response.Model,
+		GeneratedAt:      time.Now(),
+	}
+	var content strings.Builder
+	for _, block := range response.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			result.ToolCalls = append(result.ToolCalls, ToolCall{ID: block.ID, Name: block.Name, Arguments: toolInput(block.Input)})
+		}
+	}
+	result.Content = content.String()
+	if result.Content == "" && len(result.ToolCalls) == 0 {
+		return nil, fmt.Errorf("Anthropic response contained no text (stop reason: %s)", response.StopReason)
+	}
+	if result.Model == "" {
+		result.Model = c.model
+	}
+	logrus.Debugf("🤖 [LLM] %s used %d input and %d output tokens (stop reason: %s)",
+		result.Model, response.Usage.InputTokens, response.Usage.OutputTokens, response.StopReason)
+
+	return result, nil
+}
+
+// toolInput is the input of a tool call; calls without arguments have an empty object as input
+func toolInput(input json.RawMessage) json.RawMessage {
+	if trimmed := bytes.TrimSpace(input); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return json.RawMessage("{}")
+	}
+	return input
+}
+
+// appendAnthropicBlock adds a block to the last message when it has the same role, as the API requires alternating roles
+func appendAnthropicBlock(messages []anthropicMessage, role string, block anthropicBlock) []anthropicMessage {
+	if len(messages) > 0 && messages[len(messages)-1].Role == role {
+		last := &messages[len(messages)-1]
+		last.Content = append(last.Content, block)
+		return messages
+	}
+	return append(messages, anthropicMessage{Role: role, Content: []anthropicBlock{block}})
+}
+
+// IsEnabled reports whether LLM integration is enabled in the configuration
+func (c *AnthropicClient) IsEnabled() bool {
+	return c.enabled
+}
@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+)
+
+// newTestAnthropicClient returns a client of a stub server that records the request body and replies with reply
+func newTestAnthropicClient(t *testing.T, reply string, contentType string) (*AnthropicClient, *anthropicMessagesRequest) {
+	t.Helper()
+	received := &anthropicMessagesRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("unexpected request %s with headers %v", r.URL, r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, received); err != nil {
+			t.Errorf("request body is not a messages request: %v", err)
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewAnthropicClientWithConfig(config.LLMConfig{
+		Enabled: true, APIKey: "test-key", Model: "test-model", Endpoint: server.URL, TimeoutSeconds: 5,
+	})
+	if err != nil {
+		t.Fatalf("NewAnthropicClientWithConfig() error = %v", err)
+	}
+	return client, received
+}
+
+// blockSummary renders a message's blocks compactly, e.g. "user: text(q) tool_result(1=r)"
+func blockSummary(message anthropicMessage) string {
+	parts := []string{message.Role + ":"}
+	for _, block := range message.Content {
+		switch block.Type {
+		case "text":
+			parts = append(parts, "text("+block.Text+")")
+		case "tool_use":
+			parts = append(parts, "tool_use("+block.ID+"="+block.Name+string(block.Input)+")")
+		case "tool_result":
+			parts = append(parts, "tool_result("+block.ToolUseID+"="+block.Content+")")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestAnthropicChatMapsMessages(t *testing.T) {
+	reply := `{"model":"test-model-2024","stop_reason":"tool_use","usage":{"input_tokens":40,"output_tokens":12},
+		"content":[{"type":"text","text":"Checking."},{"type":"tool_use","id":"call_3","name":"list_components"}]}`
+	client, received := newTestAnthropicClient(t, reply, "application/json")
+
+	temperature := 1.5
+	response, err := client.Chat(context.Background(), []ChatMessage{
+		{Role: "system", Content: "You know Mimir."},
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "Why are samples rejected?"},
+		{Role: "assistant", Content: "Let me look.", ToolCalls: []ToolCall{
+			{ID: "call_1", Name: "query_metrics", Arguments: json.RawMessage(`{"query":"up"}`)},
+			{ID: "call_2", Name: "list_components"},
+		}},
+		{Role: "tool", ToolCallID: "call_1", ToolName: "query_metrics", Content: "[]"},
+		{Role: "tool", ToolCallID: "call_2", ToolName: "list_components", Content: "{}"},
+		{Role: "user", Content: "Answer now."},
+	}, []ToolDefinition{{Name: "list_components", Description: "List components", Parameters: objectSchema(map[string]interface{}{})}}, 0, &temperature)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+
+	if received.System != "You know Mimir.\n\nBe brief." {
+		t.Errorf("system = %q, want both system messages", received.System)
+	}
+	want := []string{
+		"user: text(Why are samples rejected?)",
+		`assistant: text(Let me look.) tool_use(call_1=query_metrics{"query":"up"}) tool_use(call_2=list_components{})`,
+		"user: tool_result(call_1=[]) tool_result(call_2={}) text(Answer now.)",
+	}
+	if len(received.Messages) != len(want) {
+		t.Fatalf("sent %d messages, want %d alternating turns", len(received.Messages), len(want))
+	}
+	for i, message := range received.Messages {
+		if got := blockSummary(message); got != want[i] {
+			t.Errorf("message %d = %s, want %s", i, got, want[i])
+		}
+	}
+	if received.MaxTokens != defaultAnthropicMaxTokens || received.Temperature == nil || *received.Temperature != 1 {
+		t.Errorf("max_tokens = %d, temperature = %v; want the default and a temperature capped at 1", received.MaxTokens, received.Temperature)
+	}
+	if len(received.Tools) != 1 || received.Tools[0].Name != "list_components" || received.Tools[0].InputSchema["type"] != "object" {
+		t.Errorf("tools = %+v", received.Tools)
+	}
+
+	if response.Content != "Checking." || response.Model != "test-model-2024" || response.TokensUsed != 52 || response.Truncated {
+		t.Errorf("response = %+v", response)
+	}
+	if len(response.ToolCalls) != 1 || response.ToolCalls[0].ID != "call_3" || string(response.ToolCalls[0].Arguments) != "{}" {
+		t.Errorf("tool calls = %+v, want call_3 with {} as its arguments", response.ToolCalls)
+	}
+}
+
+func TestAppendAnthropicBlock(t *testing.T) {
+	var messages []anthropicMessage
+	messages = appendAnthropicBlock(messages, "user", anthropicBlock{Type: "text", Text: "a"})
+	messages = appendAnthropicBlock(messages, "user", anthropicBlock{Type: "tool_result", ToolUseID: "1", Content: "b"})
+	messages = appendAnthropicBlock(messages, "assistant", anthropicBlock{Type: "text", Text: "c"})
+	messages = appendAnthropicBlock(messages, "user", anthropicBlock{Type: "text", Text: "d"})
+
+	var got []string
+	for _, message := range messages {
+		got = append(got, blockSummary(message))
+	}
+	if want := "user: text(a) tool_result(1=b)|assistant: text(c)|user: text(d)"; strings.Join(got, "|") != want {
+		t.Errorf("messages = %s, want %s", strings.Join(got, "|"), want)
+	}
+}
+
+func TestAnthropicChatStream(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"model":"test-model-2024","usage":{"input_tokens":30,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me "}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"check."}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"call_1","name":"get_tenant_limits","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"ten"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"ant\": \"team-a\"}"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"call_2","name":"list_components","input":{}}}`,
+		`{"type":"content_block_stop","index":2}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":25}}`,
+		`{"type":"message_stop"}`,
+	}
+	var stream strings.Builder
+	for _, event := range events {
+		stream.WriteString("event: message\ndata: " + event + "\n\n")
+	}
+	client, received := newTestAnthropicClient(t, stream.String(), "text/event-stream")
+
+	var chunks []string
+	response, err := client.ChatStream(context.Background(), []ChatMessage{{Role: "user", Content: "Limits of team-a?"}}, nil, 0, nil,
+		func(text string) error {
+			chunks = append(chunks, text)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	if !received.Stream {
+		t.Errorf("request did not ask for a stream")
+	}
+	if strings.Join(chunks, "|") != "Let me |check." || response.Content != "Let me check." {
+		t.Errorf("chunks %q and content %q, want the text deltas", chunks, response.Content)
+	}
+	if len(response.ToolCalls) != 2 ||
+		string(response.ToolCalls[0].Arguments) != `{"tenant": "team-a"}` || response.ToolCalls[0].Name != "get_tenant_limits" ||
+		string(response.ToolCalls[1].Arguments) != "{}" || response.ToolCalls[1].ID != "call_2" {
+		t.Errorf("tool calls = %+v, want the assembled input and {} for the call without input", response.ToolCalls)
+	}
+	if response.PromptTokens != 30 || response.CompletionTokens != 25 || response.Model != "test-model-2024" {
+		t.Errorf("usage = %d/%d from %s", response.PromptTokens, response.CompletionTokens, response.Model)
+	}
+}
+
+func TestAnthropicChatStreamError(t *testing.T) {
+	stream := "event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"model\":\"test-model\"}}\n\n" +
+		"event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"
+	client, _ := newTestAnthropicClient(t, stream, "text/event-stream")
+
+	_, err := client.ChatStream(context.Background(), []ChatMessage{{Role: "user", Content: "?"}}, nil, 0, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "overloaded_error: Overloaded") {
+		t.Errorf("ChatStream() error = %v, want the error event", err)
+	}
+}
@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -16,21 +17,60 @@ type Assistant struct {
 	config        *config.Config
 	metricsClient *metrics.Client
 	llmClient     LLMClient
+	sources       DataSources
+	tools         map[string]assistantTool
 }
 
 // LLMClient interface for different LLM providers
 type LLMClient interface {
-	GenerateResponse(ctx context.Context, prompt string, maxTokens int) (*LLMResponse, error)
+	GenerateResponse(ctx context.Context, prompt string, maxTokens int, temperature *float64) (*LLMResponse, error)
 	IsEnabled() bool
 }
 
+// ToolClient is implemented by LLM clients whose model can call tools
+type ToolClient interface {
+	Chat(ctx context.Context, messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64) (*LLMResponse, error)
+}
+
+// StreamingClient is implemented by LLM clients that can pass on text as the model generates it
+type StreamingClient interface {
+	ChatStream(ctx context.Context, messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64, onChunk func(string) error) (*LLMResponse, error)
+}
+
+// ChatMessage is one turn of a tool-calling conversation
+type ChatMessage struct {
+	Role       string     `json:"role"` // "system", "user", "assistant" or "tool"
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Tools requested by the assistant
+	ToolCallID string     `json:"tool_call_id,omitempty"` // Call a tool message answers
+	ToolName   string     `json:"tool_name,omitempty"`
+}
+
+// ToolCall is a tool invocation requested by the model
+type ToolCall struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// ToolDefinition describes a tool to the model; Parameters is a JSON schema
+type ToolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
 // LLMResponse represents a response from the LLM
 type LLMResponse struct {
-	Content     string    `json:"content"`
-	Confidence  float64   `json:"confidence"`
-	TokensUsed  int       `json:"tokens_used"`
-	Model       string    `json:"model"`
-	GeneratedAt time.Time `json:"generated_at"`
+	Content          string     `json:"content"`
+	Confidence       float64    `json:"confidence"` // Heuristic, see heuristicConfidence
+	Truncated        bool       `json:"truncated"`  // The answer was cut off by the token limit
+	TokensUsed       int        `json:"tokens_used"`
+	PromptTokens     int        `json:"prompt_tokens"`
+	CompletionTokens int        `json:"completion_tokens"`
+	Model            string     `json:"model"`
+	GeneratedAt      time.Time  `json:"generated_at"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
 }
 
 // QueryRequest represents a user query to the assistant
@@ -38,7 +78,7 @@ type QueryRequest struct {
 	Query       string            `json:"query" binding:"required"`
 	Context     QueryContext      `json:"context"`
 	MaxTokens   int               `json:"max_tokens,omitempty"`
-	Temperature float64           `json:"temperature,omitempty"`
+	Temperature *float64          `json:"temperature,omitempty"` // Provider default when unset
 	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
@@ -56,9 +96,9 @@ type AssistantResponse struct {
 	Answer           string           `json:"answer"`
 	Confidence       float64          `json:"confidence"`
 	Sources          []MetricSource   `json:"sources"`
-	Recommendations  []string         `json:"recommendations"`
+	Recommendations  []string         `json:"recommendations,omitempty"`
 	RelatedQueries   []string         `json:"related_queries"`
-	Analysis         MetricAnalysis   `json:"analysis"`
+	Analysis         *MetricAnalysis  `json:"analysis,omitempty"` // Only for answers built from the prefetched metric sources
 	ResponseMetadata ResponseMetadata `json:"metadata"`
 }
 
@@ -105,12 +145,15 @@ type MetricCorrelation struct {
 
 // ResponseMetadata contains metadata about the response
 type ResponseMetadata struct {
-	ProcessingTime  time.Duration `json:"processing_time"`
-	TokensUsed      int           `json:"tokens_used"`
-	Model           string        `json:"model"`
-	QueryComplexity string        `json:"query_complexity"`
-	DataPoints      int           `json:"data_points"`
-	GeneratedAt     time.Time     `json:"generated_at"`
+	ProcessingTime   time.Duration `json:"processing_time"`
+	TokensUsed       int           `json:"tokens_used"`
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	ToolCalls        int           `json:"tool_calls"`
+	Model            string        `json:"model"`
+	QueryComplexity  string        `json:"query_complexity"`
+	DataPoints       int           `json:"data_points"`
+	GeneratedAt      time.Time     `json:"generated_at"`
 }
 
 // NewAssistant creates a new LLM assistant
@@ -136,7 +179,7 @@ func NewAssistant() (*Assistant, error) {
 	}
 
 	if err != nil {
-		logrus.Warnf("Failed to initialize LLM client: %v", err)
+		logrus.Errorf("Failed to initialize %s LLM client, LLM queries are disabled: %v", cfg.LLM.Provider, err)
 		return &Assistant{
 			config:        cfg,
 			metricsClient: nil,
@@ -146,17 +189,32 @@ func NewAssistant() (*Assistant, error) {
 
 	return &Assistant{
 		config:        cfg,
-		metricsClient: nil, // TODO: Initialize metrics client
+		metricsClient: nil, // Set through SetDataSources
 		llmClient:     llmClient,
 	}, nil
 }
 
 // ProcessQuery processes a natural language query about metrics
 func (a *Assistant) ProcessQuery(ctx context.Context, req QueryRequest) (*AssistantResponse, error) {
+	return a.ProcessQueryStream(ctx, req, nil)
+}
+
+// ProcessQueryStream processes a query like ProcessQuery and reports progress and answer text to emit as they are produced.
+// Nothing is emitted when no LLM is available, so callers can still answer with an error status.
+func (a *Assistant) ProcessQueryStream(ctx context.Context, req QueryRequest, emit func(StreamEvent) error) (*AssistantResponse, error) {
 	start := time.Now()
 
+	if a.llmClient == nil || !a.llmClient.IsEnabled() {
+		return nil, ErrLLMUnavailable
+	}
+
 	logrus.Infof("Processing LLM query: %s", req.Query)
 
+	// Let the model gather the data it needs when it can call tools
+	if toolClient, ok := a.llmClient.(ToolClient); ok && len(a.tools) > 0 {
+		return a.processWithTools(ctx, toolClient, req, start, emit)
+	}
+
 	// Analyze query intent and extract context
 	queryIntent := a.analyzeQueryIntent(req.Query)
 
@@ -165,12 +223,24 @@ func (a *Assistant) ProcessQuery(ctx context.Context, req QueryRequest) (*Assist
 	if err != nil {
 		return nil, fmt.Errorf("failed to gather relevant data: %w", err)
 	}
+	if err := emitEvent(emit, StreamEventSources, metricSources); err != nil {
+		return nil, err
+	}
 
 	// Build context-aware prompt
 	prompt := a.buildPrompt(req, metricSources, queryIntent)
 
 	// Get LLM response
-	llmResponse, err := a.llmClient.GenerateResponse(ctx, prompt, req.MaxTokens)
+	var llmResponse *LLMResponse
+	if streamingClient, ok := a.llmClient.(StreamingClient); ok && emit != nil {
+		llmResponse, err = streamingClient.ChatStream(ctx, []ChatMessage{{Role: "user", Content: prompt}}, nil,
+			req.MaxTokens, req.Temperature, tokenEmitter(emit))
+	} else {
+		llmResponse, err = a.llmClient.GenerateResponse(ctx, prompt, req.MaxTokens, req.Temperature)
+		if err == nil {
+			err = emitEvent(emit, StreamEventToken, TokenEvent{Text: llmResponse.Content})
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get LLM response: %w", err)
 	}
@@ -190,20 +260,25 @@ func (a *Assistant) ProcessQuery(ctx context.Context, req QueryRequest) (*Assist
 		Sources:         metricSources,
 		Recommendations: recommendations,
 		RelatedQueries:  relatedQueries,
-		Analysis:        analysis,
+		Analysis:        &analysis,
 		ResponseMetadata: ResponseMetadata{
-			ProcessingTime:  time.Since(start),
-			TokensUsed:      llmResponse.TokensUsed,
-			Model:           llmResponse.Model,
-			QueryComplexity: a.assessQueryComplexity(req.Query),
-			DataPoints:      len(metricSources),
-			GeneratedAt:     time.Now(),
+			ProcessingTime:   time.Since(start),
+			TokensUsed:       llmResponse.TokensUsed,
+			PromptTokens:     llmResponse.PromptTokens,
+			CompletionTokens: llmResponse.CompletionTokens,
+			Model:            llmResponse.Model,
+			QueryComplexity:  a.assessQueryComplexity(req.Query),
+			DataPoints:       len(metricSources),
+			GeneratedAt:      time.Now(),
 		},
 	}
 
 	logrus.Infof("Processed LLM query in %v, used %d tokens",
 		response.ResponseMetadata.ProcessingTime, response.ResponseMetadata.TokensUsed)
 
+	if err := emitResponse(emit, response); err != nil {
+		return nil, err
+	}
 	return response, nil
 }
 
@@ -284,6 +359,9 @@ func (a *Assistant) gatherRelevantData(ctx context.Context, req QueryRequest, in
 	metricNames := a.selectRelevantMetrics(intent.MetricTypes, req.Context.TenantName)
 
 	for _, metricName := range metricNames {
+		if a.metricsClient == nil {
+			break
+		}
 		tenantMetrics, err := a.metricsClient.GetTenantMetrics(ctx, req.Context.TenantName, timeRange)
 		if err != nil {
 			logrus.Warnf("Failed to get metrics for %s: %v", metricName, err)
@@ -613,7 +691,7 @@ func calculateAverage(values []float64) float64 {
 // GetAssistantCapabilities returns the capabilities of the assistant
 func (a *Assistant) GetAssistantCapabilities() map[string]interface{} {
 	return map[string]interface{}{
-		"enabled": a.llmClient.IsEnabled(),
+		"enabled": a.llmClient != nil && a.llmClient.IsEnabled(),
 		"supported_queries": []string{
 			"Metrics analysis and interpretation",
 			"Troubleshooting assistance",
@@ -621,6 +699,7 @@ func (a *Assistant) GetAssistantCapabilities() map[string]interface{} {
 			"Root cause analysis",
 			"Configuration recommendations",
 			"Capacity planning insights",
+			"PromQL generation from questions",
 		},
 		"supported_intents": []string{
 			"troubleshooting", "trending", "comparison",
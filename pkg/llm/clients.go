@@ -1,47 +1,209 @@
 package llm
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/akshaydubey29/mimirInsights/pkg/config"
 	"github.com/sirupsen/logrus"
 )
 
-// NewOpenAIClient creates a new OpenAI client
-func NewOpenAIClient() (LLMClient, error) {
-	cfg := config.Get()
+// ErrLLMUnavailable is returned when no LLM provider is configured and enabled
+var ErrLLMUnavailable = errors.New("LLM integration is not available")
 
-	if cfg.LLM.APIKey == "" {
-		return nil, fmt.Errorf("OpenAI API key not configured")
+const (
+	defaultLLMTimeout   = 60 * time.Second
+	defaultRetryBackoff = time.Second
+	maxRetryBackoff     = 30 * time.Second
+)
+
+// httpTransport calls an LLM API, retrying rate limits and server errors
+type httpTransport struct {
+	httpClient   *http.Client
+	timeout      time.Duration // Bounds each attempt of a JSON call, and the wait for the headers of a stream
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// newHTTPTransport creates a transport with the timeout and retries of the LLM configuration
+func newHTTPTransport(cfg config.LLMConfig) *httpTransport {
+	timeout := defaultLLMTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	maxRetries := cfg.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = 0
 	}
+	// No client-wide timeout: it would also cut off streamed answers that take longer to generate
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	return &httpTransport{
+		httpClient:   &http.Client{Transport: transport},
+		timeout:      timeout,
+		maxRetries:   maxRetries,
+		retryBackoff: defaultRetryBackoff,
+	}
+}
 
-	// TODO: Implement actual OpenAI client
-	logrus.Info("OpenAI integration not yet implemented")
-	return nil, fmt.Errorf("OpenAI integration not yet implemented")
+// apiError is a non-successful response from an LLM API
+type apiError struct {
+	StatusCode int
+	Body       string
 }
 
-// NewAnthropicClient creates a new Anthropic client
-func NewAnthropicClient() (LLMClient, error) {
-	cfg := config.Get()
+func (e *apiError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.StatusCode, e.Body)
+}
 
-	if cfg.LLM.APIKey == "" {
-		return nil, fmt.Errorf("Anthropic API key not configured")
+// postJSON sends payload to url and decodes the JSON response into result
+func (t *httpTransport) postJSON(ctx context.Context, url string, headers map[string]string, payload, result interface{}) error {
+	resp, err := t.do(ctx, http.MethodPost, url, headers, payload, t.timeout)
+	if err != nil {
+		return err
 	}
+	return decodeResponse(resp, result)
+}
 
-	// TODO: Implement actual Anthropic client
-	logrus.Info("Anthropic integration not yet implemented")
-	return nil, fmt.Errorf("Anthropic integration not yet implemented")
+// getJSON fetches url and decodes the JSON response into result
+func (t *httpTransport) getJSON(ctx context.Context, url string, headers map[string]string, result interface{}) error {
+	resp, err := t.do(ctx, http.MethodGet, url, headers, nil, t.timeout)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(resp, result)
+}
+
+// stream sends payload to url and returns the open response once it succeeds; the caller reads and closes the body.
+// Only the wait for the response headers is bounded, an answer may take longer than the timeout to stream.
+func (t *httpTransport) stream(ctx context.Context, url string, headers map[string]string, payload interface{}) (*http.Response, error) {
+	return t.do(ctx, http.MethodPost, url, headers, payload, 0)
 }
 
-// NewOllamaClient creates a new Ollama client
-func NewOllamaClient() (LLMClient, error) {
-	cfg := config.Get()
+// do sends the request and returns the open response once it succeeds; the caller closes the body.
+// A positive attemptTimeout bounds each attempt including reading the body.
+func (t *httpTransport) do(ctx context.Context, method, url string, headers map[string]string, payload interface{}, attemptTimeout time.Duration) (*http.Response, error) {
+	var body []byte
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = encoded
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if attemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, attemptTimeout)
+		}
+		req, err := http.NewRequestWithContext(attemptCtx, method, url, bytes.NewReader(body))
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := t.httpClient.Do(req)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("request to %s failed: %w", url, err)
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
 
-	if cfg.LLM.Endpoint == "" {
-		return nil, fmt.Errorf("Ollama URL not configured")
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		cancel()
+		callErr := &apiError{StatusCode: resp.StatusCode, Body: string(respBody)}
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retryable || attempt >= t.maxRetries {
+			return nil, callErr
+		}
+
+		delay := t.retryDelay(attempt, resp.Header.Get("Retry-After"))
+		logrus.Warnf("⚠️ [LLM] %s returned %d, retrying in %v (attempt %d/%d)", url, resp.StatusCode, delay, attempt+1, t.maxRetries)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
 	}
+}
 
-	// TODO: Implement actual Ollama client
-	logrus.Info("Ollama integration not yet implemented")
-	return nil, fmt.Errorf("Ollama integration not yet implemented")
+// cancelOnClose releases the context of an attempt once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func decodeResponse(resp *http.Response, result interface{}) error {
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// readServerSentEvents calls onData with the payload of every "data:" line until the stream ends or onData stops it
+func readServerSentEvents(body io.Reader, onData func(data []byte) (done bool, err error)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := bytes.CutPrefix(scanner.Bytes(), []byte("data:"))
+		if !ok {
+			// Event names, comments and keep-alives carry nothing the clients need
+			continue
+		}
+		done, err := onData(bytes.TrimSpace(data))
+		if err != nil || done {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return fmt.Errorf("event stream ended before completion")
+}
+
+// retryDelay backs off exponentially unless the server asked for a specific delay
+func (t *httpTransport) retryDelay(attempt int, retryAfter string) time.Duration {
+	delay := t.retryBackoff << attempt
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	}
+	if delay > maxRetryBackoff || delay < 0 {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
+
+// heuristicConfidence is a fixed estimate rather than a score from the model, which chat APIs do not report:
+// an answer cut off by the token limit gets 0.5, any other answer 0.9
+func heuristicConfidence(truncated bool) float64 {
+	if truncated {
+		return 0.5
+	}
+	return 0.9
 }
@@ -0,0 +1,289 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+)
+
+func newTestTransport(maxRetries int) *httpTransport {
+	transport := newHTTPTransport(config.LLMConfig{TimeoutSeconds: 5, MaxRetries: maxRetries})
+	transport.retryBackoff = time.Millisecond
+	return transport
+}
+
+func TestPostJSONRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   int
+		statuses     []int
+		wantStatus   int // Status of the returned apiError, 0 for success
+		wantAttempts int32
+	}{
+		{name: "success", maxRetries: 2, statuses: []int{200}, wantAttempts: 1},
+		{name: "rate limit then success", maxRetries: 2, statuses: []int{429, 200}, wantAttempts: 2},
+		{name: "server errors then success", maxRetries: 2, statuses: []int{502, 503, 200}, wantAttempts: 3},
+		{name: "retries exhausted", maxRetries: 1, statuses: []int{500, 500, 200}, wantStatus: 500, wantAttempts: 2},
+		{name: "client error is not retried", maxRetries: 3, statuses: []int{400, 200}, wantStatus: 400, wantAttempts: 1},
+		{name: "no retries configured", maxRetries: 0, statuses: []int{429, 200}, wantStatus: 429, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[atomic.AddInt32(&attempts, 1)-1]
+				if status != http.StatusOK {
+					w.Header().Set("Retry-After", "0")
+					http.Error(w, `{"error":{"message":"try later"}}`, status)
+					return
+				}
+				w.Write([]byte(`{"ok":true}`))
+			}))
+			defer server.Close()
+
+			var result struct{ OK bool }
+			err := newTestTransport(tt.maxRetries).postJSON(context.Background(), server.URL, nil, map[string]string{"a": "b"}, &result)
+
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil || !result.OK {
+					t.Fatalf("postJSON() = %v, %v, want success", result, err)
+				}
+				return
+			}
+			var callErr *apiError
+			if !errors.As(err, &callErr) || callErr.StatusCode != tt.wantStatus {
+				t.Fatalf("postJSON() error = %v, want status %d", err, tt.wantStatus)
+			}
+			if !strings.Contains(callErr.Body, "try later") {
+				t.Errorf("error body = %q, want the API's message", callErr.Body)
+			}
+		})
+	}
+}
+
+func TestRetryWaitsForRetryAfter(t *testing.T) {
+	var attempts int32
+	var first time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if waited := time.Since(first); waited < time.Second {
+			t.Errorf("retried after %v, want at least the 1s of Retry-After", waited)
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var result struct{}
+	if err := newTestTransport(1).postJSON(context.Background(), server.URL, nil, nil, &result); err != nil {
+		t.Fatalf("postJSON() error = %v", err)
+	}
+}
+
+func TestRetryStopsWhenContextIsCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var result struct{}
+	if err := newTestTransport(3).postJSON(ctx, server.URL, nil, nil, &result); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("postJSON() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	transport := &httpTransport{retryBackoff: time.Second}
+	tests := []struct {
+		attempt    int
+		retryAfter string
+		want       time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 2, want: 4 * time.Second},
+		{attempt: 10, want: maxRetryBackoff},
+		{attempt: 0, retryAfter: "7", want: 7 * time.Second},
+		{attempt: 0, retryAfter: "0", want: 0},
+		{attempt: 0, retryAfter: "3600", want: maxRetryBackoff},
+		{attempt: 1, retryAfter: "Wed, 21 Oct 2015 07:28:00 GMT", want: 2 * time.Second},
+		{attempt: 1, retryAfter: "-5", want: 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := transport.retryDelay(tt.attempt, tt.retryAfter); got != tt.want {
+			t.Errorf("retryDelay(%d, %q) = %v, want %v", tt.attempt, tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestStreamOutlivesTheTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{"one", "two", "[DONE]"} {
+			w.Write([]byte("data: " + chunk + "\n\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(150 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	transport := newTestTransport(0)
+	transport.timeout = 200 * time.Millisecond
+	transport.httpClient.Transport.(*http.Transport).ResponseHeaderTimeout = transport.timeout
+
+	resp, err := transport.stream(context.Background(), server.URL, nil, nil)
+	if err != nil {
+		t.Fatalf("stream() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var chunks []string
+	err = readServerSentEvents(resp.Body, func(data []byte) (bool, error) {
+		if string(data) == "[DONE]" {
+			return true, nil
+		}
+		chunks = append(chunks, string(data))
+		return false, nil
+	})
+	if err != nil || len(chunks) != 2 {
+		t.Errorf("stream read %v, %v, want both chunks after the timeout passed", chunks, err)
+	}
+}
+
+func TestReadServerSentEvents(t *testing.T) {
+	stopErr := errors.New("stop")
+	tests := []struct {
+		name      string
+		body      string
+		stopAt    string // Payload that ends the stream
+		failAt    string // Payload that onData rejects
+		want      []string
+		wantError error
+		wantEnded bool // The stream ended without completing
+	}{
+		{
+			name:   "data lines until done",
+			body:   "data: {\"a\":1}\n\ndata:{\"b\":2}\n\ndata: [DONE]\n\n",
+			stopAt: "[DONE]",
+			want:   []string{`{"a":1}`, `{"b":2}`, "[DONE]"},
+		},
+		{
+			name:   "events, comments and keep-alives are skipped",
+			body:   "event: message_start\ndata: start\n\n: keep-alive\n\nevent: ping\n\nretry: 100\ndata: stop\n",
+			stopAt: "stop",
+			want:   []string{"start", "stop"},
+		},
+		{
+			name:   "CRLF line endings",
+			body:   "data: one\r\n\r\ndata: stop\r\n\r\n",
+			stopAt: "stop",
+			want:   []string{"one", "stop"},
+		},
+		{
+			name:      "stream ends early",
+			body:      "data: one\n\n",
+			stopAt:    "stop",
+			want:      []string{"one"},
+			wantEnded: true,
+		},
+		{
+			name:      "callback error stops reading",
+			body:      "data: one\n\ndata: bad\n\ndata: stop\n\n",
+			stopAt:    "stop",
+			failAt:    "bad",
+			want:      []string{"one", "bad"},
+			wantError: stopErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := readServerSentEvents(strings.NewReader(tt.body), func(data []byte) (bool, error) {
+				got = append(got, string(data))
+				if string(data) == tt.failAt {
+					return false, stopErr
+				}
+				return string(data) == tt.stopAt, nil
+			})
+
+			switch {
+			case tt.wantError != nil:
+				if !errors.Is(err, tt.wantError) {
+					t.Errorf("error = %v, want %v", err, tt.wantError)
+				}
+			case tt.wantEnded:
+				if err == nil {
+					t.Error("error = nil, want an error for the incomplete stream")
+				}
+			case err != nil:
+				t.Errorf("error = %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("payloads = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemperatureEncoding(t *testing.T) {
+	zero, warm, hot := 0.0, 0.7, 1.5
+	messages := []ChatMessage{{Role: "user", Content: "hi"}}
+
+	tests := []struct {
+		name        string
+		temperature *float64
+		want        string // Expected JSON fragment, empty when the field must be absent
+	}{
+		{name: "unset uses the provider default", temperature: nil},
+		{name: "explicit zero is sent", temperature: &zero, want: `"temperature":0`},
+		{name: "other values are sent", temperature: &warm, want: `"temperature":0.7`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := map[string]interface{}{
+				"openai":    (&OpenAIClient{model: "m"}).chatRequest(messages, nil, 10, tt.temperature),
+				"anthropic": (&AnthropicClient{model: "m"}).messagesRequest(messages, nil, 10, tt.temperature),
+				"ollama":    (&OllamaClient{model: "m"}).chatRequest(messages, nil, 10, tt.temperature),
+			}
+			for provider, request := range requests {
+				encoded, err := json.Marshal(request)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tt.want == "" && strings.Contains(string(encoded), "temperature") {
+					t.Errorf("%s request %s, want no temperature", provider, encoded)
+				}
+				if tt.want != "" && !strings.Contains(string(encoded), tt.want) {
+					t.Errorf("%s request %s, want %s", provider, encoded, tt.want)
+				}
+			}
+		})
+	}
+
+	// The Messages API rejects temperatures above 1
+	encoded, _ := json.Marshal((&AnthropicClient{model: "m"}).messagesRequest(messages, nil, 10, &hot))
+	if !strings.Contains(string(encoded), `"temperature":1`) {
+		t.Errorf("anthropic request %s, want the temperature clamped to 1", encoded)
+	}
+}
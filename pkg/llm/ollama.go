@@ -0,0 +1,275 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// OllamaClient talks to a self-hosted Ollama server through its chat API
+type OllamaClient struct {
+	enabled   bool
+	endpoint  string
+	model     string
+	maxTokens int
+	transport *httpTransport
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"` // Object, not a JSON-encoded string
+	} `json:"function"`
+}
+
+type ollamaTool struct {
+	Type     string         `json:"type"`
+	Function ToolDefinition `json:"function"`
+}
+
+type ollamaOptions struct {
+	NumPredict  int      `json:"num_predict,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []ollamaTool    `json:"tools,omitempty"`
+	Stream   bool            `json:"stream"`
+	Options  ollamaOptions   `json:"options"`
+}
+
+// ollamaChatChunk is one line of a streamed chat response; the last one carries done and the token counts
+type ollamaChatChunk struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+// NewOllamaClient creates a new Ollama client from the global configuration
+func NewOllamaClient() (LLMClient, error) {
+	return NewOllamaClientWithConfig(config.Get().LLM)
+}
+
+// NewOllamaClientWithConfig creates an Ollama client and checks that the configured model is pulled.
+// An unreachable server is only logged so the assistant recovers once Ollama comes up.
+func NewOllamaClientWithConfig(cfg config.LLMConfig) (*OllamaClient, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("Ollama URL not configured")
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("Ollama model not configured")
+	}
+
+	client := &OllamaClient{
+		enabled:   cfg.Enabled,
+		endpoint:  strings.TrimRight(cfg.Endpoint, "/"),
+		model:     cfg.Model,
+		maxTokens: cfg.MaxTokens,
+		transport: newHTTPTransport(cfg),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		logrus.Warnf("⚠️ [LLM] Could not list Ollama models at %s: %v", client.endpoint, err)
+		return client, nil
+	}
+	if !hasOllamaModel(models, cfg.Model) {
+		return nil, fmt.Errorf("Ollama model %s is not available at %s (available: %s)",
+			cfg.Model, client.endpoint, strings.Join(models, ", "))
+	}
+
+	logrus.Infof("🤖 [LLM] Using Ollama at %s with model %s", client.endpoint, cfg.Model)
+	return client, nil
+}
+
+// ListModels returns the models pulled on the Ollama server
+func (c *OllamaClient) ListModels(ctx context.Context) ([]string, error) {
+	var tags ollamaTagsResponse
+	if err := c.transport.getJSON(ctx, c.endpoint+"/api/tags", nil, &tags); err != nil {
+		return nil, fmt.Errorf("failed to list Ollama models: %w", err)
+	}
+	models := make([]string, 0, len(tags.Models))
+	for _, model := range tags.Models {
+		models = append(models, model.Name)
+	}
+	return models, nil
+}
+
+// GenerateResponse returns the complete reply to the prompt
+func (c *OllamaClient) GenerateResponse(ctx context.Context, prompt string, maxTokens int, temperature *float64) (*LLMResponse, error) {
+	return c.StreamResponse(ctx, prompt, maxTokens, temperature, nil)
+}
+
+// StreamResponse streams the reply to the prompt, calling onChunk with each piece of text as it is generated
+func (c *OllamaClient) StreamResponse(ctx context.Context, prompt string, maxTokens int, temperature *float64, onChunk func(string) error) (*LLMResponse, error) {
+	return c.ChatStream(ctx, []ChatMessage{{Role: "user", Content: prompt}}, nil, maxTokens, temperature, onChunk)
+}
+
+// ChatStream sends a conversation like Chat and calls onChunk with each piece of text as it is generated
+func (c *OllamaClient) ChatStream(ctx context.Context, messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64, onChunk func(string) error) (*LLMResponse, error) {
+	request := c.chatRequest(messages, tools, maxTokens, temperature)
+	request.Stream = true
+
+	resp, err := c.transport.stream(ctx, c.endpoint+"/api/chat", nil, request)
+	if err != nil {
+		return nil, fmt.Errorf("Ollama chat request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var content strings.Builder
+	var toolCalls []ollamaToolCall
+	var last ollamaChatChunk
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode Ollama response: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("Ollama chat request failed: %s", chunk.Error)
+		}
+
+		content.WriteString(chunk.Message.Content)
+		// Tool calls are sent whole, not in fragments
+		toolCalls = append(toolCalls, chunk.Message.ToolCalls...)
+		if onChunk != nil && chunk.Message.Content != "" {
+			if err := onChunk(chunk.Message.Content); err != nil {
+				return nil, err
+			}
+		}
+		last = chunk
+		if chunk.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Ollama response: %w", err)
+	}
+	if !last.Done {
+		return nil, fmt.Errorf("Ollama response ended before completion")
+	}
+
+	last.Message.Content = content.String()
+	last.Message.ToolCalls = toolCalls
+	return c.llmResponse(&last), nil
+}
+
+// Chat sends a conversation with the tools the model may call and waits for the complete reply.
+// Ollama does not identify tool calls, so calls are numbered in the order they were requested.
+func (c *OllamaClient) Chat(ctx context.Context, messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64) (*LLMResponse, error) {
+	request := c.chatRequest(messages, tools, maxTokens, temperature)
+
+	var response ollamaChatChunk
+	if err := c.transport.postJSON(ctx, c.endpoint+"/api/chat", nil, request, &response); err != nil {
+		return nil, fmt.Errorf("Ollama chat request failed: %w", err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("Ollama chat request failed: %s", response.Error)
+	}
+	return c.llmResponse(&response), nil
+}
+
+// chatRequest converts a conversation to the Ollama chat format
+func (c *OllamaClient) chatRequest(messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64) ollamaChatRequest {
+	if maxTokens <= 0 {
+		maxTokens = c.maxTokens
+	}
+	request := ollamaChatRequest{
+		Model:    c.model,
+		Messages: make([]ollamaMessage, 0, len(messages)),
+		Options: ollamaOptions{
+			NumPredict:  maxTokens,
+			Temperature: temperature,
+		},
+	}
+	for _, message := range messages {
+		converted := ollamaMessage{Role: message.Role, Content: message.Content, ToolName: message.ToolName}
+		for _, call := range message.ToolCalls {
+			var toolCall ollamaToolCall
+			toolCall.Function.Name = call.Name
+			toolCall.Function.Arguments = call.Arguments
+			converted.ToolCalls = append(converted.ToolCalls, toolCall)
+		}
+		request.Messages = append(request.Messages, converted)
+	}
+	for _, tool := range tools {
+		request.Tools = append(request.Tools, ollamaTool{Type: "function", Function: tool})
+	}
+	return request
+}
+
+// llmResponse converts the final chat response, which carries the token counts
+func (c *OllamaClient) llmResponse(response *ollamaChatChunk) *LLMResponse {
+	model := response.Model
+	if model == "" {
+		model = c.model
+	}
+	logrus.Debugf("🤖 [LLM] %s used %d prompt and %d completion tokens (done reason: %s)",
+		model, response.PromptEvalCount, response.EvalCount, response.DoneReason)
+
+	result := &LLMResponse{
+		Content:          response.Message.Content,
+		Confidence:       heuristicConfidence(response.DoneReason == "length"),
+		Truncated:        response.DoneReason == "length",
+		TokensUsed:       response.PromptEvalCount + response.EvalCount,
+		PromptTokens:     response.PromptEvalCount,
+		CompletionTokens: response.EvalCount,
+		Model:            model,
+		GeneratedAt:      time.Now(),
+	}
+	for i, call := range response.Message.ToolCalls {
+		result.ToolCalls = append(result.ToolCalls, ToolCall{
+			ID:        fmt.Sprintf("call_%d", i),
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return result
+}
+
+// IsEnabled reports whether LLM integration is enabled in the configuration
+func (c *OllamaClient) IsEnabled() bool {
+	return c.enabled
+}
+
+// hasOllamaModel matches a configured model against pulled ones, where an untagged name means ":latest"
+func hasOllamaModel(models []string, model string) bool {
+	for _, available := range models {
+		if available == model || (!strings.Contains(model, ":") && available == model+":latest") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+)
+
+const ollamaTags = `{"models":[{"name":"llama3.1:latest"},{"name":"qwen2.5:7b"}]}`
+
+// newTestOllamaClient returns a client of a stub server that lists ollamaTags and answers chats with chat.
+// The decoded chat request body is stored in received.
+func newTestOllamaClient(t *testing.T, chat string, received *map[string]interface{}) *OllamaClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(ollamaTags))
+		case "/api/chat":
+			body, _ := io.ReadAll(r.Body)
+			if received != nil {
+				if err := json.Unmarshal(body, received); err != nil {
+					t.Errorf("chat request is not JSON: %v", err)
+				}
+			}
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Write([]byte(chat))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewOllamaClientWithConfig(config.LLMConfig{Enabled: true, Endpoint: server.URL + "/", Model: "llama3.1", TimeoutSeconds: 5})
+	if err != nil {
+		t.Fatalf("NewOllamaClientWithConfig() error = %v", err)
+	}
+	return client
+}
+
+func TestHasOllamaModel(t *testing.T) {
+	models := []string{"llama3.1:latest", "qwen2.5:7b"}
+	tests := []struct {
+		model string
+		want  bool
+	}{
+		{model: "llama3.1:latest", want: true},
+		{model: "llama3.1", want: true},
+		{model: "qwen2.5:7b", want: true},
+		{model: "qwen2.5", want: false}, // Only :latest is implied
+		{model: "llama3.1:8b", want: false},
+		{model: "mistral", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := hasOllamaModel(models, tt.model); got != tt.want {
+			t.Errorf("hasOllamaModel(%s) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestNewOllamaClientChecksTheModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.Write([]byte(ollamaTags))
+	}))
+	defer server.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	tests := []struct {
+		name     string
+		endpoint string
+		model    string
+		wantErr  bool
+	}{
+		{name: "pulled model", endpoint: server.URL, model: "qwen2.5:7b"},
+		{name: "untagged model means latest", endpoint: server.URL, model: "llama3.1"},
+		{name: "model not pulled", endpoint: server.URL, model: "mistral", wantErr: true},
+		{name: "unreachable server is not fatal", endpoint: unreachable.URL, model: "mistral"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOllamaClientWithConfig(config.LLMConfig{Endpoint: tt.endpoint, Model: tt.model, TimeoutSeconds: 1})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewOllamaClientWithConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOllamaChatStream(t *testing.T) {
+	chat := `{"model":"llama3.1","message":{"role":"assistant","content":"Checking "},"done":false}
+
+{"model":"llama3.1","message":{"role":"assistant","content":"limits."},"done":false}
+{"model":"llama3.1","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_tenant_limits","arguments":{"tenant":"team-a"}}}]},"done":false}
+{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":20,"eval_count":8}
+`
+	var received map[string]interface{}
+	client := newTestOllamaClient(t, chat, &received)
+
+	var chunks []string
+	response, err := client.ChatStream(context.Background(), []ChatMessage{{Role: "user", Content: "Limits of team-a?"}}, nil, 0, nil,
+		func(text string) error {
+			chunks = append(chunks, text)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+
+	if received["stream"] != true {
+		t.Errorf("request stream = %v, want true", received["stream"])
+	}
+	if strings.Join(chunks, "|") != "Checking |limits." || response.Content != "Checking limits." {
+		t.Errorf("chunks %q and content %q, want the streamed text", chunks, response.Content)
+	}
+	if len(response.ToolCalls) != 1 || response.ToolCalls[0].ID != "call_0" || string(response.ToolCalls[0].Arguments) != `{"tenant":"team-a"}` {
+		t.Errorf("tool calls = %+v", response.ToolCalls)
+	}
+	if response.PromptTokens != 20 || response.CompletionTokens != 8 || response.Truncated {
+		t.Errorf("usage = %d/%d, truncated %v", response.PromptTokens, response.CompletionTokens, response.Truncated)
+	}
+}
+
+func TestOllamaChatStreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		chat    string
+		wantErr string
+	}{
+		{
+			name:    "ended before done",
+			chat:    `{"model":"llama3.1","message":{"role":"assistant","content":"Partial"},"done":false}` + "\n",
+			wantErr: "ended before completion",
+		},
+		{
+			name:    "error line",
+			chat:    `{"error":"model requires more system memory"}` + "\n",
+			wantErr: "model requires more system memory",
+		},
+		{
+			name:    "not NDJSON",
+			chat:    "<html></html>\n",
+			wantErr: "failed to decode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestOllamaClient(t, tt.chat, nil)
+			_, err := client.ChatStream(context.Background(), []ChatMessage{{Role: "user", Content: "?"}}, nil, 0, nil, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ChatStream() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOllamaChatDoesNotStream(t *testing.T) {
+	var received map[string]interface{}
+	client := newTestOllamaClient(t, `{"model":"llama3.1","message":{"role":"assistant","content":"Done."},"done":true,"done_reason":"length"}`, &received)
+
+	response, err := client.Chat(context.Background(), []ChatMessage{{Role: "user", Content: "?"}}, nil, 0, nil)
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	if stream, sent := received["stream"]; !sent || stream != false {
+		t.Errorf("request stream = %v (sent %v), want false sent explicitly since Ollama streams by default", stream, sent)
+	}
+	if response.Content != "Done." || !response.Truncated {
+		t.Errorf("response = %+v, want the content marked truncated", response)
+	}
+}
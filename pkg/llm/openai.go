@@ -0,0 +1,296 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultOpenAIEndpoint is used when no OpenAI-compatible gateway is configured
+const defaultOpenAIEndpoint = "https://api.openai.com/v1"
+
+// OpenAIClient talks to any API implementing the OpenAI chat-completions wire format
+type OpenAIClient struct {
+	enabled   bool
+	url       string
+	apiKey    string
+	model     string
+	maxTokens int
+	transport *httpTransport
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON-encoded object
+	} `json:"function"`
+}
+
+type openAITool struct {
+	Type     string         `json:"type"`
+	Function ToolDefinition `json:"function"`
+}
+
+type openAIChatRequest struct {
+	Model         string               `json:"model"`
+	Messages      []openAIMessage      `json:"messages"`
+	Tools         []openAITool         `json:"tools,omitempty"`
+	MaxTokens     int                  `json:"max_tokens,omitempty"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type openAIChatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      openAIMessage `json:"message"`
+		FinishReason string        `json:"finish_reason"`
+	} `json:"choices"`
+	Usage openAIUsage `json:"usage"`
+}
+
+// openAIChatChunk is one event of a streamed chat completion; tool calls arrive in pieces keyed by index
+type openAIChatChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    int    `json:"index"`
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+// NewOpenAIClient creates a new OpenAI client from the global configuration
+func NewOpenAIClient() (LLMClient, error) {
+	return NewOpenAIClientWithConfig(config.Get().LLM)
+}
+
+// NewOpenAIClientWithConfig creates an OpenAI client; Endpoint may point at any compatible gateway
+func NewOpenAIClientWithConfig(cfg config.LLMConfig) (*OpenAIClient, error) {
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		// Self-hosted gateways often run without authentication, api.openai.com never does
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("OpenAI API key not configured")
+		}
+		endpoint = defaultOpenAIEndpoint
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("OpenAI model not configured")
+	}
+
+	url := endpoint
+	if !strings.HasSuffix(url, "/chat/completions") {
+		url += "/chat/completions"
+	}
+
+	logrus.Infof("🤖 [LLM] Using OpenAI-compatible endpoint %s with model %s", endpoint, cfg.Model)
+	return &OpenAIClient{
+		enabled:   cfg.Enabled,
+		url:       url,
+		apiKey:    cfg.APIKey,
+		model:     cfg.Model,
+		maxTokens: cfg.MaxTokens,
+		transport: newHTTPTransport(cfg),
+	}, nil
+}
+
+// GenerateResponse sends the prompt as a chat completion and returns the first choice
+func (c *OpenAIClient) GenerateResponse(ctx context.Context, prompt string, maxTokens int, temperature *float64) (*LLMResponse, error) {
+	return c.Chat(ctx, []ChatMessage{{Role: "user", Content: prompt}}, nil, maxTokens, temperature)
+}
+
+// Chat sends a conversation with the tools the model may call and returns the first choice
+func (c *OpenAIClient) Chat(ctx context.Context, messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64) (*LLMResponse, error) {
+	request := c.chatRequest(messages, tools, maxTokens, temperature)
+
+	var response openAIChatResponse
+	if err := c.transport.postJSON(ctx, c.url, c.headers(), request, &response); err != nil {
+		return nil, fmt.Errorf("OpenAI chat completion failed: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("OpenAI chat completion returned no choices")
+	}
+
+	choice := response.Choices[0]
+	toolCalls := make([]ToolCall, 0, len(choice.Message.ToolCalls))
+	for _, call := range choice.Message.ToolCalls {
+		toolCalls = append(toolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: json.RawMessage(call.Function.Arguments),
+		})
+	}
+	return c.llmResponse(response.Model, choice.Message.Content, toolCalls, choice.FinishReason, response.Usage), nil
+}
+
+// ChatStream sends a conversation like Chat and calls onChunk with each piece of text as it is generated
+func (c *OpenAIClient) ChatStream(ctx context.Context, messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64, onChunk func(string) error) (*LLMResponse, error) {
+	request := c.chatRequest(messages, tools, maxTokens, temperature)
+	request.Stream = true
+	request.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+
+	resp, err := c.transport.stream(ctx, c.url, c.headers(), request)
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI chat completion failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var model, finishReason string
+	var usage openAIUsage
+	var content strings.Builder
+	var toolCalls []ToolCall
+	var arguments []string
+	err = readServerSentEvents(resp.Body, func(data []byte) (bool, error) {
+		if string(data) == "[DONE]" {
+			return true, nil
+		}
+		var chunk openAIChatChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return false, fmt.Errorf("failed to decode OpenAI stream: %w", err)
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			return false, nil
+		}
+
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			finishReason = choice.FinishReason
+		}
+		for _, delta := range choice.Delta.ToolCalls {
+			if delta.Index < 0 {
+				continue
+			}
+			for len(toolCalls) <= delta.Index {
+				toolCalls = append(toolCalls, ToolCall{})
+				arguments = append(arguments, "")
+			}
+			if delta.ID != "" {
+				toolCalls[delta.Index].ID = delta.ID
+			}
+			if delta.Function.Name != "" {
+				toolCalls[delta.Index].Name = delta.Function.Name
+			}
+			arguments[delta.Index] += delta.Function.Arguments
+		}
+		if choice.Delta.Content == "" {
+			return false, nil
+		}
+		content.WriteString(choice.Delta.Content)
+		if onChunk != nil {
+			return false, onChunk(choice.Delta.Content)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI chat completion failed: %w", err)
+	}
+
+	for i := range toolCalls {
+		toolCalls[i].Arguments = json.RawMessage(arguments[i])
+	}
+	return c.llmResponse(model, content.String(), toolCalls, finishReason, usage), nil
+}
+
+// chatRequest converts a conversation to the chat-completions format
+func (c *OpenAIClient) chatRequest(messages []ChatMessage, tools []ToolDefinition, maxTokens int, temperature *float64) openAIChatRequest {
+	if maxTokens <= 0 {
+		maxTokens = c.maxTokens
+	}
+	request := openAIChatRequest{
+		Model:       c.model,
+		Messages:    make([]openAIMessage, 0, len(messages)),
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
+	}
+	for _, message := range messages {
+		converted := openAIMessage{Role: message.Role, Content: message.Content, ToolCallID: message.ToolCallID}
+		for _, call := range message.ToolCalls {
+			toolCall := openAIToolCall{ID: call.ID, Type: "function"}
+			toolCall.Function.Name = call.Name
+			toolCall.Function.Arguments = string(call.Arguments)
+			converted.ToolCalls = append(converted.ToolCalls, toolCall)
+		}
+		request.Messages = append(request.Messages, converted)
+	}
+	for _, tool := range tools {
+		request.Tools = append(request.Tools, openAITool{Type: "function", Function: tool})
+	}
+	return request
+}
+
+func (c *OpenAIClient) headers() map[string]string {
+	headers := map[string]string{}
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+	return headers
+}
+
+func (c *OpenAIClient) llmResponse(model, content string, toolCalls []ToolCall, finishReason string, usage openAIUsage) *LLMResponse {
+	if model == "" {
+		model = c.model
+	}
+	logrus.Debugf("🤖 [LLM] %s used %d prompt and %d completion tokens (finish reason: %s)",
+		model, usage.PromptTokens, usage.CompletionTokens, finishReason)
+
+	result := &LLMResponse{
+		Content:          content,
+		Confidence:       heuristicConfidence(finishReason == "length"),
+		Truncated:        finishReason == "length",
+		TokensUsed:       usage.TotalTokens,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		Model:            model,
+		GeneratedAt:      time.Now(),
+	}
+	if len(toolCalls) > 0 {
+		result.ToolCalls = toolCalls
+	}
+	return result
+}
+
+// IsEnabled reports whether LLM integration is enabled in the configuration
+func (c *OpenAIClient) IsEnabled() bool {
+	return c.enabled
+}
@@ -0,0 +1,322 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/metrics"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxPromQLAttempts bounds how often the model may fix a query the parser rejected
+	maxPromQLAttempts = 3
+	// promQLDryRunRange keeps the validation query cheap for Mimir
+	promQLDryRunRange = 15 * time.Minute
+	promQLDryRunStep  = "60s"
+	maxDryRunSeries   = 10
+	// promQLTenantLabel carries the tenant ID on Mimir's per-tenant series
+	promQLTenantLabel = "user"
+)
+
+// ErrInvalidPromQL is returned when the model does not produce a query the parser accepts
+var ErrInvalidPromQL = errors.New("generated query is not valid PromQL")
+
+const promQLPrompt = `You translate questions about a Grafana Mimir cluster into a single PromQL expression.
+Mimir's own metrics use the cortex_ prefix, for example cortex_distributor_received_samples_total,
+cortex_discarded_samples_total (label "reason"), cortex_ingester_memory_series, cortex_ingester_active_series
+and cortex_request_duration_seconds. Per-tenant series carry the tenant ID in the "user" label.
+Use rate() or increase() on counters and topk()/bottomk() for rankings.
+Reply with only a JSON object: {"query": "<PromQL>", "explanation": "<one sentence>"}.
+
+Question: %s`
+
+// PromQLRequest asks for a PromQL expression answering a question
+type PromQLRequest struct {
+	Question    string   `json:"question" binding:"required"`
+	Tenant      string   `json:"tenant,omitempty"` // Restricts the query to one tenant's series
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"` // Provider default when unset
+}
+
+// PromQLResponse is a generated query that passed the parser, with the result of a dry run
+type PromQLResponse struct {
+	Question    string           `json:"question"`
+	Tenant      string           `json:"tenant,omitempty"`
+	Query       string           `json:"query"`
+	Explanation string           `json:"explanation,omitempty"`
+	AST         PromQLSummary    `json:"ast"`
+	DryRun      *PromQLDryRun    `json:"dry_run,omitempty"`
+	Attempts    int              `json:"attempts"`
+	Metadata    ResponseMetadata `json:"metadata"`
+}
+
+// PromQLSummary describes the parsed expression
+type PromQLSummary struct {
+	ResultType   string   `json:"result_type"`
+	Metrics      []string `json:"metrics"`
+	Matchers     []string `json:"matchers,omitempty"`
+	Functions    []string `json:"functions,omitempty"`
+	Aggregations []string `json:"aggregations,omitempty"`
+	Operators    []string `json:"operators,omitempty"`
+	Ranges       []string `json:"ranges,omitempty"`
+}
+
+// PromQLDryRun is a sample of the query's result over a short range
+type PromQLDryRun struct {
+	Start       time.Time       `json:"start"`
+	End         time.Time       `json:"end"`
+	Step        string          `json:"step"`
+	Succeeded   bool            `json:"succeeded"`
+	Error       string          `json:"error,omitempty"`
+	SeriesTotal int             `json:"series_total"`
+	Series      []seriesSummary `json:"series,omitempty"`
+}
+
+// GeneratePromQL turns a question into a PromQL expression, checks it with the Prometheus parser
+// and runs it against Mimir over a short range
+func (a *Assistant) GeneratePromQL(ctx context.Context, req PromQLRequest) (*PromQLResponse, error) {
+	start := time.Now()
+
+	if a.llmClient == nil || !a.llmClient.IsEnabled() {
+		return nil, ErrLLMUnavailable
+	}
+
+	logrus.Infof("🤖 [LLM] Generating PromQL for: %s", req.Question)
+
+	prompt := fmt.Sprintf(promQLPrompt, req.Question) + a.metricNamesHint(ctx)
+	if req.Tenant != "" {
+		prompt += fmt.Sprintf("\nOnly include series of tenant %q (user=%q).", req.Tenant, req.Tenant)
+	}
+
+	response := &PromQLResponse{Question: req.Question, Tenant: req.Tenant}
+	var expr parser.Expr
+	var lastErr error
+	for response.Attempts < maxPromQLAttempts {
+		response.Attempts++
+
+		llmResponse, err := a.llmClient.GenerateResponse(ctx, prompt, req.MaxTokens, req.Temperature)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get LLM response: %w", err)
+		}
+		response.Metadata.TokensUsed += llmResponse.TokensUsed
+		response.Metadata.PromptTokens += llmResponse.PromptTokens
+		response.Metadata.CompletionTokens += llmResponse.CompletionTokens
+		response.Metadata.Model = llmResponse.Model
+
+		query, explanation := extractPromQL(llmResponse.Content)
+		if query == "" {
+			lastErr = fmt.Errorf("no query in the response")
+		} else if expr, lastErr = parser.ParseExpr(query); lastErr == nil {
+			// The prompt only asks for the tenant, the matchers are what enforce it
+			if lastErr = restrictPromQLToTenant(expr, req.Tenant); lastErr == nil {
+				response.Explanation = explanation
+				break
+			}
+		}
+
+		logrus.Debugf("🤖 [LLM] Rejected PromQL %q: %v", query, lastErr)
+		// Let the model fix its own mistake with the parser's error
+		prompt = fmt.Sprintf("%s\n\nYour previous answer %q was rejected: %v\nReply with a corrected JSON object.",
+			prompt, query, lastErr)
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("%w after %d attempts: %v", ErrInvalidPromQL, response.Attempts, lastErr)
+	}
+
+	response.Query = parser.Prettify(expr)
+	response.AST = summarizePromQL(expr)
+	if a.sources.Metrics != nil {
+		response.DryRun = a.dryRunPromQL(ctx, expr)
+		response.Metadata.DataPoints = response.DryRun.SeriesTotal
+	}
+
+	response.Metadata.ProcessingTime = time.Since(start)
+	response.Metadata.QueryComplexity = a.assessQueryComplexity(req.Question)
+	response.Metadata.GeneratedAt = time.Now()
+
+	logrus.Infof("🤖 [LLM] Generated PromQL in %d attempts: %s", response.Attempts, expr.String())
+	return response, nil
+}
+
+// metricNamesHint lists the queries that work for Mimir's own metrics in this cluster, whose names vary by version
+func (a *Assistant) metricNamesHint(ctx context.Context) string {
+	if a.sources.Metrics == nil {
+		return ""
+	}
+	names := a.sources.Metrics.MetricNames(ctx)
+	if !names.Probed {
+		return ""
+	}
+
+	var hint strings.Builder
+	for _, logical := range metrics.LogicalMetrics() {
+		if metric, ok := names.Metric(logical.Name); ok && metric.TenantLabel != "" {
+			fmt.Fprintf(&hint, "\n- %s: %s", logical.Description, metric.Query)
+		}
+	}
+	if hint.Len() == 0 {
+		return ""
+	}
+	return "\nThis cluster exports these metrics; prefer their names over the examples above:" + hint.String()
+}
+
+// extractPromQL reads the query and explanation from the model's reply, tolerating code fences
+func extractPromQL(content string) (string, string) {
+	content = strings.TrimSpace(content)
+
+	if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+		var reply struct {
+			Query       string `json:"query"`
+			Explanation string `json:"explanation"`
+		}
+		if err := json.Unmarshal([]byte(content[start:end+1]), &reply); err == nil && reply.Query != "" {
+			return strings.TrimSpace(reply.Query), strings.TrimSpace(reply.Explanation)
+		}
+	}
+
+	// Fall back to a bare expression, possibly inside a fenced code block
+	if start := strings.Index(content, "```"); start >= 0 {
+		content = content[start+3:]
+		if end := strings.Index(content, "```"); end >= 0 {
+			content = content[:end]
+		}
+		if newline := strings.Index(content, "\n"); newline >= 0 && !strings.ContainsAny(content[:newline], "({[") {
+			// Drop the language tag
+			content = content[newline+1:]
+		}
+	}
+	return strings.TrimSpace(content), ""
+}
+
+// restrictPromQLToTenant adds the tenant matcher to every selector of the expression that lacks it.
+// A selector that already matches the tenant label in any other way is rejected rather than rewritten.
+func restrictPromQLToTenant(expr parser.Expr, tenant string) error {
+	if tenant == "" {
+		return nil
+	}
+	return parser.Walk(tenantScoper(tenant), expr, nil)
+}
+
+// tenantScoper is the parser.Visitor that restricts selectors to one tenant
+type tenantScoper string
+
+func (tenant tenantScoper) Visit(node parser.Node, _ []parser.Node) (parser.Visitor, error) {
+	selector, ok := node.(*parser.VectorSelector)
+	if !ok {
+		return tenant, nil
+	}
+
+	scoped := false
+	for _, matcher := range selector.LabelMatchers {
+		if matcher.Name != promQLTenantLabel {
+			continue
+		}
+		if matcher.Type != labels.MatchEqual || matcher.Value != string(tenant) {
+			return nil, fmt.Errorf("selector %s must only select tenant %q", selector, string(tenant))
+		}
+		scoped = true
+	}
+	if !scoped {
+		selector.LabelMatchers = append(selector.LabelMatchers,
+			labels.MustNewMatcher(labels.MatchEqual, promQLTenantLabel, string(tenant)))
+	}
+	return tenant, nil
+}
+
+// summarizePromQL lists the metrics, matchers, functions and operators used by an expression
+func summarizePromQL(expr parser.Expr) PromQLSummary {
+	summary := PromQLSummary{ResultType: string(expr.Type()), Metrics: []string{}}
+	add := func(list *[]string, value string) {
+		if !slices.Contains(*list, value) {
+			*list = append(*list, value)
+		}
+	}
+
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		switch n := node.(type) {
+		case *parser.VectorSelector:
+			name := n.Name
+			for _, matcher := range n.LabelMatchers {
+				if matcher.Name == model.MetricNameLabel {
+					if name == "" {
+						name = matcher.Value
+					}
+					continue
+				}
+				add(&summary.Matchers, matcher.String())
+			}
+			if name != "" {
+				add(&summary.Metrics, name)
+			}
+		case *parser.MatrixSelector:
+			add(&summary.Ranges, model.Duration(n.Range).String())
+		case *parser.SubqueryExpr:
+			step := ""
+			if n.Step > 0 {
+				step = model.Duration(n.Step).String()
+			}
+			add(&summary.Ranges, fmt.Sprintf("%s:%s", model.Duration(n.Range), step))
+		case *parser.Call:
+			add(&summary.Functions, n.Func.Name)
+		case *parser.AggregateExpr:
+			aggregation := n.Op.String()
+			if len(n.Grouping) > 0 {
+				grouping := "by"
+				if n.Without {
+					grouping = "without"
+				}
+				aggregation = fmt.Sprintf("%s %s (%s)", aggregation, grouping, strings.Join(n.Grouping, ", "))
+			}
+			add(&summary.Aggregations, aggregation)
+		case *parser.BinaryExpr:
+			add(&summary.Operators, n.Op.String())
+		}
+		return nil
+	})
+
+	return summary
+}
+
+// dryRunPromQL runs the expression over the last few minutes and samples the result
+func (a *Assistant) dryRunPromQL(ctx context.Context, expr parser.Expr) *PromQLDryRun {
+	end := time.Now().UTC()
+	dryRun := &PromQLDryRun{
+		Start: end.Add(-promQLDryRunRange),
+		End:   end,
+		Step:  promQLDryRunStep,
+	}
+	if expr.Type() == parser.ValueTypeString {
+		dryRun.Error = "string expressions cannot be evaluated as a range query"
+		return dryRun
+	}
+
+	result, err := a.sources.Metrics.QueryMetrics(ctx, metrics.MetricQuery{
+		Query: expr.String(),
+		Start: dryRun.Start,
+		End:   dryRun.End,
+		Step:  dryRun.Step,
+	})
+	if err != nil {
+		dryRun.Error = err.Error()
+		return dryRun
+	}
+
+	dryRun.Succeeded = true
+	dryRun.SeriesTotal = len(result.Data.Result)
+	for _, series := range result.Data.Result {
+		if len(dryRun.Series) == maxDryRunSeries {
+			break
+		}
+		dryRun.Series = append(dryRun.Series, summarizeSeries(series.Metric, series.Samples()))
+	}
+	return dryRun
+}
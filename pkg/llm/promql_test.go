@@ -0,0 +1,193 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+func TestExtractPromQL(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		wantQuery       string
+		wantExplanation string
+	}{
+		{
+			name:            "JSON object",
+			content:         `{"query": "sum(rate(cortex_distributor_received_samples_total[5m]))", "explanation": "Ingestion rate."}`,
+			wantQuery:       "sum(rate(cortex_distributor_received_samples_total[5m]))",
+			wantExplanation: "Ingestion rate.",
+		},
+		{
+			name:            "JSON in a fenced block with prose",
+			content:         "Here you go:\n```json\n{\"query\": \" up{job=\\\"mimir\\\"} \", \"explanation\": \" Targets \"}\n```\nHope it helps.",
+			wantQuery:       `up{job="mimir"}`,
+			wantExplanation: "Targets",
+		},
+		{
+			name:      "bare expression",
+			content:   "  topk(5, cortex_ingester_memory_series)  ",
+			wantQuery: "topk(5, cortex_ingester_memory_series)",
+		},
+		{
+			name:      "fenced expression with language tag",
+			content:   "```promql\nrate(cortex_request_duration_seconds_count[1m])\n```",
+			wantQuery: "rate(cortex_request_duration_seconds_count[1m])",
+		},
+		{
+			name:      "fenced expression without language tag",
+			content:   "```sum by (user) (cortex_ingester_active_series)```",
+			wantQuery: "sum by (user) (cortex_ingester_active_series)",
+		},
+		{
+			name:      "braces that are not a JSON reply",
+			content:   `cortex_ingester_memory_series{user="team-a"}`,
+			wantQuery: `cortex_ingester_memory_series{user="team-a"}`,
+		},
+		{
+			name:      "JSON without a query",
+			content:   `{"explanation": "I cannot answer that"}`,
+			wantQuery: `{"explanation": "I cannot answer that"}`,
+		},
+		{name: "empty", content: "   "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, explanation := extractPromQL(tt.content)
+			if query != tt.wantQuery || explanation != tt.wantExplanation {
+				t.Errorf("extractPromQL() = %q, %q, want %q, %q", query, explanation, tt.wantQuery, tt.wantExplanation)
+			}
+		})
+	}
+}
+
+func TestRestrictPromQLToTenant(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		tenant    string
+		want      string
+		wantError bool
+	}{
+		{
+			name:  "no tenant leaves the query alone",
+			query: `sum(rate(cortex_distributor_received_samples_total[5m]))`,
+			want:  `sum(rate(cortex_distributor_received_samples_total[5m]))`,
+		},
+		{
+			name:   "every selector gets the matcher",
+			query:  `sum(rate(cortex_discarded_samples_total{reason="rate_limited"}[5m])) / sum(rate(cortex_distributor_received_samples_total[5m]))`,
+			tenant: "team-a",
+			want:   `sum(rate(cortex_discarded_samples_total{reason="rate_limited",user="team-a"}[5m])) / sum(rate(cortex_distributor_received_samples_total{user="team-a"}[5m]))`,
+		},
+		{
+			name:   "subqueries and offsets",
+			query:  `max_over_time(cortex_ingester_active_series[1h:5m] offset 1d)`,
+			tenant: "team-a",
+			want:   `max_over_time(cortex_ingester_active_series{user="team-a"}[1h:5m] offset 1d)`,
+		},
+		{
+			name:   "existing matcher is kept",
+			query:  `cortex_ingester_memory_series{user="team-a"}`,
+			tenant: "team-a",
+			want:   `cortex_ingester_memory_series{user="team-a"}`,
+		},
+		{
+			name:      "other tenant",
+			query:     `cortex_ingester_memory_series{user="team-b"}`,
+			tenant:    "team-a",
+			wantError: true,
+		},
+		{
+			name:      "regex matcher could select other tenants",
+			query:     `sum(cortex_ingester_memory_series{user=~"team-.*"})`,
+			tenant:    "team-a",
+			wantError: true,
+		},
+		{
+			name:      "negative matcher",
+			query:     `cortex_ingester_memory_series{user!="team-b"}`,
+			tenant:    "team-a",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.query)
+			if err != nil {
+				t.Fatalf("ParseExpr() error = %v", err)
+			}
+			err = restrictPromQLToTenant(expr, tt.tenant)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("restrictPromQLToTenant() error = nil, query %s", expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("restrictPromQLToTenant() error = %v", err)
+			}
+			if got := expr.String(); got != tt.want {
+				t.Errorf("query = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// scriptedClient replies with one canned answer per call
+type scriptedClient struct {
+	replies []string
+	prompts []string
+}
+
+func (c *scriptedClient) GenerateResponse(ctx context.Context, prompt string, maxTokens int, temperature *float64) (*LLMResponse, error) {
+	c.prompts = append(c.prompts, prompt)
+	if len(c.replies) == 0 {
+		return nil, errors.New("no more replies")
+	}
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	return &LLMResponse{Content: reply}, nil
+}
+
+func (c *scriptedClient) IsEnabled() bool { return true }
+
+func TestGeneratePromQLEnforcesTenant(t *testing.T) {
+	client := &scriptedClient{replies: []string{
+		`{"query": "sum(cortex_ingester_memory_series{user=\"team-b\"})"}`,
+		`{"query": "sum(cortex_ingester_memory_series)", "explanation": "In-memory series."}`,
+	}}
+	assistant := &Assistant{llmClient: client}
+
+	response, err := assistant.GeneratePromQL(context.Background(), PromQLRequest{Question: "How many series does team-a have?", Tenant: "team-a"})
+	if err != nil {
+		t.Fatalf("GeneratePromQL() error = %v", err)
+	}
+	if response.Attempts != 2 || !strings.Contains(client.prompts[1], `must only select tenant "team-a"`) {
+		t.Errorf("attempts = %d, want the other tenant's query rejected and explained to the model", response.Attempts)
+	}
+	if !strings.Contains(response.Query, `user="team-a"`) || response.Tenant != "team-a" {
+		t.Errorf("query = %s, want it restricted to team-a", response.Query)
+	}
+	if len(response.AST.Matchers) != 1 || response.AST.Matchers[0] != `user="team-a"` {
+		t.Errorf("matchers = %v, want the tenant matcher", response.AST.Matchers)
+	}
+}
+
+func TestGeneratePromQLGivesUp(t *testing.T) {
+	client := &scriptedClient{replies: []string{"sum(", "rate(x[5m]", `{"query": ""}`}}
+	assistant := &Assistant{llmClient: client}
+
+	if _, err := assistant.GeneratePromQL(context.Background(), PromQLRequest{Question: "?"}); !errors.Is(err, ErrInvalidPromQL) {
+		t.Errorf("GeneratePromQL() error = %v, want ErrInvalidPromQL", err)
+	}
+	if len(client.prompts) != maxPromQLAttempts {
+		t.Errorf("asked the model %d times, want %d", len(client.prompts), maxPromQLAttempts)
+	}
+}
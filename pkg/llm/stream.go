@@ -0,0 +1,60 @@
+package llm
+
+// Event types emitted while a query is processed
+const (
+	StreamEventSources         = "sources"
+	StreamEventToken           = "token"
+	StreamEventAnalysis        = "analysis"
+	StreamEventRecommendations = "recommendations"
+	StreamEventDone            = "done"
+)
+
+// StreamEvent is a step of a streamed answer
+type StreamEvent struct {
+	Type string
+	Data interface{}
+}
+
+// TokenEvent is a piece of answer text as generated by the model
+type TokenEvent struct {
+	Text string `json:"text"`
+}
+
+// RecommendationsEvent carries the suggested actions and follow-up questions
+type RecommendationsEvent struct {
+	Recommendations []string `json:"recommendations"`
+	RelatedQueries  []string `json:"related_queries"`
+}
+
+// emitEvent passes an event to emit unless the caller is not streaming
+func emitEvent(emit func(StreamEvent) error, eventType string, data interface{}) error {
+	if emit == nil {
+		return nil
+	}
+	return emit(StreamEvent{Type: eventType, Data: data})
+}
+
+// tokenEmitter turns streamed text into token events
+func tokenEmitter(emit func(StreamEvent) error) func(string) error {
+	return func(text string) error {
+		return emitEvent(emit, StreamEventToken, TokenEvent{Text: text})
+	}
+}
+
+// emitResponse emits the analysis and the recommendations when the response has them, and finally the complete response
+func emitResponse(emit func(StreamEvent) error, response *AssistantResponse) error {
+	if response.Analysis != nil {
+		if err := emitEvent(emit, StreamEventAnalysis, response.Analysis); err != nil {
+			return err
+		}
+	}
+	if len(response.Recommendations) > 0 {
+		if err := emitEvent(emit, StreamEventRecommendations, RecommendationsEvent{
+			Recommendations: response.Recommendations,
+			RelatedQueries:  response.RelatedQueries,
+		}); err != nil {
+			return err
+		}
+	}
+	return emitEvent(emit, StreamEventDone, response)
+}
@@ -3,10 +3,13 @@ package metrics
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/akshaydubey29/mimirInsights/pkg/config"
@@ -18,6 +21,9 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	config     *config.Config
+
+	namesMu sync.Mutex
+	names   map[string]*MetricNames // Metric names resolved per Mimir base URL
 }
 
 // MetricQuery represents a Prometheus query
@@ -26,19 +32,26 @@ type MetricQuery struct {
 	Start  time.Time `json:"start"`
 	End    time.Time `json:"end"`
 	Step   string    `json:"step"`
+	Tenant string    `json:"tenant,omitempty"` // Org to query the tenant's own series in; "a|b" queries several tenants
+	Stats  bool      `json:"stats,omitempty"`  // Ask for query statistics (stats=all)
+}
+
+// InstantQuery is a PromQL expression evaluated at a single point in time
+type InstantQuery struct {
+	Query  string    `json:"query"`
+	Time   time.Time `json:"time,omitempty"` // Zero evaluates at the current time
 	Tenant string    `json:"tenant,omitempty"`
+	Stats  bool      `json:"stats,omitempty"`
 }
 
 // MetricResponse represents a Prometheus query response
 type MetricResponse struct {
-	Status string `json:"status"`
-	Data   struct {
-		ResultType string `json:"resultType"`
-		Result     []struct {
-			Metric map[string]string `json:"metric"`
-			Values [][]interface{}   `json:"values"`
-		} `json:"result"`
-	} `json:"data"`
+	Status    string    `json:"status"`
+	Data      QueryData `json:"data"`
+	ErrorType string    `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Warnings  []string  `json:"warnings,omitempty"`
+	Infos     []string  `json:"infos,omitempty"`
 }
 
 // MetricValue represents a single metric value
@@ -84,21 +97,55 @@ func NewClient() *Client {
 	}
 }
 
-// QueryMetrics executes a Prometheus query
+// QueryMetrics executes a Prometheus query as the query's tenant, or the configured org when none is set
 func (c *Client) QueryMetrics(ctx context.Context, query MetricQuery) (*MetricResponse, error) {
+	return c.QueryMetricsAt(ctx, c.baseURL, query)
+}
+
+// QueryMetricsAt executes a Prometheus range query against the Mimir at baseURL instead of the configured one
+func (c *Client) QueryMetricsAt(ctx context.Context, baseURL string, query MetricQuery) (*MetricResponse, error) {
+	params := url.Values{}
+	params.Set("query", query.Query)
+	params.Set("start", query.Start.Format(time.RFC3339))
+	params.Set("end", query.End.Format(time.RFC3339))
+	params.Set("step", query.Step)
+	if query.Stats {
+		params.Set("stats", "all")
+	}
+	return c.query(ctx, baseURL, "query_range", params, query.Tenant)
+}
+
+// QueryInstant evaluates an expression at a single point in time
+func (c *Client) QueryInstant(ctx context.Context, query InstantQuery) (*MetricResponse, error) {
+	return c.QueryInstantAt(ctx, c.baseURL, query)
+}
+
+// QueryInstantAt evaluates an expression at a single point in time against the Mimir at baseURL
+func (c *Client) QueryInstantAt(ctx context.Context, baseURL string, query InstantQuery) (*MetricResponse, error) {
+	params := url.Values{}
+	params.Set("query", query.Query)
+	if !query.Time.IsZero() {
+		params.Set("time", query.Time.Format(time.RFC3339))
+	}
+	if query.Stats {
+		params.Set("stats", "all")
+	}
+	return c.query(ctx, baseURL, "query", params, query.Tenant)
+}
+
+// query calls a Prometheus query API of Mimir as the tenant and decodes the result
+func (c *Client) query(ctx context.Context, baseURL, api string, params url.Values, tenant string) (*MetricResponse, error) {
+	orgID, err := c.orgID(tenant)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build query URL - use the correct Mimir API path
-	u, err := url.Parse(c.baseURL + "/prometheus/api/v1/query_range")
+	u, err := url.Parse(strings.TrimRight(baseURL, "/") + "/prometheus/api/v1/" + api)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
-
-	q := u.Query()
-	q.Set("query", query.Query)
-	q.Set("start", query.Start.Format(time.RFC3339))
-	q.Set("end", query.End.Format(time.RFC3339))
-	q.Set("step", query.Step)
-
-	u.RawQuery = q.Encode()
+	u.RawQuery = params.Encode()
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
@@ -107,8 +154,8 @@ func (c *Client) QueryMetrics(ctx context.Context, query MetricQuery) (*MetricRe
 	}
 
 	// Add X-Scope-OrgID header for multi-tenant Mimir
-	if c.config.Mimir.OrgID != "" {
-		req.Header.Set("X-Scope-OrgID", c.config.Mimir.OrgID)
+	if orgID != "" {
+		req.Header.Set("X-Scope-OrgID", orgID)
 	}
 
 	// Execute request
@@ -119,7 +166,13 @@ func (c *Client) QueryMetrics(ctx context.Context, query MetricQuery) (*MetricRe
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		// Mimir explains rejected queries in the error fields of the body
+		queryErr := &QueryError{StatusCode: resp.StatusCode}
+		var errResp MetricResponse
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&errResp); err == nil {
+			queryErr.Type, queryErr.Message = errResp.ErrorType, errResp.Error
+		}
+		return nil, queryErr
 	}
 
 	// Parse response
@@ -129,106 +182,66 @@ func (c *Client) QueryMetrics(ctx context.Context, query MetricQuery) (*MetricRe
 	}
 
 	if metricResp.Status != "success" {
-		return nil, fmt.Errorf("query failed with status: %s", metricResp.Status)
+		return nil, &QueryError{StatusCode: resp.StatusCode, Type: metricResp.ErrorType, Message: metricResp.Error}
+	}
+	for _, warning := range metricResp.Warnings {
+		logrus.Debugf("Query %q returned warning: %s", params.Get("query"), warning)
 	}
 
 	return &metricResp, nil
 }
 
+// tenantMetricTypes are the logical metrics collected per tenant
+var tenantMetricTypes = []string{
+	MetricIngestionRate,
+	MetricRejectedSamples,
+	MetricLimitsReached,
+	MetricActiveSeries,
+	MetricInMemorySeries,
+	MetricMemoryUsage,
+}
+
 // GetIngestionRate gets the ingestion rate for a tenant
 func (c *Client) GetIngestionRate(ctx context.Context, tenant string, timeRange TimeRange) ([]MetricSeries, error) {
-	query := MetricQuery{
-		Query:  fmt.Sprintf(`cortex_distributor_ingestion_rate{tenant="%s"}`, tenant),
-		Start:  timeRange.Start,
-		End:    timeRange.End,
-		Step:   timeRange.Step,
-		Tenant: tenant,
-	}
-
-	resp, err := c.QueryMetrics(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query ingestion rate: %w", err)
-	}
-
-	return c.parseMetricResponse(resp, "ingestion_rate"), nil
+	return c.GetTenantMetric(ctx, MetricIngestionRate, tenant, timeRange)
 }
 
-// GetRejectedSamples gets the rejected samples count for a tenant
+// GetRejectedSamples gets the rate of discarded samples by reason for a tenant
 func (c *Client) GetRejectedSamples(ctx context.Context, tenant string, timeRange TimeRange) ([]MetricSeries, error) {
-	query := MetricQuery{
-		Query:  fmt.Sprintf(`cortex_distributor_rejected_samples_total{tenant="%s"}`, tenant),
-		Start:  timeRange.Start,
-		End:    timeRange.End,
-		Step:   timeRange.Step,
-		Tenant: tenant,
-	}
-
-	resp, err := c.QueryMetrics(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query rejected samples: %w", err)
-	}
-
-	return c.parseMetricResponse(resp, "rejected_samples"), nil
+	return c.GetTenantMetric(ctx, MetricRejectedSamples, tenant, timeRange)
 }
 
-// GetTenantLimitsReached gets the tenant limits reached count
+// GetTenantLimitsReached gets the rate of samples discarded by the tenant's limits
 func (c *Client) GetTenantLimitsReached(ctx context.Context, tenant string, timeRange TimeRange) ([]MetricSeries, error) {
-	query := MetricQuery{
-		Query:  fmt.Sprintf(`cortex_distributor_tenant_limits_reached_total{tenant="%s"}`, tenant),
-		Start:  timeRange.Start,
-		End:    timeRange.End,
-		Step:   timeRange.Step,
-		Tenant: tenant,
-	}
-
-	resp, err := c.QueryMetrics(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query tenant limits reached: %w", err)
-	}
-
-	return c.parseMetricResponse(resp, "limits_reached"), nil
+	return c.GetTenantMetric(ctx, MetricLimitsReached, tenant, timeRange)
 }
 
 // GetActiveSeries gets the active series count for a tenant
 func (c *Client) GetActiveSeries(ctx context.Context, tenant string, timeRange TimeRange) ([]MetricSeries, error) {
-	query := MetricQuery{
-		Query:  fmt.Sprintf(`cortex_ingester_active_series{tenant="%s"}`, tenant),
-		Start:  timeRange.Start,
-		End:    timeRange.End,
-		Step:   timeRange.Step,
-		Tenant: tenant,
-	}
-
-	resp, err := c.QueryMetrics(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query active series: %w", err)
-	}
-
-	return c.parseMetricResponse(resp, "active_series"), nil
+	return c.GetTenantMetric(ctx, MetricActiveSeries, tenant, timeRange)
 }
 
 // GetMemoryUsage gets the memory usage for a tenant
 func (c *Client) GetMemoryUsage(ctx context.Context, tenant string, timeRange TimeRange) ([]MetricSeries, error) {
-	query := MetricQuery{
-		Query:  fmt.Sprintf(`cortex_ingester_memory_usage_bytes{tenant="%s"}`, tenant),
-		Start:  timeRange.Start,
-		End:    timeRange.End,
-		Step:   timeRange.Step,
-		Tenant: tenant,
-	}
-
-	resp, err := c.QueryMetrics(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query memory usage: %w", err)
-	}
-
-	return c.parseMetricResponse(resp, "memory_usage"), nil
+	return c.GetTenantMetric(ctx, MetricMemoryUsage, tenant, timeRange)
 }
 
 // GetProcessMemory gets the process memory usage
 func (c *Client) GetProcessMemory(ctx context.Context, timeRange TimeRange) ([]MetricSeries, error) {
+	return c.GetTenantMetric(ctx, MetricProcessMemory, "", timeRange)
+}
+
+// GetTenantMetric queries a logical metric under the name the configured Mimir exports it as.
+// An empty tenant queries the metric across all tenants.
+// Mimir's self-monitoring series label tenants with user and live in the configured org, not the tenant's.
+func (c *Client) GetTenantMetric(ctx context.Context, logical, tenant string, timeRange TimeRange) ([]MetricSeries, error) {
+	metric, ok := c.MetricNames(ctx).Metric(logical)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMetricNotExported, logical)
+	}
+
 	query := MetricQuery{
-		Query: "process_resident_memory_bytes",
+		Query: metric.TenantQuery(tenant, timeRange.Step),
 		Start: timeRange.Start,
 		End:   timeRange.End,
 		Step:  timeRange.Step,
@@ -236,10 +249,10 @@ func (c *Client) GetProcessMemory(ctx context.Context, timeRange TimeRange) ([]M
 
 	resp, err := c.QueryMetrics(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query process memory: %w", err)
+		return nil, fmt.Errorf("failed to query %s: %w", logical, err)
 	}
 
-	return c.parseMetricResponse(resp, "process_memory"), nil
+	return c.parseMetricResponse(resp, logical), nil
 }
 
 // GetTenantMetrics gets all relevant metrics for a tenant
@@ -252,66 +265,55 @@ func (c *Client) GetTenantMetrics(ctx context.Context, tenant string, timeRange
 		Metrics:    make(map[string][]MetricSeries),
 	}
 
-	// Get ingestion rate
-	ingestionRate, err := c.GetIngestionRate(ctx, tenant, timeRange)
-	if err != nil {
-		logrus.Warnf("Failed to get ingestion rate for %s: %v", tenant, err)
-	} else {
-		metrics.Metrics["ingestion_rate"] = ingestionRate
-	}
-
-	// Get rejected samples
-	rejectedSamples, err := c.GetRejectedSamples(ctx, tenant, timeRange)
-	if err != nil {
-		logrus.Warnf("Failed to get rejected samples for %s: %v", tenant, err)
-	} else {
-		metrics.Metrics["rejected_samples"] = rejectedSamples
-	}
-
-	// Get tenant limits reached
-	limitsReached, err := c.GetTenantLimitsReached(ctx, tenant, timeRange)
-	if err != nil {
-		logrus.Warnf("Failed to get limits reached for %s: %v", tenant, err)
-	} else {
-		metrics.Metrics["limits_reached"] = limitsReached
-	}
-
-	// Get active series
-	activeSeries, err := c.GetActiveSeries(ctx, tenant, timeRange)
-	if err != nil {
-		logrus.Warnf("Failed to get active series for %s: %v", tenant, err)
-	} else {
-		metrics.Metrics["active_series"] = activeSeries
-	}
-
-	// Get memory usage
-	memoryUsage, err := c.GetMemoryUsage(ctx, tenant, timeRange)
-	if err != nil {
-		logrus.Warnf("Failed to get memory usage for %s: %v", tenant, err)
-	} else {
-		metrics.Metrics["memory_usage"] = memoryUsage
+	for _, metricType := range tenantMetricTypes {
+		series, err := c.GetTenantMetric(ctx, metricType, tenant, timeRange)
+		switch {
+		case errors.Is(err, ErrMetricNotExported):
+			logrus.Debugf("Skipping %s for %s: %v", metricType, tenant, err)
+		case err != nil:
+			logrus.Warnf("Failed to get %s for %s: %v", metricType, tenant, err)
+		default:
+			metrics.Metrics[metricType] = series
+		}
 	}
 
 	return metrics, nil
 }
 
-// GetPeakValues gets the peak values for metrics over a time range
+// GetPeakValues gets the peak of each tenant metric over a time range.
+// Mimir evaluates max_over_time at the end of the range, so no samples are transferred.
+// Metrics without any series are left out, e.g. when Mimir does not scrape itself.
 func (c *Client) GetPeakValues(ctx context.Context, tenant string, timeRange TimeRange) (map[string]float64, error) {
-	peakValues := make(map[string]float64)
-
-	// Get all metrics
-	tenantMetrics, err := c.GetTenantMetrics(ctx, tenant, timeRange)
-	if err != nil {
-		return nil, err
+	window := timeRange.End.Sub(timeRange.Start)
+	if window < time.Second {
+		return nil, fmt.Errorf("invalid time range: %v to %v", timeRange.Start, timeRange.End)
 	}
 
-	// Calculate peak values for each metric type
-	for metricType, series := range tenantMetrics.Metrics {
+	names := c.MetricNames(ctx)
+	peakValues := make(map[string]float64)
+	for _, metricType := range tenantMetricTypes {
+		metric, ok := names.Metric(metricType)
+		if !ok {
+			continue
+		}
+		query := InstantQuery{
+			Query: metric.PeakQuery(tenant, window, timeRange.Step),
+			Time:  timeRange.End,
+		}
+		resp, err := c.QueryInstant(ctx, query)
+		if err != nil {
+			logrus.Warnf("Failed to get peak %s for %s: %v", metricType, tenant, err)
+			continue
+		}
+		if len(resp.Data.Result) == 0 {
+			continue
+		}
+
 		var maxValue float64
-		for _, s := range series {
-			for _, v := range s.Values {
-				if v.Value > maxValue {
-					maxValue = v.Value
+		for _, series := range resp.Data.Result {
+			for _, sample := range series.Samples() {
+				if sample.Value > maxValue {
+					maxValue = sample.Value
 				}
 			}
 		}
@@ -321,30 +323,23 @@ func (c *Client) GetPeakValues(ctx context.Context, tenant string, timeRange Tim
 	return peakValues, nil
 }
 
-// parseMetricResponse converts a MetricResponse to MetricSeries
 func (c *Client) parseMetricResponse(resp *MetricResponse, metricName string) []MetricSeries {
 	var series []MetricSeries
 
 	for _, result := range resp.Data.Result {
+		samples := result.Samples()
 		s := MetricSeries{
 			Name:   metricName,
 			Labels: result.Metric,
-			Values: make([]MetricValue, 0, len(result.Values)),
+			Values: make([]MetricValue, 0, len(samples)),
 		}
 
-		for _, value := range result.Values {
-			if len(value) >= 2 {
-				timestamp, _ := value[0].(float64)
-				val, _ := value[1].(string)
-
-				if valFloat, err := strconv.ParseFloat(val, 64); err == nil {
-					s.Values = append(s.Values, MetricValue{
-						Timestamp: time.Unix(int64(timestamp), 0),
-						Value:     valFloat,
-						Labels:    result.Metric,
-					})
-				}
-			}
+		for _, sample := range samples {
+			s.Values = append(s.Values, MetricValue{
+				Timestamp: sample.Timestamp,
+				Value:     sample.Value,
+				Labels:    result.Metric,
+			})
 		}
 
 		series = append(series, s)
@@ -375,87 +370,6 @@ func GetStandardTimeRanges() map[string]TimeRange {
 	}
 }
 
-// GetRealMetricsFromEndpoints collects real metrics from all discovered Mimir endpoints
-func (c *Client) GetRealMetricsFromEndpoints(ctx context.Context, endpoints []string, timeRange TimeRange) (map[string]*TenantMetrics, error) {
-	logrus.Infof("Collecting real metrics from %d discovered Mimir endpoints", len(endpoints))
-
-	allMetrics := make(map[string]*TenantMetrics)
-
-	// For each endpoint, try to collect metrics
-	for _, endpoint := range endpoints {
-		logrus.Infof("Querying metrics from endpoint: %s", endpoint)
-
-		// Temporarily update the base URL for this endpoint
-		originalBaseURL := c.baseURL
-		c.baseURL = endpoint
-
-		// Try to get basic metrics to test connectivity
-		query := MetricQuery{
-			Query: "up",
-			Start: timeRange.Start,
-			End:   timeRange.End,
-			Step:  timeRange.Step,
-		}
-
-		_, err := c.QueryMetrics(ctx, query)
-		if err != nil {
-			logrus.Warnf("Failed to query endpoint %s: %v", endpoint, err)
-			c.baseURL = originalBaseURL
-			continue
-		}
-
-		logrus.Infof("Successfully connected to endpoint: %s", endpoint)
-
-		// Try to get tenant-specific metrics
-		// For now, we'll use a default tenant name since we're in single-tenant mode
-		tenantName := "default"
-
-		tenantMetrics := &TenantMetrics{
-			TenantName: tenantName,
-			TimeRange:  timeRange,
-			Metrics:    make(map[string][]MetricSeries),
-		}
-
-		// Collect various metrics
-		metricsToCollect := map[string]string{
-			"ingestion_rate":   "cortex_distributor_ingestion_rate",
-			"rejected_samples": "cortex_distributor_rejected_samples_total",
-			"active_series":    "cortex_ingester_active_series",
-			"memory_usage":     "cortex_ingester_memory_usage_bytes",
-			"limits_reached":   "cortex_distributor_tenant_limits_reached_total",
-			"process_memory":   "process_resident_memory_bytes",
-			"build_info":       "cortex_build_info",
-		}
-
-		for metricName, queryString := range metricsToCollect {
-			query := MetricQuery{
-				Query: queryString,
-				Start: timeRange.Start,
-				End:   timeRange.End,
-				Step:  timeRange.Step,
-			}
-
-			resp, err := c.QueryMetrics(ctx, query)
-			if err != nil {
-				logrus.Debugf("Failed to get %s from %s: %v", metricName, endpoint, err)
-				continue
-			}
-
-			series := c.parseMetricResponse(resp, metricName)
-			if len(series) > 0 {
-				tenantMetrics.Metrics[metricName] = series
-				logrus.Infof("Collected %d series for %s from %s", len(series), metricName, endpoint)
-			}
-		}
-
-		allMetrics[endpoint] = tenantMetrics
-		c.baseURL = originalBaseURL
-	}
-
-	logrus.Infof("Successfully collected metrics from %d endpoints", len(allMetrics))
-	return allMetrics, nil
-}
-
 // GetProductionMetricsData returns production metrics data with real Mimir data
 func (c *Client) GetProductionMetricsData(ctx context.Context, timeRange TimeRange) (map[string]interface{}, error) {
 	logrus.Infof("Getting production metrics data")
@@ -465,19 +379,26 @@ func (c *Client) GetProductionMetricsData(ctx context.Context, timeRange TimeRan
 	endpoints := []string{c.baseURL}
 
 	// Try to collect real metrics
-	realMetrics, err := c.GetRealMetricsFromEndpoints(ctx, endpoints, timeRange)
-	if err != nil {
-		logrus.Warnf("Failed to collect real metrics: %v", err)
-		return nil, err
+	results := c.CollectEndpointMetrics(ctx, endpoints, timeRange)
+	realMetrics := make(map[string]*TenantMetrics, len(results))
+	endpointErrors := make(map[string]string)
+	for _, result := range results {
+		if result.Error != "" {
+			endpointErrors[result.Endpoint] = result.Error
+			continue
+		}
+		realMetrics[result.Endpoint] = result.Metrics
 	}
 
 	// Convert to a format suitable for the UI
 	productionData := map[string]interface{}{
-		"data_source":  "production",
-		"endpoints":    endpoints,
-		"metrics":      realMetrics,
-		"time_range":   timeRange,
-		"collected_at": time.Now().UTC(),
+		"data_source":     "production",
+		"endpoints":       endpoints,
+		"metrics":         realMetrics,
+		"endpoint_errors": endpointErrors,
+		"metric_names":    c.MetricNames(ctx),
+		"time_range":      timeRange,
+		"collected_at":    time.Now().UTC(),
 	}
 
 	return productionData, nil
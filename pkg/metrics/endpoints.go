@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultEndpointConcurrency bounds how many endpoints are queried at once
+	defaultEndpointConcurrency = 4
+	// defaultEndpointTimeout applies when mimir.api.timeout is not set
+	defaultEndpointTimeout = 30 * time.Second
+)
+
+// endpointMetricTypes are the logical metrics collected from every endpoint
+var endpointMetricTypes = []string{
+	MetricIngestionRate,
+	MetricRejectedSamples,
+	MetricActiveSeries,
+	MetricInMemorySeries,
+	MetricMemoryUsage,
+	MetricLimitsReached,
+	MetricProcessMemory,
+	MetricBuildInfo,
+}
+
+// EndpointMetrics is the outcome of collecting metrics from one Mimir endpoint
+type EndpointMetrics struct {
+	Endpoint     string            `json:"endpoint"`
+	Metrics      *TenantMetrics    `json:"metrics,omitempty"`
+	Error        string            `json:"error,omitempty"`         // Set when the endpoint could not be queried at all
+	MetricErrors map[string]string `json:"metric_errors,omitempty"` // Metrics that failed on a reachable endpoint
+	Duration     time.Duration     `json:"duration"`
+}
+
+// GetRealMetricsFromEndpoints collects real metrics from all discovered Mimir endpoints, keyed by endpoint.
+// Unreachable endpoints are logged and left out; use CollectEndpointMetrics to see why.
+func (c *Client) GetRealMetricsFromEndpoints(ctx context.Context, endpoints []string, timeRange TimeRange) (map[string]*TenantMetrics, error) {
+	allMetrics := make(map[string]*TenantMetrics)
+	for _, result := range c.CollectEndpointMetrics(ctx, endpoints, timeRange) {
+		if result.Error == "" {
+			allMetrics[result.Endpoint] = result.Metrics
+		}
+	}
+	return allMetrics, nil
+}
+
+// CollectEndpointMetrics queries every endpoint concurrently, each within its own timeout.
+// Results are returned in the order of endpoints.
+func (c *Client) CollectEndpointMetrics(ctx context.Context, endpoints []string, timeRange TimeRange) []EndpointMetrics {
+	logrus.Infof("Collecting real metrics from %d discovered Mimir endpoints", len(endpoints))
+
+	concurrency := c.config.Mimir.API.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultEndpointConcurrency
+	}
+	timeout := time.Duration(c.config.Mimir.API.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultEndpointTimeout
+	}
+
+	results := make([]EndpointMetrics, len(endpoints))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i] = EndpointMetrics{Endpoint: endpoint, Error: ctx.Err().Error()}
+				return
+			}
+
+			endpointCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			results[i] = c.collectFromEndpoint(endpointCtx, endpoint, timeRange)
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Error == "" {
+			succeeded++
+		}
+	}
+	logrus.Infof("Successfully collected metrics from %d of %d endpoints", succeeded, len(endpoints))
+	return results
+}
+
+// collectFromEndpoint checks that the endpoint answers and then collects each metric from it
+func (c *Client) collectFromEndpoint(ctx context.Context, endpoint string, timeRange TimeRange) EndpointMetrics {
+	start := time.Now()
+	result := EndpointMetrics{Endpoint: endpoint}
+
+	query := MetricQuery{
+		Query: "up",
+		Start: timeRange.Start,
+		End:   timeRange.End,
+		Step:  timeRange.Step,
+	}
+	if _, err := c.QueryMetricsAt(ctx, endpoint, query); err != nil {
+		logrus.Warnf("Failed to query endpoint %s: %v", endpoint, err)
+		result.Error = fmt.Sprintf("endpoint unreachable: %v", err)
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	// Single-tenant mode: everything on an endpoint is reported under the default tenant
+	result.Metrics = &TenantMetrics{
+		TenantName: "default",
+		TimeRange:  timeRange,
+		Metrics:    make(map[string][]MetricSeries),
+	}
+	names := c.MetricNamesAt(ctx, endpoint)
+	for _, metricName := range endpointMetricTypes {
+		metric, ok := names.Metric(metricName)
+		if !ok {
+			// Report it so that an empty panel can be told apart from a failed query
+			result.addMetricError(metricName, fmt.Errorf("%w: none of %v", ErrMetricNotExported, metric.Candidates))
+			continue
+		}
+		query.Query = metric.TenantQuery("", query.Step)
+		resp, err := c.QueryMetricsAt(ctx, endpoint, query)
+		if err != nil {
+			logrus.Debugf("Failed to get %s from %s: %v", metricName, endpoint, err)
+			result.addMetricError(metricName, err)
+			continue
+		}
+
+		series := c.parseMetricResponse(resp, metricName)
+		if len(series) > 0 {
+			result.Metrics.Metrics[metricName] = series
+			logrus.Debugf("Collected %d series for %s from %s", len(series), metricName, endpoint)
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (r *EndpointMetrics) addMetricError(metricName string, err error) {
+	if r.MetricErrors == nil {
+		r.MetricErrors = make(map[string]string)
+	}
+	r.MetricErrors[metricName] = err.Error()
+}
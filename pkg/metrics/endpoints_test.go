@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+)
+
+func TestCollectEndpointMetrics(t *testing.T) {
+	const (
+		endpointCount  = 6
+		maxConcurrency = 2
+		slowEndpoint   = 3
+	)
+	var inFlight, maxInFlight int32
+
+	// Every endpoint labels its series with its own index, so results can be traced back to it
+	endpoints := make([]string, endpointCount)
+	for i := range endpoints {
+		index := i
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				seen := atomic.LoadInt32(&maxInFlight)
+				if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+					break
+				}
+			}
+
+			if index == slowEndpoint {
+				// Answer only once the client gave up
+				<-r.Context().Done()
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			switch r.URL.Path {
+			case "/prometheus/api/v1/label/__name__/values":
+				w.Write([]byte(`{"status":"success","data":["cortex_distributor_received_samples_total","cortex_ingester_active_series"]}`))
+			case "/prometheus/api/v1/query_range":
+				fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"endpoint":"%d"},"values":[[1700000000,"%d"]]}]}}`, index, index)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer server.Close()
+		endpoints[i] = server.URL
+	}
+
+	client := newTestClient("", config.MimirConfig{API: config.APIConfig{Timeout: 1, MaxConcurrency: maxConcurrency}})
+	results := client.CollectEndpointMetrics(context.Background(), endpoints, CreateTimeRange(time.Hour, "5m"))
+
+	if len(results) != endpointCount {
+		t.Fatalf("CollectEndpointMetrics() returned %d results, want %d", len(results), endpointCount)
+	}
+	for i, result := range results {
+		if result.Endpoint != endpoints[i] {
+			t.Errorf("result %d is for %s, want %s", i, result.Endpoint, endpoints[i])
+			continue
+		}
+		if i == slowEndpoint {
+			if !strings.Contains(result.Error, "endpoint unreachable") || !strings.Contains(result.Error, "deadline exceeded") || result.Metrics != nil {
+				t.Errorf("slow endpoint result = %q, want its timeout reported", result.Error)
+			}
+			continue
+		}
+
+		if result.Error != "" {
+			t.Errorf("endpoint %d error = %s", i, result.Error)
+			continue
+		}
+		for _, metricName := range []string{MetricIngestionRate, MetricActiveSeries} {
+			series := result.Metrics.Metrics[metricName]
+			if len(series) != 1 || series[0].Labels["endpoint"] != fmt.Sprint(i) {
+				t.Errorf("endpoint %d %s = %+v, want the endpoint's own series", i, metricName, series)
+			}
+		}
+		if _, reported := result.MetricErrors[MetricBuildInfo]; !reported {
+			t.Errorf("endpoint %d does not report the metrics it does not export: %v", i, result.MetricErrors)
+		}
+	}
+
+	if got := atomic.LoadInt32(&maxInFlight); got > maxConcurrency {
+		t.Errorf("%d requests were in flight at once, want at most %d", got, maxConcurrency)
+	}
+}
@@ -0,0 +1,335 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logical metrics resolved to the names a Mimir actually exports
+const (
+	MetricIngestionRate   = "ingestion_rate"
+	MetricRejectedSamples = "rejected_samples"
+	MetricLimitsReached   = "limits_reached"
+	MetricActiveSeries    = "active_series"
+	MetricInMemorySeries  = "in_memory_series"
+	MetricMemoryUsage     = "memory_usage"
+	MetricProcessMemory   = "process_memory"
+	MetricBuildInfo       = "build_info"
+)
+
+const (
+	// metricNamesLookback limits the probe to metrics with recent samples
+	metricNamesLookback = time.Hour
+	// metricNamesRetryInterval is how long a failed probe falls back to the default names before probing again
+	metricNamesRetryInterval = 5 * time.Minute
+	// minRateWindow covers several scrapes at the usual intervals
+	minRateWindow = 5 * time.Minute
+)
+
+// ErrMetricNotExported is returned for logical metrics none of whose candidate names exist
+var ErrMetricNotExported = errors.New("metric not exported by this Mimir")
+
+// metricFamilies are the prefixes Mimir's own metrics carry, depending on version and component
+var metricFamilies = []string{"cortex_", "mimir_"}
+
+// limitReasons are the discard reasons of samples rejected by per-tenant limits
+const limitReasons = `reason=~"rate_limited|per_user_series_limit|per_metric_series_limit|per_user_metadata_limit|per_metric_metadata_limit"`
+
+// LogicalMetric is a measurement with the metric names that may provide it, most preferred first
+type LogicalMetric struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Candidates  []MetricCandidate `json:"candidates"`
+}
+
+// MetricCandidate is one way of computing a logical metric
+type MetricCandidate struct {
+	Name        string   `json:"name"`                   // Without the family prefix unless Unprefixed
+	Unprefixed  bool     `json:"unprefixed,omitempty"`   // Name is used as is, e.g. process metrics
+	Counter     bool     `json:"counter,omitempty"`      // Queried as a per-second rate
+	Subtract    string   `json:"subtract,omitempty"`     // Metric of the same family subtracted from Name
+	TenantLabel string   `json:"tenant_label,omitempty"` // Label carrying the tenant ID
+	Matchers    string   `json:"matchers,omitempty"`     // Extra label matchers
+	GroupBy     []string `json:"group_by,omitempty"`
+	Replicated  bool     `json:"replicated,omitempty"` // Reported by every ingester holding a replica of the series
+}
+
+// logicalMetrics are the metrics mimirInsights queries; the last candidates cover older deployments
+var logicalMetrics = []LogicalMetric{
+	{Name: MetricIngestionRate, Description: "Samples received per second", Candidates: []MetricCandidate{
+		{Name: "distributor_received_samples_total", Counter: true, TenantLabel: "user"},
+		{Name: "distributor_samples_in_total", Counter: true, TenantLabel: "user"},
+		{Name: "distributor_ingestion_rate", TenantLabel: "tenant"},
+	}},
+	{Name: MetricRejectedSamples, Description: "Discarded samples per second by reason", Candidates: []MetricCandidate{
+		{Name: "discarded_samples_total", Counter: true, TenantLabel: "user", GroupBy: []string{"reason"}},
+		{Name: "distributor_rejected_samples_total", Counter: true, TenantLabel: "tenant"},
+	}},
+	{Name: MetricLimitsReached, Description: "Samples discarded by per-tenant limits per second", Candidates: []MetricCandidate{
+		{Name: "discarded_samples_total", Counter: true, TenantLabel: "user", Matchers: limitReasons, GroupBy: []string{"reason"}},
+		{Name: "distributor_tenant_limits_reached_total", Counter: true, TenantLabel: "tenant"},
+	}},
+	{Name: MetricActiveSeries, Description: "Series that received samples recently", Candidates: []MetricCandidate{
+		{Name: "ingester_active_series", TenantLabel: "user", Replicated: true},
+	}},
+	{Name: MetricInMemorySeries, Description: "Series held in ingester memory", Candidates: []MetricCandidate{
+		{Name: "ingester_memory_series_created_total", Subtract: "ingester_memory_series_removed_total", TenantLabel: "user", Replicated: true},
+	}},
+	{Name: MetricMemoryUsage, Description: "Ingester memory used by the tenant", Candidates: []MetricCandidate{
+		{Name: "ingester_memory_usage_bytes", TenantLabel: "tenant", Replicated: true},
+	}},
+	{Name: MetricProcessMemory, Description: "Resident memory of Mimir processes", Candidates: []MetricCandidate{
+		{Name: "process_resident_memory_bytes", Unprefixed: true},
+	}},
+	{Name: MetricBuildInfo, Description: "Version of Mimir components", Candidates: []MetricCandidate{
+		{Name: "build_info"},
+	}},
+}
+
+// MetricNames maps the logical metrics to the names found on one Mimir
+type MetricNames struct {
+	Endpoint string                    `json:"endpoint"`
+	Probed   bool                      `json:"probed"` // False when the probe failed and the default names are assumed
+	Error    string                    `json:"error,omitempty"`
+	ProbedAt time.Time                 `json:"probed_at"`
+	Families []string                  `json:"families"` // Prefixes of the metrics found
+	Metrics  map[string]ResolvedMetric `json:"metrics"`
+}
+
+// ResolvedMetric is the candidate chosen for a logical metric
+type ResolvedMetric struct {
+	MetricCandidate
+	Logical     string   `json:"logical"`
+	Description string   `json:"description"`
+	Resolved    bool     `json:"resolved"`         // False when no candidate exists on the endpoint
+	Family      string   `json:"family,omitempty"` // Prefix of the chosen names
+	Query       string   `json:"query,omitempty"`  // Query without a tenant restriction
+	Candidates  []string `json:"candidates"`       // Names that were looked for
+
+	ReplicationFactor int `json:"replication_factor,omitempty"` // Divides Replicated metrics
+}
+
+// Metric returns the resolution of a logical metric, and false when the endpoint exports none of its candidates
+func (n *MetricNames) Metric(logical string) (ResolvedMetric, bool) {
+	metric, ok := n.Metrics[logical]
+	return metric, ok && metric.Resolved
+}
+
+// TenantQuery returns the query for the metric, restricted to the tenant when one is given.
+// Counters are turned into rates over step, or a few minutes for short steps.
+// Per-tenant series are summed across the instances reporting them, and series every
+// ingester replica reports are divided by the replication factor.
+func (m ResolvedMetric) TenantQuery(tenant, step string) string {
+	query := m.selector(m.Name, tenant)
+	switch {
+	case m.Subtract != "":
+		query = fmt.Sprintf("%s - %s", query, m.selector(m.Subtract, tenant))
+	case m.Counter:
+		query = fmt.Sprintf("rate(%s[%s])", query, rateWindow(step))
+	}
+	if groupBy := m.aggregationLabels(); len(groupBy) > 0 {
+		query = fmt.Sprintf("sum by (%s) (%s)", strings.Join(groupBy, ", "), query)
+	}
+	if m.Replicated && m.ReplicationFactor > 1 {
+		query = fmt.Sprintf("%s / %d", query, m.ReplicationFactor)
+	}
+	return query
+}
+
+// PeakQuery returns the query for the highest value of the metric over the window
+func (m ResolvedMetric) PeakQuery(tenant string, window time.Duration, step string) string {
+	seconds := int64(window.Seconds())
+	if m.Subtract == "" && !m.Counter && len(m.aggregationLabels()) == 0 {
+		return fmt.Sprintf("max(max_over_time(%s[%ds]))", m.selector(m.Name, tenant), seconds)
+	}
+	// Rates, differences and sums across instances need a subquery to be evaluated across the window
+	return fmt.Sprintf("max(max_over_time((%s)[%ds:%s]))", m.TenantQuery(tenant, step), seconds, step)
+}
+
+// aggregationLabels are the labels kept when summing the series of all instances
+func (m ResolvedMetric) aggregationLabels() []string {
+	if m.TenantLabel == "" {
+		return m.GroupBy
+	}
+	return append([]string{m.TenantLabel}, m.GroupBy...)
+}
+
+func (m ResolvedMetric) selector(name, tenant string) string {
+	var matchers []string
+	if tenant != "" && m.TenantLabel != "" {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", m.TenantLabel, tenant))
+	}
+	if m.Matchers != "" {
+		matchers = append(matchers, m.Matchers)
+	}
+	if len(matchers) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s{%s}", name, strings.Join(matchers, ", "))
+}
+
+// rateWindow is the step of a range query, but at least minRateWindow
+func rateWindow(step string) string {
+	if duration, err := time.ParseDuration(step); err == nil && duration > minRateWindow {
+		return step
+	}
+	return fmt.Sprintf("%dm", int(minRateWindow.Minutes()))
+}
+
+// LogicalMetrics returns the logical metrics and their candidate names
+func LogicalMetrics() []LogicalMetric {
+	return logicalMetrics
+}
+
+// MetricNames returns the metric names of the configured Mimir, probing it on first use
+func (c *Client) MetricNames(ctx context.Context) *MetricNames {
+	return c.MetricNamesAt(ctx, c.baseURL)
+}
+
+// MetricNamesAt returns the metric names of the Mimir at baseURL, probing it on first use.
+// A failed probe is retried after a few minutes; until then the cortex_ names are assumed.
+func (c *Client) MetricNamesAt(ctx context.Context, baseURL string) *MetricNames {
+	c.namesMu.Lock()
+	names, ok := c.names[baseURL]
+	c.namesMu.Unlock()
+	if ok && (names.Probed || time.Since(names.ProbedAt) < metricNamesRetryInterval) {
+		return names
+	}
+	return c.probeMetricNames(ctx, baseURL)
+}
+
+// RefreshMetricNames probes the configured Mimir again, e.g. after an upgrade
+func (c *Client) RefreshMetricNames(ctx context.Context) *MetricNames {
+	return c.probeMetricNames(ctx, c.baseURL)
+}
+
+// probeMetricNames lists the metric names of a Mimir and resolves every logical metric against them
+func (c *Client) probeMetricNames(ctx context.Context, baseURL string) *MetricNames {
+	names := &MetricNames{
+		Endpoint: baseURL,
+		ProbedAt: time.Now().UTC(),
+		Families: []string{},
+		Metrics:  make(map[string]ResolvedMetric, len(logicalMetrics)),
+	}
+
+	available, err := c.listMetricNames(ctx, baseURL)
+	if err != nil {
+		logrus.Warnf("Failed to probe metric names of %s, assuming %s names: %v", baseURL, metricFamilies[0], err)
+		names.Error = err.Error()
+	} else {
+		names.Probed = true
+		for _, family := range metricFamilies {
+			for name := range available {
+				if strings.HasPrefix(name, family) {
+					names.Families = append(names.Families, family)
+					break
+				}
+			}
+		}
+	}
+
+	for _, logical := range logicalMetrics {
+		names.Metrics[logical.Name] = resolveMetric(logical, available, names.Probed, c.replicationFactor())
+	}
+
+	c.namesMu.Lock()
+	if c.names == nil {
+		c.names = make(map[string]*MetricNames)
+	}
+	c.names[baseURL] = names
+	c.namesMu.Unlock()
+
+	if names.Probed {
+		logrus.Infof("Resolved metric names of %s (families %v)", baseURL, names.Families)
+	}
+	return names
+}
+
+// replicationFactor is the configured ingester replication factor; unset counts every series once
+func (c *Client) replicationFactor() int {
+	if c.config == nil || c.config.Mimir.ReplicationFactor < 1 {
+		return 1
+	}
+	return c.config.Mimir.ReplicationFactor
+}
+
+// resolveMetric picks the first candidate whose names all exist; without a probe the first candidate is assumed
+func resolveMetric(logical LogicalMetric, available map[string]bool, probed bool, replicationFactor int) ResolvedMetric {
+	resolved := ResolvedMetric{
+		Logical:           logical.Name,
+		Description:       logical.Description,
+		Candidates:        []string{},
+		ReplicationFactor: replicationFactor,
+	}
+	for _, candidate := range logical.Candidates {
+		families := metricFamilies
+		if candidate.Unprefixed {
+			families = []string{""}
+		}
+		for _, family := range families {
+			name, subtract := family+candidate.Name, ""
+			if candidate.Subtract != "" {
+				subtract = family + candidate.Subtract
+			}
+			if !slices.Contains(resolved.Candidates, name) {
+				resolved.Candidates = append(resolved.Candidates, name)
+			}
+			if resolved.Resolved || (probed && (!available[name] || (subtract != "" && !available[subtract]))) {
+				continue
+			}
+
+			resolved.MetricCandidate = candidate
+			resolved.Name, resolved.Subtract = name, subtract
+			resolved.Family = family
+			resolved.Resolved = true
+		}
+	}
+	if resolved.Resolved {
+		resolved.Query = resolved.TenantQuery("", "")
+	}
+	return resolved
+}
+
+// listMetricNames gets the names of Mimir's own metrics with recent samples in the configured tenant
+func (c *Client) listMetricNames(ctx context.Context, baseURL string) (map[string]bool, error) {
+	families := make([]string, 0, len(metricFamilies))
+	for _, family := range metricFamilies {
+		families = append(families, strings.TrimSuffix(family, "_"))
+	}
+	end := time.Now()
+	params := url.Values{}
+	params.Set("match[]", fmt.Sprintf(`{__name__=~"(%s)_.+|process_.+"}`, strings.Join(families, "|")))
+	params.Set("start", end.Add(-metricNamesLookback).Format(time.RFC3339))
+	params.Set("end", end.Format(time.RFC3339))
+
+	body, err := c.getAPI(ctx, baseURL, "/prometheus/api/v1/label/__name__/values", params, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Status string   `json:"status"`
+		Data   []string `json:"data"`
+		Error  string   `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode metric names: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("listing metric names failed: %s", resp.Error)
+	}
+
+	available := make(map[string]bool, len(resp.Data))
+	for _, name := range resp.Data {
+		available[name] = true
+	}
+	return available, nil
+}
@@ -0,0 +1,242 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+)
+
+func logicalMetric(t *testing.T, name string) LogicalMetric {
+	t.Helper()
+	for _, logical := range LogicalMetrics() {
+		if logical.Name == name {
+			return logical
+		}
+	}
+	t.Fatalf("no logical metric %s", name)
+	return LogicalMetric{}
+}
+
+func available(names ...string) map[string]bool {
+	result := make(map[string]bool, len(names))
+	for _, name := range names {
+		result[name] = true
+	}
+	return result
+}
+
+func TestResolveMetric(t *testing.T) {
+	tests := []struct {
+		name         string
+		logical      string
+		available    map[string]bool
+		probed       bool
+		wantResolved bool
+		wantName     string
+		wantSubtract string
+		wantFamily   string
+		wantQuery    string
+	}{
+		{
+			name:         "without a probe the first cortex_ candidate is assumed",
+			logical:      MetricIngestionRate,
+			wantResolved: true,
+			wantName:     "cortex_distributor_received_samples_total",
+			wantFamily:   "cortex_",
+			wantQuery:    "sum by (user) (rate(cortex_distributor_received_samples_total[5m]))",
+		},
+		{
+			name:         "mimir_ family",
+			logical:      MetricIngestionRate,
+			available:    available("mimir_distributor_received_samples_total"),
+			probed:       true,
+			wantResolved: true,
+			wantName:     "mimir_distributor_received_samples_total",
+			wantFamily:   "mimir_",
+			wantQuery:    "sum by (user) (rate(mimir_distributor_received_samples_total[5m]))",
+		},
+		{
+			name:         "older candidate",
+			logical:      MetricIngestionRate,
+			available:    available("cortex_distributor_ingestion_rate"),
+			probed:       true,
+			wantResolved: true,
+			wantName:     "cortex_distributor_ingestion_rate",
+			wantFamily:   "cortex_",
+			wantQuery:    "sum by (tenant) (cortex_distributor_ingestion_rate)",
+		},
+		{
+			name:         "preferred candidate wins over the family order",
+			logical:      MetricIngestionRate,
+			available:    available("cortex_distributor_samples_in_total", "mimir_distributor_received_samples_total"),
+			probed:       true,
+			wantResolved: true,
+			wantName:     "mimir_distributor_received_samples_total",
+			wantFamily:   "mimir_",
+			wantQuery:    "sum by (user) (rate(mimir_distributor_received_samples_total[5m]))",
+		},
+		{
+			name:         "difference needs both names",
+			logical:      MetricInMemorySeries,
+			available:    available("cortex_ingester_memory_series_created_total", "mimir_ingester_memory_series_created_total", "mimir_ingester_memory_series_removed_total"),
+			probed:       true,
+			wantResolved: true,
+			wantName:     "mimir_ingester_memory_series_created_total",
+			wantSubtract: "mimir_ingester_memory_series_removed_total",
+			wantFamily:   "mimir_",
+			wantQuery:    "sum by (user) (mimir_ingester_memory_series_created_total - mimir_ingester_memory_series_removed_total) / 3",
+		},
+		{
+			name:         "unprefixed metric",
+			logical:      MetricProcessMemory,
+			available:    available("process_resident_memory_bytes"),
+			probed:       true,
+			wantResolved: true,
+			wantName:     "process_resident_memory_bytes",
+			wantQuery:    "process_resident_memory_bytes",
+		},
+		{
+			name:      "not exported",
+			logical:   MetricActiveSeries,
+			available: available("cortex_ingester_memory_series"),
+			probed:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveMetric(logicalMetric(t, tt.logical), tt.available, tt.probed, 3)
+			if got.Resolved != tt.wantResolved || got.Name != tt.wantName || got.Subtract != tt.wantSubtract ||
+				got.Family != tt.wantFamily || got.Query != tt.wantQuery {
+				t.Errorf("resolveMetric() = resolved %v, %q - %q (family %q), query %q; want resolved %v, %q - %q (family %q), query %q",
+					got.Resolved, got.Name, got.Subtract, got.Family, got.Query,
+					tt.wantResolved, tt.wantName, tt.wantSubtract, tt.wantFamily, tt.wantQuery)
+			}
+			if got.Logical != tt.logical || len(got.Candidates) == 0 {
+				t.Errorf("resolveMetric() logical %q with candidates %v", got.Logical, got.Candidates)
+			}
+		})
+	}
+}
+
+func TestResolvedMetricQueries(t *testing.T) {
+	limitsReached := resolveMetric(logicalMetric(t, MetricLimitsReached), nil, false, 3)
+	activeSeries := resolveMetric(logicalMetric(t, MetricActiveSeries), nil, false, 3)
+	unreplicated := resolveMetric(logicalMetric(t, MetricActiveSeries), nil, false, 1)
+	processMemory := resolveMetric(logicalMetric(t, MetricProcessMemory), nil, false, 3)
+	buildInfo := resolveMetric(logicalMetric(t, MetricBuildInfo), nil, false, 3)
+
+	tests := []struct {
+		name   string
+		metric ResolvedMetric
+		tenant string
+		step   string
+		want   string
+		peak   string
+	}{
+		{
+			name:   "counter with matchers, grouping and a tenant",
+			metric: limitsReached,
+			tenant: "team-a",
+			step:   "1h",
+			want:   `sum by (user, reason) (rate(cortex_discarded_samples_total{user="team-a", ` + limitReasons + `}[1h]))`,
+			peak:   `max(max_over_time((sum by (user, reason) (rate(cortex_discarded_samples_total{user="team-a", ` + limitReasons + `}[1h])))[86400s:1h]))`,
+		},
+		{
+			name:   "short steps use the minimum rate window",
+			metric: limitsReached,
+			step:   "30s",
+			want:   `sum by (user, reason) (rate(cortex_discarded_samples_total{` + limitReasons + `}[5m]))`,
+			peak:   `max(max_over_time((sum by (user, reason) (rate(cortex_discarded_samples_total{` + limitReasons + `}[5m])))[86400s:30s]))`,
+		},
+		{
+			name:   "replicated gauge is summed across ingesters and divided by the replication factor",
+			metric: activeSeries,
+			tenant: "team-a",
+			step:   "5m",
+			want:   `sum by (user) (cortex_ingester_active_series{user="team-a"}) / 3`,
+			peak:   `max(max_over_time((sum by (user) (cortex_ingester_active_series{user="team-a"}) / 3)[86400s:5m]))`,
+		},
+		{
+			name:   "replication factor of one is not divided by",
+			metric: unreplicated,
+			tenant: "team-a",
+			step:   "5m",
+			want:   `sum by (user) (cortex_ingester_active_series{user="team-a"})`,
+			peak:   `max(max_over_time((sum by (user) (cortex_ingester_active_series{user="team-a"}))[86400s:5m]))`,
+		},
+		{
+			name:   "per-process gauge stays per instance",
+			metric: processMemory,
+			step:   "5m",
+			want:   `process_resident_memory_bytes`,
+			peak:   `max(max_over_time(process_resident_memory_bytes[86400s]))`,
+		},
+		{
+			name:   "metric without a tenant label ignores the tenant",
+			metric: buildInfo,
+			tenant: "team-a",
+			step:   "5m",
+			want:   `cortex_build_info`,
+			peak:   `max(max_over_time(cortex_build_info[86400s]))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.metric.TenantQuery(tt.tenant, tt.step); got != tt.want {
+				t.Errorf("TenantQuery() = %s, want %s", got, tt.want)
+			}
+			if got := tt.metric.PeakQuery(tt.tenant, 24*time.Hour, tt.step); got != tt.peak {
+				t.Errorf("PeakQuery() = %s, want %s", got, tt.peak)
+			}
+		})
+	}
+}
+
+func TestProbeMetricNames(t *testing.T) {
+	status, body := http.StatusOK, `{"status":"success","data":["mimir_distributor_received_samples_total","mimir_ingester_active_series","process_resident_memory_bytes"]}`
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/prometheus/api/v1/label/__name__/values" || !strings.Contains(r.URL.Query().Get("match[]"), "mimir") {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, config.MimirConfig{})
+	names := client.MetricNames(context.Background())
+	if !names.Probed || strings.Join(names.Families, ",") != "mimir_" {
+		t.Fatalf("names = probed %v, families %v, want probed mimir_", names.Probed, names.Families)
+	}
+	if metric, ok := names.Metric(MetricIngestionRate); !ok || metric.Name != "mimir_distributor_received_samples_total" {
+		t.Errorf("ingestion rate = %+v, %v", metric, ok)
+	}
+	if _, ok := names.Metric(MetricMemoryUsage); ok {
+		t.Error("memory usage resolved, want it missing from this Mimir")
+	}
+
+	// A successful probe is cached
+	client.MetricNames(context.Background())
+	if requests != 1 {
+		t.Errorf("%d probes, want 1", requests)
+	}
+
+	// A failed probe assumes the cortex_ names
+	status, body = http.StatusInternalServerError, "boom"
+	names = client.RefreshMetricNames(context.Background())
+	if names.Probed || names.Error == "" {
+		t.Fatalf("names = probed %v, error %q, want a failed probe", names.Probed, names.Error)
+	}
+	if metric, ok := names.Metric(MetricIngestionRate); !ok || metric.Name != "cortex_distributor_received_samples_total" {
+		t.Errorf("ingestion rate after a failed probe = %+v, %v", metric, ok)
+	}
+}
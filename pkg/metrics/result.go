@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Result types of the Prometheus query API
+const (
+	ResultTypeMatrix = "matrix"
+	ResultTypeVector = "vector"
+	ResultTypeScalar = "scalar"
+	ResultTypeString = "string"
+)
+
+// Error types of the Prometheus query API
+const (
+	ErrorTypeBadData     = "bad_data"
+	ErrorTypeExecution   = "execution"
+	ErrorTypeTimeout     = "timeout"
+	ErrorTypeCanceled    = "canceled"
+	ErrorTypeInternal    = "internal"
+	ErrorTypeUnavailable = "unavailable"
+	ErrorTypeNotFound    = "not_found"
+)
+
+// QueryError is an error returned by the Prometheus query API
+type QueryError struct {
+	StatusCode int
+	Type       string // One of the ErrorType constants, empty when the body carried no error
+	Message    string
+}
+
+func (e *QueryError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("query failed with status %d (%s): %s", e.StatusCode, e.Type, e.Message)
+}
+
+// QueryData is the result of a query; Result holds matrix and vector results, Scalar and String the others
+type QueryData struct {
+	ResultType string        `json:"resultType"`
+	Result     []QuerySeries `json:"result"`
+	Scalar     *SamplePair   `json:"scalar,omitempty"`
+	String     *StringSample `json:"string,omitempty"`
+	Stats      *QueryStats   `json:"stats,omitempty"` // Only when the query asked for statistics
+}
+
+// QuerySeries is one series of a matrix or vector result
+type QuerySeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []SamplePair      `json:"values,omitempty"` // Matrix results
+	Value  *SamplePair       `json:"value,omitempty"`  // Vector results
+}
+
+// SamplePair is a sample, encoded by the API as [unix seconds, "value"]
+type SamplePair struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// StringSample is the result of a string expression
+type StringSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     string    `json:"value"`
+}
+
+// QueryStats are the statistics returned with stats=all
+type QueryStats struct {
+	Timings struct {
+		EvalTotalTime        float64 `json:"evalTotalTime"`
+		ResultSortTime       float64 `json:"resultSortTime"`
+		QueryPreparationTime float64 `json:"queryPreparationTime"`
+		InnerEvalTime        float64 `json:"innerEvalTime"`
+		ExecQueueTime        float64 `json:"execQueueTime"`
+		ExecTotalTime        float64 `json:"execTotalTime"`
+	} `json:"timings"`
+	Samples struct {
+		TotalQueryableSamples int64 `json:"totalQueryableSamples"`
+		PeakSamples           int64 `json:"peakSamples"`
+	} `json:"samples"`
+}
+
+// Samples returns the samples of a series, whether it came from a matrix or a vector
+func (s QuerySeries) Samples() []SamplePair {
+	if s.Value != nil {
+		return []SamplePair{*s.Value}
+	}
+	return s.Values
+}
+
+// UnmarshalJSON decodes the result according to its type
+func (d *QueryData) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+		Stats      *QueryStats     `json:"stats"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*d = QueryData{ResultType: raw.ResultType, Stats: raw.Stats}
+	if len(raw.Result) == 0 || string(raw.Result) == "null" {
+		return nil
+	}
+
+	switch raw.ResultType {
+	case ResultTypeMatrix, ResultTypeVector:
+		return json.Unmarshal(raw.Result, &d.Result)
+	case ResultTypeScalar:
+		d.Scalar = &SamplePair{}
+		return json.Unmarshal(raw.Result, d.Scalar)
+	case ResultTypeString:
+		timestamp, value, err := decodeSample(raw.Result)
+		if err != nil {
+			return err
+		}
+		d.String = &StringSample{Timestamp: timestamp, Value: value}
+		return nil
+	default:
+		return fmt.Errorf("unsupported result type %q", raw.ResultType)
+	}
+}
+
+// UnmarshalJSON decodes a [unix seconds, "value"] pair
+func (p *SamplePair) UnmarshalJSON(data []byte) error {
+	timestamp, text, err := decodeSample(data)
+	if err != nil {
+		return err
+	}
+	// Go parses the "NaN", "+Inf" and "-Inf" the API uses for special values
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fmt.Errorf("invalid sample value %q: %w", text, err)
+	}
+	*p = SamplePair{Timestamp: timestamp, Value: value}
+	return nil
+}
+
+// MarshalJSON encodes the pair the way the API does, which also keeps NaN and infinities encodable
+func (p SamplePair) MarshalJSON() ([]byte, error) {
+	seconds := float64(p.Timestamp.UnixMilli()) / 1000
+	return json.Marshal([]interface{}{seconds, strconv.FormatFloat(p.Value, 'f', -1, 64)})
+}
+
+func decodeSample(data []byte) (time.Time, string, error) {
+	var pair []interface{}
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid sample: %w", err)
+	}
+	if len(pair) != 2 {
+		return time.Time{}, "", fmt.Errorf("invalid sample: expected 2 elements, got %d", len(pair))
+	}
+	seconds, ok := pair[0].(float64)
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("invalid sample timestamp %v", pair[0])
+	}
+	value, ok := pair[1].(string)
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("invalid sample value %v", pair[1])
+	}
+	return time.UnixMilli(int64(math.Round(seconds * 1000))), value, nil
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestQueryDataUnmarshalJSON(t *testing.T) {
+	at := time.UnixMilli(1700000000500)
+
+	tests := []struct {
+		name      string
+		data      string
+		wantError bool
+		check     func(t *testing.T, d QueryData)
+	}{
+		{
+			name: "matrix",
+			data: `{"resultType":"matrix","result":[{"metric":{"user":"team-a"},"values":[[1700000000.5,"1"],[1700000060.5,"2.5"]]}]}`,
+			check: func(t *testing.T, d QueryData) {
+				if len(d.Result) != 1 || d.Result[0].Metric["user"] != "team-a" {
+					t.Fatalf("result = %+v, want one team-a series", d.Result)
+				}
+				samples := d.Result[0].Samples()
+				if len(samples) != 2 || !samples[0].Timestamp.Equal(at) || samples[1].Value != 2.5 {
+					t.Errorf("samples = %+v", samples)
+				}
+			},
+		},
+		{
+			name: "vector",
+			data: `{"resultType":"vector","result":[{"metric":{},"value":[1700000000.5,"42"]},{"metric":{"reason":"rate_limited"},"value":[1700000000.5,"NaN"]}]}`,
+			check: func(t *testing.T, d QueryData) {
+				if len(d.Result) != 2 || d.Result[0].Value == nil || d.Result[0].Value.Value != 42 {
+					t.Fatalf("result = %+v, want two samples starting with 42", d.Result)
+				}
+				if samples := d.Result[1].Samples(); len(samples) != 1 || !math.IsNaN(samples[0].Value) {
+					t.Errorf("samples = %+v, want NaN", samples)
+				}
+			},
+		},
+		{
+			name: "empty vector",
+			data: `{"resultType":"vector","result":[]}`,
+			check: func(t *testing.T, d QueryData) {
+				if len(d.Result) != 0 || d.Scalar != nil || d.String != nil {
+					t.Errorf("data = %+v, want no result", d)
+				}
+			},
+		},
+		{
+			name: "scalar",
+			data: `{"resultType":"scalar","result":[1700000000.5,"+Inf"]}`,
+			check: func(t *testing.T, d QueryData) {
+				if d.Scalar == nil || !math.IsInf(d.Scalar.Value, 1) || !d.Scalar.Timestamp.Equal(at) || d.Result != nil {
+					t.Errorf("data = %+v, want the +Inf scalar only", d)
+				}
+			},
+		},
+		{
+			name: "string",
+			data: `{"resultType":"string","result":[1700000000.5,"hello"]}`,
+			check: func(t *testing.T, d QueryData) {
+				if d.String == nil || d.String.Value != "hello" || !d.String.Timestamp.Equal(at) {
+					t.Errorf("data = %+v, want the string sample", d)
+				}
+			},
+		},
+		{
+			name: "stats",
+			data: `{"resultType":"vector","result":[],"stats":{"timings":{"evalTotalTime":0.25},"samples":{"totalQueryableSamples":1200,"peakSamples":30}}}`,
+			check: func(t *testing.T, d QueryData) {
+				if d.Stats == nil || d.Stats.Timings.EvalTotalTime != 0.25 || d.Stats.Samples.TotalQueryableSamples != 1200 {
+					t.Errorf("stats = %+v", d.Stats)
+				}
+			},
+		},
+		{
+			name: "null result",
+			data: `{"resultType":"matrix","result":null}`,
+			check: func(t *testing.T, d QueryData) {
+				if d.ResultType != ResultTypeMatrix || d.Result != nil {
+					t.Errorf("data = %+v, want an empty matrix", d)
+				}
+			},
+		},
+		{name: "unknown type", data: `{"resultType":"histogram","result":[]}`, wantError: true},
+		{name: "bad sample value", data: `{"resultType":"scalar","result":[1700000000,"many"]}`, wantError: true},
+		{name: "sample without a value", data: `{"resultType":"vector","result":[{"metric":{},"value":[1700000000]}]}`, wantError: true},
+		{name: "numeric sample value", data: `{"resultType":"scalar","result":[1700000000,1]}`, wantError: true},
+		{name: "string timestamp", data: `{"resultType":"string","result":["now","x"]}`, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d QueryData
+			err := json.Unmarshal([]byte(tt.data), &d)
+			if tt.wantError {
+				if err == nil {
+					t.Fatalf("Unmarshal() error = nil, data %+v", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			tt.check(t, d)
+		})
+	}
+}
+
+func TestSamplePairRoundTrip(t *testing.T) {
+	for _, value := range []float64{0, 1.5, -3, math.Inf(1), math.Inf(-1), math.NaN()} {
+		original := SamplePair{Timestamp: time.UnixMilli(1700000000123), Value: value}
+		encoded, err := json.Marshal(original)
+		if err != nil {
+			t.Fatalf("Marshal(%v) error = %v", value, err)
+		}
+		var decoded SamplePair
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", encoded, err)
+		}
+		sameValue := decoded.Value == value || (math.IsNaN(value) && math.IsNaN(decoded.Value))
+		if !decoded.Timestamp.Equal(original.Timestamp) || !sameValue {
+			t.Errorf("round trip of %s = %+v, want %+v", encoded, decoded, original)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TenantSeparator joins the tenants of a federated query in X-Scope-OrgID
+const TenantSeparator = "|"
+
+// maxTenantIDLength is the longest tenant ID Mimir accepts
+const maxTenantIDLength = 150
+
+var (
+	// ErrTenantNotAllowed is returned when a query targets a tenant outside mimir.allowed_tenants
+	ErrTenantNotAllowed = errors.New("tenant not allowed")
+	// ErrFederationDisabled is returned for multi-tenant queries unless mimir.tenant_federation is set
+	ErrFederationDisabled = errors.New("tenant federation is disabled")
+)
+
+// FederatedTenant joins tenant IDs for a query across all of them
+func FederatedTenant(tenants ...string) string {
+	return strings.Join(tenants, TenantSeparator)
+}
+
+// TenantAllowed reports whether queries may be sent with the tenant's org ID
+func (c *Client) TenantAllowed(tenant string) bool {
+	if tenant == c.config.Mimir.OrgID {
+		return true
+	}
+	for _, allowed := range c.config.Mimir.AllowedTenants {
+		if allowed == "*" || allowed == tenant {
+			return true
+		}
+	}
+	return false
+}
+
+// orgID returns the X-Scope-OrgID header for a query's tenant, which may list several tenants separated by "|".
+// Queries without a tenant use the configured org ID.
+func (c *Client) orgID(tenant string) (string, error) {
+	if tenant == "" {
+		return c.config.Mimir.OrgID, nil
+	}
+
+	tenants := strings.Split(tenant, TenantSeparator)
+	if len(tenants) > 1 && !c.config.Mimir.TenantFederation {
+		return "", fmt.Errorf("%w: cannot query %s", ErrFederationDisabled, tenant)
+	}
+	seen := make(map[string]bool, len(tenants))
+	unique := make([]string, 0, len(tenants))
+	for _, id := range tenants {
+		if err := validateTenantID(id); err != nil {
+			return "", err
+		}
+		if !c.TenantAllowed(id) {
+			return "", fmt.Errorf("%w: %s", ErrTenantNotAllowed, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return FederatedTenant(unique...), nil
+}
+
+// validateTenantID applies Mimir's tenant ID rules so bad IDs fail before reaching the API
+func validateTenantID(id string) error {
+	if id == "" {
+		return fmt.Errorf("empty tenant ID")
+	}
+	if len(id) > maxTenantIDLength {
+		return fmt.Errorf("tenant ID %q is longer than %d characters", id, maxTenantIDLength)
+	}
+	if id == "." || id == ".." {
+		return fmt.Errorf("tenant ID %q is not allowed", id)
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!-_.*'()", r):
+		default:
+			return fmt.Errorf("tenant ID %q contains unsupported character %q", id, r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+)
+
+func newTestClient(baseURL string, mimir config.MimirConfig) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: http.DefaultClient,
+		config:     &config.Config{Mimir: mimir},
+	}
+}
+
+func TestOrgID(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowed    []string
+		federation bool
+		tenant     string
+		want       string
+		wantErr    error
+		wantBadID  bool
+	}{
+		{name: "no tenant uses org_id", tenant: "", want: "monitoring"},
+		{name: "org_id is always allowed", tenant: "monitoring", want: "monitoring"},
+		{name: "allow-listed tenant", allowed: []string{"team-a"}, tenant: "team-a", want: "team-a"},
+		{name: "tenant outside the allow-list", allowed: []string{"team-a"}, tenant: "team-b", wantErr: ErrTenantNotAllowed},
+		{name: "empty allow-list", tenant: "team-a", wantErr: ErrTenantNotAllowed},
+		{name: "wildcard", allowed: []string{"*"}, tenant: "team-b", want: "team-b"},
+		{name: "federation disabled", allowed: []string{"*"}, tenant: "team-a|team-b", wantErr: ErrFederationDisabled},
+		{name: "federation deduplicates", allowed: []string{"*"}, federation: true, tenant: "team-a|team-b|team-a", want: "team-a|team-b"},
+		{name: "federation checks every tenant", allowed: []string{"team-a"}, federation: true, tenant: "team-a|team-b", wantErr: ErrTenantNotAllowed},
+		{name: "invalid tenant ID", allowed: []string{"*"}, tenant: "team/a", wantBadID: true},
+		{name: "empty federated member", allowed: []string{"*"}, federation: true, tenant: "team-a|", wantBadID: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient("", config.MimirConfig{
+				OrgID:            "monitoring",
+				AllowedTenants:   tt.allowed,
+				TenantFederation: tt.federation,
+			})
+			got, err := client.orgID(tt.tenant)
+			switch {
+			case tt.wantBadID:
+				if err == nil || errors.Is(err, ErrTenantNotAllowed) || errors.Is(err, ErrFederationDisabled) {
+					t.Fatalf("orgID(%q) error = %v, want validation error", tt.tenant, err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("orgID(%q) error = %v, want %v", tt.tenant, err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("orgID(%q) error = %v", tt.tenant, err)
+			case got != tt.want:
+				t.Errorf("orgID(%q) = %q, want %q", tt.tenant, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTenantID(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{id: "team-a"},
+		{id: "Team_A.prod"},
+		{id: "a!b*c'(d)"},
+		{id: "0"},
+		{id: strings.Repeat("a", maxTenantIDLength)},
+		{id: "", wantErr: true},
+		{id: ".", wantErr: true},
+		{id: "..", wantErr: true},
+		{id: "team a", wantErr: true},
+		{id: "team/a", wantErr: true},
+		{id: "team|a", wantErr: true},
+		{id: "tenänt", wantErr: true},
+		{id: strings.Repeat("a", maxTenantIDLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := validateTenantID(tt.id); (err != nil) != tt.wantErr {
+			t.Errorf("validateTenantID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+		}
+	}
+}
+
+// Self-monitoring series live in the configured org, so per-tenant metric queries must not switch org IDs
+func TestSelfMonitoringQueriesUseConfiguredOrg(t *testing.T) {
+	var mu sync.Mutex
+	orgIDs := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		orgIDs[r.Header.Get("X-Scope-OrgID")] = true
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/label/__name__/values") {
+			w.Write([]byte(`{"status":"success","data":["cortex_distributor_received_samples_total","cortex_ingester_active_series"]}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"42"]}]}}`))
+	}))
+	defer server.Close()
+
+	// team-a is not allow-listed, which must not matter for self-monitoring queries
+	client := newTestClient(server.URL, config.MimirConfig{OrgID: "monitoring"})
+	ctx := context.Background()
+	end := time.Now()
+	timeRange := TimeRange{Start: end.Add(-time.Hour), End: end, Step: "1m"}
+
+	if _, err := client.GetTenantMetric(ctx, MetricIngestionRate, "team-a", timeRange); err != nil {
+		t.Fatalf("GetTenantMetric() error = %v", err)
+	}
+	peaks, err := client.GetPeakValues(ctx, "team-a", timeRange)
+	if err != nil {
+		t.Fatalf("GetPeakValues() error = %v", err)
+	}
+	if peaks[MetricActiveSeries] != 42 {
+		t.Errorf("GetPeakValues()[%s] = %v, want 42", MetricActiveSeries, peaks[MetricActiveSeries])
+	}
+	if _, err := client.GetTenantMetrics(ctx, "team-a", timeRange); err != nil {
+		t.Fatalf("GetTenantMetrics() error = %v", err)
+	}
+
+	if len(orgIDs) != 1 || !orgIDs["monitoring"] {
+		t.Errorf("X-Scope-OrgID headers = %v, want only monitoring", orgIDs)
+	}
+}
@@ -0,0 +1,307 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sources of TenantUsage values
+const (
+	UsageSourceUserStats   = "distributor_user_stats"
+	UsageSourceCardinality = "cardinality"
+)
+
+// Count methods of the cardinality APIs
+const (
+	CountMethodInMemory = "inmemory" // Series in the ingesters' memory
+	CountMethodActive   = "active"   // Series that received samples within the active series idle timeout
+)
+
+// UserStats is a tenant's row of the distributor's /distributor/all_user_stats page.
+// Ingesters report every replica, so the values are multiplied by the replication factor.
+type UserStats struct {
+	Tenant            string  `json:"userID"`
+	IngestionRate     float64 `json:"ingestionRate"`
+	NumSeries         uint64  `json:"numSeries"`
+	APIIngestionRate  float64 `json:"APIIngestionRate"`
+	RuleIngestionRate float64 `json:"RuleIngestionRate"`
+}
+
+// AllUserStats are the ingestion stats of every tenant
+type AllUserStats struct {
+	Stats             []UserStats `json:"stats"`
+	ReplicationFactor int         `json:"replicationFactor,omitempty"` // Zero when Mimir does not report it; mimir.replication_factor is assumed then
+}
+
+// LabelNamesCardinality is the response of the label_names cardinality API
+type LabelNamesCardinality struct {
+	LabelValuesCountTotal int64                  `json:"label_values_count_total"`
+	LabelNamesCount       int64                  `json:"label_names_count"`
+	Cardinality           []LabelNameCardinality `json:"cardinality"`
+}
+
+// LabelNameCardinality is the number of values of one label name
+type LabelNameCardinality struct {
+	LabelName        string `json:"label_name"`
+	LabelValuesCount int64  `json:"label_values_count"`
+}
+
+// LabelValuesCardinality is the response of the label_values cardinality API
+type LabelValuesCardinality struct {
+	SeriesCountTotal int64             `json:"series_count_total"`
+	Labels           []LabelValuesInfo `json:"labels"`
+}
+
+// LabelValuesInfo is the series count of one label name and of its top values
+type LabelValuesInfo struct {
+	LabelName        string                  `json:"label_name"`
+	LabelValuesCount int64                   `json:"label_values_count"`
+	SeriesCount      int64                   `json:"series_count"`
+	Cardinality      []LabelValueCardinality `json:"cardinality"`
+}
+
+// LabelValueCardinality is the number of series with one label value
+type LabelValueCardinality struct {
+	LabelValue  string `json:"label_value"`
+	SeriesCount int64  `json:"series_count"`
+}
+
+// CardinalityRequest narrows a cardinality query
+type CardinalityRequest struct {
+	Selector    string `json:"selector,omitempty"`     // Series selector, e.g. {job="api"}
+	Limit       int    `json:"limit,omitempty"`        // Entries returned per list; Mimir defaults to 20
+	CountMethod string `json:"count_method,omitempty"` // CountMethodInMemory (default) or CountMethodActive
+}
+
+// TenantUsage is a tenant's current usage as reported by Mimir's own APIs rather than self-monitoring metrics
+type TenantUsage struct {
+	Tenant        string   `json:"tenant"`
+	IngestionRate float64  `json:"ingestion_rate"` // Samples per second
+	ActiveSeries  int64    `json:"active_series"`
+	Sources       []string `json:"sources"`
+}
+
+// Value returns the usage for a metric type of TenantMetrics, and whether any API reported it
+func (u *TenantUsage) Value(metricType string) (float64, bool) {
+	hasStats := u.hasSource(UsageSourceUserStats)
+	switch metricType {
+	case MetricIngestionRate:
+		return u.IngestionRate, hasStats
+	case MetricActiveSeries:
+		return float64(u.ActiveSeries), hasStats || u.hasSource(UsageSourceCardinality)
+	default:
+		return 0, false
+	}
+}
+
+func (u *TenantUsage) hasSource(source string) bool {
+	for _, s := range u.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAllUserStats gets the ingestion stats of all tenants from the distributor
+func (c *Client) GetAllUserStats(ctx context.Context) (*AllUserStats, error) {
+	body, err := c.getAPI(ctx, c.baseURL, "/distributor/all_user_stats", nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Mimir answers JSON requests with the bare list; the page contents also carry the replication factor
+	stats := &AllUserStats{}
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		err = json.Unmarshal(body, &stats.Stats)
+	} else {
+		err = json.Unmarshal(body, stats)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode user stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetIngesterTenants lists the tenants with a TSDB on the ingester behind mimir.ingester_url.
+// Each ingester only knows the tenants of its own shard.
+func (c *Client) GetIngesterTenants(ctx context.Context) ([]string, error) {
+	baseURL := c.config.Mimir.IngesterURL
+	if baseURL == "" {
+		baseURL = c.baseURL
+	}
+	body, err := c.getAPI(ctx, baseURL, "/ingester/tenants", nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var page struct {
+		Tenants []string `json:"tenants"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to decode ingester tenants: %w", err)
+	}
+	return page.Tenants, nil
+}
+
+// GetLabelNamesCardinality counts the values of each label name of a tenant's series.
+// The tenant needs cardinality_analysis_enabled in its limits.
+func (c *Client) GetLabelNamesCardinality(ctx context.Context, tenant string, req CardinalityRequest) (*LabelNamesCardinality, error) {
+	body, err := c.getAPI(ctx, c.baseURL, "/prometheus/api/v1/cardinality/label_names", req.params(), tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	var cardinality LabelNamesCardinality
+	if err := json.Unmarshal(body, &cardinality); err != nil {
+		return nil, fmt.Errorf("failed to decode label names cardinality: %w", err)
+	}
+	return &cardinality, nil
+}
+
+// GetLabelValuesCardinality counts a tenant's series per value of each label name
+func (c *Client) GetLabelValuesCardinality(ctx context.Context, tenant string, labelNames []string, req CardinalityRequest) (*LabelValuesCardinality, error) {
+	if len(labelNames) == 0 {
+		return nil, fmt.Errorf("at least one label name is required")
+	}
+	params := req.params()
+	for _, name := range labelNames {
+		params.Add("label_names[]", name)
+	}
+	body, err := c.getAPI(ctx, c.baseURL, "/prometheus/api/v1/cardinality/label_values", params, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	var cardinality LabelValuesCardinality
+	if err := json.Unmarshal(body, &cardinality); err != nil {
+		return nil, fmt.Errorf("failed to decode label values cardinality: %w", err)
+	}
+	return &cardinality, nil
+}
+
+// GetActiveSeriesCount counts a tenant's active series with the cardinality API
+func (c *Client) GetActiveSeriesCount(ctx context.Context, tenant string) (int64, error) {
+	// Every series has a name, so the total over __name__ is the tenant's series count
+	cardinality, err := c.GetLabelValuesCardinality(ctx, tenant, []string{"__name__"},
+		CardinalityRequest{Limit: 1, CountMethod: CountMethodActive})
+	if err != nil {
+		return 0, err
+	}
+	return cardinality.SeriesCountTotal, nil
+}
+
+// GetTenantUsage gets a tenant's ingestion rate and active series from the distributor's user stats
+// and the cardinality API, for clusters that don't scrape Mimir's own metrics.
+// The user stats come from the configured org; the cardinality API needs the tenant's own org ID.
+func (c *Client) GetTenantUsage(ctx context.Context, tenant string) (*TenantUsage, error) {
+	if err := validateTenantID(tenant); err != nil {
+		return nil, err
+	}
+
+	usage := &TenantUsage{Tenant: tenant, Sources: []string{}}
+	var errs []error
+
+	if allStats, err := c.GetAllUserStats(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("user stats: %w", err))
+	} else {
+		replicationFactor := allStats.ReplicationFactor
+		if replicationFactor <= 0 {
+			replicationFactor = c.replicationFactor()
+		}
+		for _, stats := range allStats.Stats {
+			if stats.Tenant == tenant {
+				usage.IngestionRate = stats.IngestionRate / float64(replicationFactor)
+				usage.ActiveSeries = int64(stats.NumSeries) / int64(replicationFactor)
+				usage.Sources = append(usage.Sources, UsageSourceUserStats)
+				break
+			}
+		}
+	}
+
+	// The cardinality API deduplicates replicas, so its count wins over the user stats
+	if activeSeries, err := c.GetActiveSeriesCount(ctx, tenant); err != nil {
+		errs = append(errs, fmt.Errorf("cardinality: %w", err))
+	} else {
+		usage.ActiveSeries = activeSeries
+		usage.Sources = append(usage.Sources, UsageSourceCardinality)
+	}
+
+	if len(usage.Sources) == 0 {
+		if len(errs) == 0 {
+			return nil, fmt.Errorf("no usage reported for tenant %s", tenant)
+		}
+		return nil, fmt.Errorf("no usage reported for tenant %s: %w", tenant, errors.Join(errs...))
+	}
+	for _, err := range errs {
+		logrus.Debugf("Partial usage for %s: %v", tenant, err)
+	}
+	return usage, nil
+}
+
+func (r CardinalityRequest) params() url.Values {
+	params := url.Values{}
+	if r.Selector != "" {
+		params.Set("selector", r.Selector)
+	}
+	if r.Limit > 0 {
+		params.Set("limit", strconv.Itoa(r.Limit))
+	}
+	if r.CountMethod != "" {
+		params.Set("count_method", r.CountMethod)
+	}
+	return params
+}
+
+// getAPI calls one of Mimir's JSON HTTP APIs as the tenant and returns the body
+func (c *Client) getAPI(ctx context.Context, baseURL, path string, params url.Values, tenant string) ([]byte, error) {
+	orgID, err := c.orgID(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(strings.TrimRight(baseURL, "/") + path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	// Mimir renders its admin pages as HTML unless JSON is asked for
+	req.Header.Set("Accept", "application/json")
+	if orgID != "" {
+		req.Header.Set("X-Scope-OrgID", orgID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := strings.TrimSpace(string(body))
+		if len(message) > 256 {
+			message = message[:256]
+		}
+		return nil, fmt.Errorf("%s returned status %d: %s", path, resp.StatusCode, message)
+	}
+	return body, nil
+}
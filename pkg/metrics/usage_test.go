@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+)
+
+func TestGetAllUserStats(t *testing.T) {
+	tests := []struct {
+		name                  string
+		body                  string
+		wantTenants           []string
+		wantReplicationFactor int
+		wantErr               bool
+	}{
+		{
+			name:        "bare list",
+			body:        `[{"userID":"team-a","ingestionRate":30,"numSeries":300},{"userID":"team-b","ingestionRate":6,"numSeries":60}]`,
+			wantTenants: []string{"team-a", "team-b"},
+		},
+		{
+			name:                  "page with the replication factor",
+			body:                  ` {"stats":[{"userID":"team-a","ingestionRate":30,"numSeries":300}],"replicationFactor":3}`,
+			wantTenants:           []string{"team-a"},
+			wantReplicationFactor: 3,
+		},
+		{
+			name:    "HTML page",
+			body:    `<html><body>Usage stats</body></html>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/distributor/all_user_stats" || r.Header.Get("Accept") != "application/json" {
+					t.Errorf("unexpected request %s (Accept %q)", r.URL, r.Header.Get("Accept"))
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			stats, err := newTestClient(server.URL, config.MimirConfig{}).GetAllUserStats(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetAllUserStats() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var tenants []string
+			for _, s := range stats.Stats {
+				tenants = append(tenants, s.Tenant)
+			}
+			if !slices.Equal(tenants, tt.wantTenants) || stats.ReplicationFactor != tt.wantReplicationFactor {
+				t.Errorf("GetAllUserStats() = tenants %v, replication factor %d; want %v, %d",
+					tenants, stats.ReplicationFactor, tt.wantTenants, tt.wantReplicationFactor)
+			}
+		})
+	}
+}
+
+func TestGetTenantUsage(t *testing.T) {
+	const (
+		bareList = `[{"userID":"team-a","ingestionRate":30,"numSeries":300}]`
+		page     = `{"stats":[{"userID":"team-a","ingestionRate":30,"numSeries":300}],"replicationFactor":2}`
+	)
+
+	tests := []struct {
+		name              string
+		userStats         string // Empty fails the request
+		cardinality       string // Empty fails the request
+		replicationFactor int
+		wantRate          float64
+		wantSeries        int64
+		wantSources       []string
+		wantErr           bool
+	}{
+		{
+			name:        "reported replication factor",
+			userStats:   page,
+			wantRate:    15,
+			wantSeries:  150,
+			wantSources: []string{UsageSourceUserStats},
+		},
+		{
+			name:              "reported replication factor wins over the configured one",
+			userStats:         page,
+			replicationFactor: 3,
+			wantRate:          15,
+			wantSeries:        150,
+			wantSources:       []string{UsageSourceUserStats},
+		},
+		{
+			name:              "bare list uses the configured replication factor",
+			userStats:         bareList,
+			replicationFactor: 3,
+			wantRate:          10,
+			wantSeries:        100,
+			wantSources:       []string{UsageSourceUserStats},
+		},
+		{
+			name:        "unconfigured replication factor counts every series once",
+			userStats:   bareList,
+			wantRate:    30,
+			wantSeries:  300,
+			wantSources: []string{UsageSourceUserStats},
+		},
+		{
+			name:              "cardinality count wins for active series",
+			userStats:         bareList,
+			cardinality:       `{"series_count_total":120,"labels":[]}`,
+			replicationFactor: 3,
+			wantRate:          10,
+			wantSeries:        120,
+			wantSources:       []string{UsageSourceUserStats, UsageSourceCardinality},
+		},
+		{
+			name:        "cardinality only",
+			userStats:   `[]`,
+			cardinality: `{"series_count_total":120,"labels":[]}`,
+			wantSeries:  120,
+			wantSources: []string{UsageSourceCardinality},
+		},
+		{
+			name:      "no source reports the tenant",
+			userStats: `[{"userID":"team-b","ingestionRate":30,"numSeries":300}]`,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := ""
+				switch r.URL.Path {
+				case "/distributor/all_user_stats":
+					body = tt.userStats
+				case "/prometheus/api/v1/cardinality/label_values":
+					if got := r.Header.Get("X-Scope-OrgID"); got != "team-a" {
+						t.Errorf("cardinality queried as %q, want the tenant", got)
+					}
+					body = tt.cardinality
+				}
+				if body == "" {
+					http.Error(w, "unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL, config.MimirConfig{
+				OrgID:             "monitoring",
+				AllowedTenants:    []string{"*"},
+				ReplicationFactor: tt.replicationFactor,
+			})
+			usage, err := client.GetTenantUsage(context.Background(), "team-a")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetTenantUsage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if usage.IngestionRate != tt.wantRate || usage.ActiveSeries != tt.wantSeries || !slices.Equal(usage.Sources, tt.wantSources) {
+				t.Errorf("GetTenantUsage() = rate %v, series %d from %v; want %v, %d from %v",
+					usage.IngestionRate, usage.ActiveSeries, usage.Sources, tt.wantRate, tt.wantSeries, tt.wantSources)
+			}
+		})
+	}
+}
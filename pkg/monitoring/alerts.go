@@ -5,15 +5,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// alertHistoryRetention is how long triggered and resolved alerts are kept
+	alertHistoryRetention = 30 * 24 * time.Hour
+	// alertPruneInterval is how often recording an alert also removes expired history
+	alertPruneInterval = time.Hour
+)
+
 // AlertManager handles alerting and notifications
 type AlertManager struct {
-	config       HealthConfig
-	activeAlerts map[string]*ActiveAlert
+	config HealthConfig
+	store  storage.Store
+
+	pruneMu   sync.Mutex
+	lastPrune time.Time
 }
 
 // ActiveAlert represents an active alert
@@ -54,17 +66,23 @@ type Field struct {
 // NewAlertManager creates a new alert manager
 func NewAlertManager(config HealthConfig) *AlertManager {
 	return &AlertManager{
-		config:       config,
-		activeAlerts: make(map[string]*ActiveAlert),
+		config: config,
+		store:  storage.Get(),
 	}
 }
 
 // TriggerAlert triggers an alert through configured channels
 func (am *AlertManager) TriggerAlert(alert ActiveAlert) {
+	if alert.Timestamp.IsZero() {
+		alert.Timestamp = time.Now()
+	}
 	logrus.Warnf("Alert triggered: %s - %s", alert.Name, alert.Description)
 
 	// Store active alert
-	am.activeAlerts[alert.Name] = &alert
+	if err := storage.PutJSON(am.store, storage.BucketActiveAlerts, alert.Name, alert); err != nil {
+		logrus.Errorf("Failed to store active alert %s: %v", alert.Name, err)
+	}
+	am.recordHistory(alert)
 
 	// Send to Slack if configured
 	if am.config.SlackWebhookURL != "" {
@@ -182,9 +200,14 @@ func (am *AlertManager) sendPagerDutyAlert(alert ActiveAlert) {
 
 // ResolveAlert resolves an active alert
 func (am *AlertManager) ResolveAlert(alertName string) {
-	if alert, exists := am.activeAlerts[alertName]; exists {
+	var alert ActiveAlert
+	if err := storage.GetJSON(am.store, storage.BucketActiveAlerts, alertName, &alert); err == nil {
 		alert.Status = "resolved"
-		delete(am.activeAlerts, alertName)
+		alert.Timestamp = time.Now()
+		if err := am.store.Delete(storage.BucketActiveAlerts, alertName); err != nil {
+			logrus.Errorf("Failed to delete active alert %s: %v", alertName, err)
+		}
+		am.recordHistory(alert)
 
 		logrus.Infof("Alert resolved: %s", alertName)
 
@@ -226,15 +249,93 @@ func (am *AlertManager) sendPagerDutyResolution(alertName, component string) {
 // GetActiveAlerts returns all active alerts
 func (am *AlertManager) GetActiveAlerts() []*ActiveAlert {
 	var alerts []*ActiveAlert
-	for _, alert := range am.activeAlerts {
-		alerts = append(alerts, alert)
+	err := am.store.ForEach(storage.BucketActiveAlerts, func(key string, value []byte) error {
+		var alert ActiveAlert
+		if err := storage.DecodeJSON(value, &alert); err != nil {
+			logrus.Warnf("Skipping unreadable active alert %s: %v", key, err)
+			return nil
+		}
+		alerts = append(alerts, &alert)
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to list active alerts: %v", err)
 	}
 	return alerts
 }
 
-// GetAlertHistory returns alert history (placeholder)
+// GetAlertHistory returns triggered and resolved alerts from the last hours, newest first
 func (am *AlertManager) GetAlertHistory(hours int) []ActiveAlert {
-	// In production, this would query a database or log store
-	// For now, return empty slice
-	return []ActiveAlert{}
+	cutoff := time.Now().Add(-time.Duration(hours) * time.Hour)
+	history := []ActiveAlert{}
+
+	// Keys are in chronological order, so reading backwards can stop at the first alert before the cutoff
+	err := am.store.ForEachReverse(storage.BucketAlertHistory, "", func(key string, value []byte) error {
+		var alert ActiveAlert
+		if err := storage.DecodeJSON(value, &alert); err != nil {
+			logrus.Warnf("Skipping unreadable alert history entry %s: %v", key, err)
+			return nil
+		}
+		if !alert.Timestamp.After(cutoff) {
+			return storage.ErrStopIteration
+		}
+		history = append(history, alert)
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to read alert history: %v", err)
+	}
+	return history
+}
+
+// PruneHistory removes alert history older than the retention and returns how many entries were removed
+func (am *AlertManager) PruneHistory(now time.Time) int {
+	cutoff := historyKey(now.Add(-alertHistoryRetention))
+
+	var expired []string
+	err := am.store.ForEach(storage.BucketAlertHistory, func(key string, value []byte) error {
+		if key >= cutoff {
+			return storage.ErrStopIteration
+		}
+		expired = append(expired, key)
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to scan alert history: %v", err)
+		return 0
+	}
+
+	for _, key := range expired {
+		if err := am.store.Delete(storage.BucketAlertHistory, key); err != nil {
+			logrus.Errorf("Failed to prune alert history entry %s: %v", key, err)
+		}
+	}
+	return len(expired)
+}
+
+// recordHistory appends an alert event to the alert history, pruning expired events at most once per alertPruneInterval
+func (am *AlertManager) recordHistory(alert ActiveAlert) {
+	key := historyKey(alert.Timestamp) + "_" + alert.Name
+	if err := storage.PutJSON(am.store, storage.BucketAlertHistory, key, alert); err != nil {
+		logrus.Errorf("Failed to record alert history for %s: %v", alert.Name, err)
+	}
+
+	now := time.Now()
+	am.pruneMu.Lock()
+	due := now.Sub(am.lastPrune) >= alertPruneInterval
+	if due {
+		am.lastPrune = now
+	}
+	am.pruneMu.Unlock()
+	if !due {
+		return
+	}
+	if pruned := am.PruneHistory(now); pruned > 0 {
+		logrus.Debugf("Pruned %d alert history entries older than %v", pruned, alertHistoryRetention)
+	}
+}
+
+// historyKey zero-pads the timestamp so history keys sort chronologically
+func historyKey(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
 }
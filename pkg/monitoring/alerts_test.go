@@ -0,0 +1,73 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/storage"
+)
+
+func historyNames(alerts []ActiveAlert) []string {
+	names := []string{}
+	for _, alert := range alerts {
+		names = append(names, alert.Name)
+	}
+	return names
+}
+
+func TestGetAlertHistory(t *testing.T) {
+	now := time.Now()
+	am := &AlertManager{store: storage.NewMemoryStore(), lastPrune: now}
+	for _, alert := range []ActiveAlert{
+		{Name: "90m", Timestamp: now.Add(-90 * time.Minute)},
+		{Name: "150m", Timestamp: now.Add(-150 * time.Minute)},
+		{Name: "30m", Timestamp: now.Add(-30 * time.Minute)},
+	} {
+		am.recordHistory(alert)
+	}
+
+	tests := []struct {
+		hours int
+		want  []string
+	}{
+		{hours: 1, want: []string{"30m"}},
+		{hours: 2, want: []string{"30m", "90m"}},
+		{hours: 3, want: []string{"30m", "90m", "150m"}},
+	}
+	for _, tt := range tests {
+		got := historyNames(am.GetAlertHistory(tt.hours))
+		if len(got) != len(tt.want) {
+			t.Errorf("GetAlertHistory(%d) = %v, want %v", tt.hours, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("GetAlertHistory(%d) = %v, want %v", tt.hours, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestAlertHistoryRetention(t *testing.T) {
+	now := time.Now()
+	am := &AlertManager{store: storage.NewMemoryStore(), lastPrune: now}
+	for _, age := range []time.Duration{40 * 24 * time.Hour, 31 * 24 * time.Hour, 29 * 24 * time.Hour} {
+		am.recordHistory(ActiveAlert{Name: age.String(), Timestamp: now.Add(-age)})
+	}
+
+	if pruned := am.PruneHistory(now); pruned != 2 {
+		t.Errorf("PruneHistory() = %d, want the 2 alerts older than %v", pruned, alertHistoryRetention)
+	}
+	if got := historyNames(am.GetAlertHistory(40 * 24)); len(got) != 1 || got[0] != (29*24*time.Hour).String() {
+		t.Errorf("history after pruning = %v, want only the recent alert", got)
+	}
+
+	// Recording prunes once the interval has passed
+	am.recordHistory(ActiveAlert{Name: "expired", Timestamp: now.Add(-40 * 24 * time.Hour)})
+	am.lastPrune = now.Add(-alertPruneInterval)
+	am.recordHistory(ActiveAlert{Name: "new", Timestamp: now})
+	if got := historyNames(am.GetAlertHistory(40 * 24)); len(got) != 2 || got[0] != "new" {
+		t.Errorf("history after recording = %v, want the expired alert pruned", got)
+	}
+}
@@ -323,6 +323,11 @@ func (hc *HealthChecker) calculateOverallStatus(results map[string]HealthResult)
 	return StatusHealthy
 }
 
+// GetAlertManager returns the alert manager used for notifications
+func (hc *HealthChecker) GetAlertManager() *AlertManager {
+	return hc.alertManager
+}
+
 // checkForAlerts checks for alerting conditions
 func (hc *HealthChecker) checkForAlerts(results map[string]HealthResult) []ActiveAlert {
 	var alerts []ActiveAlert
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is an in-memory Store, used for tests and ephemeral deployments
+type MemoryStore struct {
+	buckets map[string]map[string][]byte
+	mutex   sync.RWMutex
+}
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]map[string][]byte),
+	}
+}
+
+// Put stores a value under key in bucket
+func (s *MemoryStore) Put(bucket, key string, value []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string][]byte)
+	}
+	s.buckets[bucket][key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Get returns the value stored under key, or ErrNotFound
+func (s *MemoryStore) Get(bucket, key string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	value, exists := s.buckets[bucket][key]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Delete removes key from bucket
+func (s *MemoryStore) Delete(bucket, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.buckets[bucket], key)
+	return nil
+}
+
+// ForEach calls fn for every key in bucket in ascending key order
+func (s *MemoryStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	keys, values := s.snapshot(bucket, "")
+	sort.Strings(keys)
+	return s.iterate(keys, values, fn)
+}
+
+// ForEachReverse calls fn for every key in bucket below before (all keys when empty) in descending key order
+func (s *MemoryStore) ForEachReverse(bucket, before string, fn func(key string, value []byte) error) error {
+	keys, values := s.snapshot(bucket, before)
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	return s.iterate(keys, values, fn)
+}
+
+// snapshot copies the entries of bucket with keys below before (all keys when empty)
+func (s *MemoryStore) snapshot(bucket, before string) ([]string, map[string][]byte) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]string, 0, len(s.buckets[bucket]))
+	values := make(map[string][]byte, len(s.buckets[bucket]))
+	for key, value := range s.buckets[bucket] {
+		if before != "" && key >= before {
+			continue
+		}
+		keys = append(keys, key)
+		values[key] = append([]byte(nil), value...)
+	}
+	return keys, values
+}
+
+func (s *MemoryStore) iterate(keys []string, values map[string][]byte, fn func(key string, value []byte) error) error {
+	for _, key := range keys {
+		if err := fn(key, values[key]); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Close is a no-op for the in-memory store
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Buckets used by the application
+const (
+	BucketDriftBaselines    = "drift_baselines"
+	BucketDriftBaselineSets = "drift_baseline_sets"
+	BucketDriftHistory      = "drift_history"
+	BucketActiveAlerts      = "active_alerts"
+	BucketAlertHistory      = "alert_history"
+	BucketCapacityExports   = "capacity_exports"
+	BucketCache             = "cache"
+	BucketLimitChanges      = "limit_changes"
+	BucketAudit             = "audit"
+	BucketApprovalProposals = "approval_proposals"
+)
+
+// ErrNotFound is returned when a key does not exist
+var ErrNotFound = errors.New("key not found")
+
+// ErrStopIteration can be returned by a ForEach or ForEachReverse callback to end the iteration early without error
+var ErrStopIteration = errors.New("stop iteration")
+
+// Store is a bucketed key-value store for state that must survive restarts
+type Store interface {
+	// Put stores a value under key in bucket
+	Put(bucket, key string, value []byte) error
+	// Get returns the value stored under key, or ErrNotFound
+	Get(bucket, key string) ([]byte, error)
+	// Delete removes key from bucket; deleting a missing key is not an error
+	Delete(bucket, key string) error
+	// ForEach calls fn for every key in bucket in ascending key order; fn must not write to the store
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+	// ForEachReverse calls fn for every key in bucket below before (all keys when empty) in descending key order;
+	// fn must not write to the store
+	ForEachReverse(bucket, before string, fn func(key string, value []byte) error) error
+	// Close releases the underlying resources
+	Close() error
+}
+
+var (
+	globalStore Store
+	globalMutex sync.Mutex
+)
+
+// Init opens the store described by the global configuration
+func Init() error {
+	cfg := config.Get()
+	if cfg == nil {
+		return fmt.Errorf("configuration not initialized")
+	}
+
+	store, err := New(cfg.Storage)
+	if err != nil {
+		return err
+	}
+
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+	globalStore = store
+	return nil
+}
+
+// New creates a store for the given storage configuration
+func New(storageConfig config.StorageConfig) (Store, error) {
+	switch storageConfig.Type {
+	case "", "bolt":
+		store, err := NewBoltStore(storageConfig.Path)
+		if err != nil {
+			return nil, err
+		}
+		logrus.Infof("Using embedded storage at %s", storageConfig.Path)
+		return store, nil
+	case "memory":
+		logrus.Warn("Using in-memory storage, state will be lost on restart")
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage type: %s", storageConfig.Type)
+	}
+}
+
+// Get returns the global store, falling back to an in-memory store when Init was not called
+func Get() Store {
+	globalMutex.Lock()
+	defer globalMutex.Unlock()
+
+	if globalStore == nil {
+		globalStore = NewMemoryStore()
+	}
+	return globalStore
+}
+
+// PutJSON stores value encoded as JSON
+func PutJSON(store Store, bucket, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%s: %w", bucket, key, err)
+	}
+	return store.Put(bucket, key, data)
+}
+
+// GetJSON decodes the JSON value stored under key into target
+func GetJSON(store Store, bucket, key string, target interface{}) error {
+	data, err := store.Get(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := DecodeJSON(data, target); err != nil {
+		return fmt.Errorf("failed to decode %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// DecodeJSON decodes a stored JSON value into target
+func DecodeJSON(data []byte, target interface{}) error {
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akshaydubey29/mimirInsights/pkg/config"
+)
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	blocked := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocked, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		config    config.StorageConfig
+		wantError bool
+	}{
+		{name: "bolt", config: config.StorageConfig{Type: "bolt", Path: filepath.Join(dir, "state.db")}},
+		{name: "bolt is the default", config: config.StorageConfig{Path: filepath.Join(dir, "default.db")}},
+		{name: "memory", config: config.StorageConfig{Type: "memory"}},
+		{name: "bolt without a path", config: config.StorageConfig{Type: "bolt"}, wantError: true},
+		{name: "unusable path", config: config.StorageConfig{Type: "bolt", Path: filepath.Join(blocked, "state.db")}, wantError: true},
+		{name: "unknown type", config: config.StorageConfig{Type: "redis"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := New(tt.config)
+			if tt.wantError {
+				if err == nil {
+					store.Close()
+					t.Fatal("New() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer store.Close()
+
+			if _, err := store.Get(BucketAudit, "missing"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() of missing key error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestForEachReverse(t *testing.T) {
+	bolt, err := NewBoltStore(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bolt.Close()
+	stores := map[string]Store{"bolt": bolt, "memory": NewMemoryStore()}
+
+	tests := []struct {
+		name   string
+		before string
+		stopAt string
+		want   string
+	}{
+		{name: "all keys", want: "d,c,b,a"},
+		{name: "below an existing key", before: "c", want: "b,a"},
+		{name: "below a missing key", before: "bb", want: "b,a"},
+		{name: "below every key", before: "a", want: ""},
+		{name: "above every key", before: "z", want: "d,c,b,a"},
+		{name: "stopped early", stopAt: "b", want: "d,c,b"},
+	}
+
+	for storeName, store := range stores {
+		for _, key := range []string{"b", "d", "a", "c"} {
+			if err := store.Put(BucketAudit, key, []byte(key)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, tt := range tests {
+			t.Run(storeName+"/"+tt.name, func(t *testing.T) {
+				var keys []string
+				err := store.ForEachReverse(BucketAudit, tt.before, func(key string, value []byte) error {
+					keys = append(keys, key)
+					if key == tt.stopAt {
+						return ErrStopIteration
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("ForEachReverse() error = %v", err)
+				}
+				if got := strings.Join(keys, ","); got != tt.want {
+					t.Errorf("keys = %s, want %s", got, tt.want)
+				}
+			})
+		}
+
+		if err := store.ForEachReverse("missing", "", func(string, []byte) error {
+			t.Error("callback for a missing bucket")
+			return nil
+		}); err != nil {
+			t.Errorf("%s: ForEachReverse() of a missing bucket error = %v", storeName, err)
+		}
+	}
+}
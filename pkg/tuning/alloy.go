@@ -125,6 +125,18 @@ func (a *AlloyTuner) ScaleAlloyReplicas(ctx context.Context, req AlloyReplicaReq
 	return response, nil
 }
 
+// GetCurrentReplicas returns the desired replica count of an Alloy deployment
+func (a *AlloyTuner) GetCurrentReplicas(ctx context.Context, namespace, deploymentName string) (int32, error) {
+	deployment, err := a.k8sClient.GetDeployment(ctx, namespace, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	if deployment.Spec.Replicas == nil {
+		return 1, nil
+	}
+	return *deployment.Spec.Replicas, nil
+}
+
 // GetAlloyScalingRecommendations gets scaling recommendations for all Alloy deployments
 func (a *AlloyTuner) GetAlloyScalingRecommendations(ctx context.Context, namespaces []string) ([]AlloyScalingRecommendation, error) {
 	var recommendations []AlloyScalingRecommendation